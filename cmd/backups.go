@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/supabase/cli/internal/backups/list"
+	"github.com/supabase/cli/internal/backups/restore"
+	"github.com/supabase/cli/internal/utils/flags"
+)
+
+var (
+	backupsCmd = &cobra.Command{
+		GroupID: groupManagementAPI,
+		Use:     "backups",
+		Short:   "Manage Supabase physical backups",
+	}
+
+	backupsListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "Lists available physical backups",
+		Long:  "Lists available physical backups and the point-in-time recovery window of the linked project.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return list.Run(cmd.Context())
+		},
+	}
+
+	timestamp string
+	backupId  string
+
+	backupsRestoreCmd = &cobra.Command{
+		Use:   "restore",
+		Short: "Restore to a specific timestamp or backup using PITR",
+		Long:  "Restores the linked project to a point in time, or to the creation time of a backup, and waits for it to become healthy.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return restore.Run(cmd.Context(), timestamp, backupId)
+		},
+	}
+)
+
+func init() {
+	backupsCmd.PersistentFlags().StringVar(&flags.ProjectRef, "project-ref", "", "Project ref of the Supabase project.")
+	backupsCmd.AddCommand(backupsListCmd)
+	restoreFlags := backupsRestoreCmd.Flags()
+	restoreFlags.StringVarP(&timestamp, "timestamp", "t", "", "Point in time to restore to, in RFC3339 format.")
+	restoreFlags.StringVarP(&backupId, "backup-id", "i", "", "ID of the backup to restore to, as shown by backups list.")
+	backupsRestoreCmd.MarkFlagsMutuallyExclusive("timestamp", "backup-id")
+	backupsRestoreCmd.MarkFlagsOneRequired("timestamp", "backup-id")
+	backupsCmd.AddCommand(backupsRestoreCmd)
+	rootCmd.AddCommand(backupsCmd)
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 
+	"github.com/go-errors/errors"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -20,10 +22,13 @@ import (
 	"github.com/supabase/cli/internal/db/remote/changes"
 	"github.com/supabase/cli/internal/db/remote/commit"
 	"github.com/supabase/cli/internal/db/reset"
+	"github.com/supabase/cli/internal/db/restore"
+	"github.com/supabase/cli/internal/db/snapshot"
 	"github.com/supabase/cli/internal/db/start"
 	"github.com/supabase/cli/internal/db/test"
 	"github.com/supabase/cli/internal/utils"
 	"github.com/supabase/cli/internal/utils/flags"
+	"github.com/supabase/cli/pkg/archive"
 )
 
 var (
@@ -87,6 +92,10 @@ var (
 	useMigra    bool
 	usePgAdmin  bool
 	usePgSchema bool
+	diffData    bool
+	diffTables  []string
+	fromRef     string
+	toRef       string
 	schema      []string
 	file        string
 
@@ -94,6 +103,12 @@ var (
 		Use:   "diff",
 		Short: "Diffs the local database for schema changes",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("to-ref") && len(fromRef) == 0 {
+				return errors.New("--to-ref can only be used together with --from-ref")
+			}
+			if diffData {
+				return diff.RunData(cmd.Context(), diffTables, file, flags.DbConfig, afero.NewOsFs())
+			}
 			if usePgAdmin {
 				return diff.RunPgAdmin(cmd.Context(), schema, file, flags.DbConfig, afero.NewOsFs())
 			}
@@ -102,6 +117,9 @@ var (
 				differ = diff.DiffPgSchema
 				fmt.Fprintln(os.Stderr, utils.Yellow("WARNING:"), "--use-pg-schema flag is experimental and may not include all entities, such as RLS policies, enums, and grants.")
 			}
+			if len(fromRef) > 0 {
+				return diff.RunRefs(cmd.Context(), fromRef, toRef, schema, file, differ, afero.NewOsFs())
+			}
 			return diff.Run(cmd.Context(), schema, file, flags.DbConfig, differ, afero.NewOsFs())
 		},
 	}
@@ -111,6 +129,9 @@ var (
 	roleOnly     bool
 	keepComments bool
 	excludeTable []string
+	compress     = utils.EnumFlag{
+		Allowed: archive.AllowedCompressions,
+	}
 
 	dbDumpCmd = &cobra.Command{
 		Use:   "dump",
@@ -121,7 +142,7 @@ var (
 			}
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return dump.Run(cmd.Context(), file, flags.DbConfig, schema, excludeTable, dataOnly, roleOnly, keepComments, useCopy, dryRun, afero.NewOsFs())
+			return dump.Run(cmd.Context(), file, archive.Compression(compress.Value), flags.DbConfig, schema, excludeTable, dataOnly, roleOnly, keepComments, useCopy, dryRun, afero.NewOsFs())
 		},
 		PostRun: func(cmd *cobra.Command, args []string) {
 			if len(file) > 0 {
@@ -130,6 +151,19 @@ var (
 		},
 	}
 
+	restoreSchema   string
+	restoreData     string
+	restoreRoles    string
+	disableTriggers bool
+
+	dbRestoreCmd = &cobra.Command{
+		Use:   "restore",
+		Short: "Restores dumped roles, schema and data to a database",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return restore.Run(cmd.Context(), restoreSchema, restoreData, restoreRoles, disableTriggers, flags.DbConfig, afero.NewOsFs())
+		},
+	}
+
 	dryRun       bool
 	includeAll   bool
 	includeRoles bool
@@ -206,11 +240,19 @@ var (
 		Value:   "none",
 	}
 
+	lintRules     []string
+	writeBaseline bool
+
+	lintFormat = utils.EnumFlag{
+		Allowed: lint.AllowedFormats,
+		Value:   lint.FormatJson,
+	}
+
 	dbLintCmd = &cobra.Command{
 		Use:   "lint",
 		Short: "Checks local database for typing error",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return lint.Run(cmd.Context(), schema, level.Value, lintFailOn.Value, flags.DbConfig, afero.NewOsFs())
+			return lint.Run(cmd.Context(), schema, lintRules, level.Value, lintFailOn.Value, lintFormat.Value, writeBaseline, flags.DbConfig, afero.NewOsFs())
 		},
 	}
 
@@ -222,12 +264,65 @@ var (
 		},
 	}
 
+	dbSnapshotCmd = &cobra.Command{
+		Use:   "snapshot",
+		Short: "Manage snapshots of the local database",
+	}
+
+	dbSnapshotSaveCmd = &cobra.Command{
+		Use:   "save <name>",
+		Short: "Save the local database and storage to a named snapshot",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return snapshot.Save(cmd.Context(), args[0], afero.NewOsFs())
+		},
+	}
+
+	dbSnapshotRestoreCmd = &cobra.Command{
+		Use:   "restore <name>",
+		Short: "Restore the local database and storage from a named snapshot",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return snapshot.Restore(cmd.Context(), args[0], afero.NewOsFs())
+		},
+	}
+
+	dbSnapshotListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List snapshots of the local database",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return snapshot.List(cmd.Context(), afero.NewOsFs())
+		},
+	}
+
+	dbSnapshotRemoveCmd = &cobra.Command{
+		Use:   "rm <name>",
+		Short: "Remove a named snapshot",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return snapshot.Remove(cmd.Context(), args[0], afero.NewOsFs())
+		},
+	}
+
+	testJobs     uint
+	testIsolate  bool
+	testCoverage bool
+	testWatch    bool
+
+	testReporter = utils.EnumFlag{
+		Allowed: test.AllowedReporters,
+		Value:   test.ReporterTap,
+	}
+
 	dbTestCmd = &cobra.Command{
 		Hidden: true,
 		Use:    "test [path] ...",
 		Short:  "Tests local database with pgTAP",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return test.Run(cmd.Context(), args, flags.DbConfig, afero.NewOsFs())
+			if testWatch {
+				return test.Watch(cmd.Context(), args, testJobs, testIsolate, flags.DbConfig, afero.NewOsFs())
+			}
+			return test.Run(cmd.Context(), args, testJobs, testReporter.Value, testIsolate, testCoverage, flags.DbConfig, afero.NewOsFs())
 		},
 	}
 )
@@ -245,10 +340,21 @@ func init() {
 	diffFlags.BoolVar(&usePgAdmin, "use-pgadmin", false, "Use pgAdmin to generate schema diff.")
 	diffFlags.BoolVar(&usePgSchema, "use-pg-schema", false, "Use pg-schema-diff to generate schema diff.")
 	dbDiffCmd.MarkFlagsMutuallyExclusive("use-migra", "use-pgadmin")
+	diffFlags.BoolVar(&diffData, "data", false, "Diffs table rows between the local database and the remote database.")
+	diffFlags.StringSliceVar(&diffTables, "table", []string{}, "List of schema.tables to include in data diff.")
+	dbDiffCmd.MarkFlagsRequiredTogether("data", "table")
+	dbDiffCmd.MarkFlagsMutuallyExclusive("data", "use-pgadmin")
+	dbDiffCmd.MarkFlagsMutuallyExclusive("data", "use-pg-schema")
+	diffFlags.StringVar(&fromRef, "from-ref", "", "Diffs local migrations starting from the specified git revision.")
+	diffFlags.StringVar(&toRef, "to-ref", "HEAD", "Diffs local migrations up to the specified git revision.")
+	dbDiffCmd.MarkFlagsMutuallyExclusive("from-ref", "data")
+	dbDiffCmd.MarkFlagsMutuallyExclusive("from-ref", "use-pgadmin")
 	diffFlags.String("db-url", "", "Diffs against the database specified by the connection string (must be percent-encoded).")
 	diffFlags.Bool("linked", false, "Diffs local migration files against the linked project.")
 	diffFlags.Bool("local", true, "Diffs local migration files against the local database.")
 	dbDiffCmd.MarkFlagsMutuallyExclusive("db-url", "linked", "local")
+	dbDiffCmd.MarkFlagsMutuallyExclusive("from-ref", "db-url")
+	dbDiffCmd.MarkFlagsMutuallyExclusive("from-ref", "linked")
 	diffFlags.StringVarP(&file, "file", "f", "", "Saves schema diff to a new migration file.")
 	diffFlags.StringSliceVarP(&schema, "schema", "s", []string{}, "Comma separated list of schema to include.")
 	dbCmd.AddCommand(dbDiffCmd)
@@ -263,6 +369,7 @@ func init() {
 	dumpFlags.BoolVar(&keepComments, "keep-comments", false, "Keeps commented lines from pg_dump output.")
 	dbDumpCmd.MarkFlagsMutuallyExclusive("keep-comments", "data-only")
 	dumpFlags.StringVarP(&file, "file", "f", "", "File path to save the dumped contents.")
+	dumpFlags.Var(&compress, "compress", "Compression format of the dumped contents, inferred from file extension by default.")
 	dumpFlags.String("db-url", "", "Dumps from the database specified by the connection string (must be percent-encoded).")
 	dumpFlags.Bool("linked", true, "Dumps from the linked project.")
 	dumpFlags.Bool("local", false, "Dumps from the local database.")
@@ -272,6 +379,20 @@ func init() {
 	dumpFlags.StringSliceVarP(&schema, "schema", "s", []string{}, "Comma separated list of schema to include.")
 	dbDumpCmd.MarkFlagsMutuallyExclusive("schema", "role-only")
 	dbCmd.AddCommand(dbDumpCmd)
+	// Build restore command
+	restoreFlags := dbRestoreCmd.Flags()
+	restoreFlags.StringVar(&restoreSchema, "schema", "", "Path to the schema file produced by db dump.")
+	restoreFlags.StringVar(&restoreData, "data", "", "Path to the data file produced by db dump --data-only.")
+	restoreFlags.StringVar(&restoreRoles, "roles", "", "Path to the roles file produced by db dump --role-only.")
+	dbRestoreCmd.MarkFlagsOneRequired("schema", "data", "roles")
+	restoreFlags.BoolVar(&disableTriggers, "disable-triggers", false, "Disables triggers while loading data.")
+	restoreFlags.String("db-url", "", "Restores to the database specified by the connection string (must be percent-encoded).")
+	restoreFlags.Bool("linked", false, "Restores to the linked project.")
+	restoreFlags.Bool("local", true, "Restores to the local database.")
+	dbRestoreCmd.MarkFlagsMutuallyExclusive("db-url", "linked", "local")
+	restoreFlags.StringVarP(&dbPassword, "password", "p", "", "Password to your remote Postgres database.")
+	cobra.CheckErr(viper.BindPFlag("DB_PASSWORD", restoreFlags.Lookup("password")))
+	dbCmd.AddCommand(dbRestoreCmd)
 	// Build push command
 	pushFlags := dbPushCmd.Flags()
 	pushFlags.BoolVar(&includeAll, "include-all", false, "Include all migrations not found on remote history table.")
@@ -322,9 +443,18 @@ func init() {
 	lintFlags.StringSliceVarP(&schema, "schema", "s", []string{}, "Comma separated list of schema to include.")
 	lintFlags.Var(&level, "level", "Error level to emit.")
 	lintFlags.Var(&lintFailOn, "fail-on", "Error level to exit with non-zero status.")
+	lintFlags.Var(&lintFormat, "format", "Output format of lint results.")
+	lintFlags.StringSliceVar(&lintRules, "rules", lint.DefaultCategories, "Comma separated list of rule categories to run. ["+strings.Join(lint.AllowedCategories, ",")+"]")
+	lintFlags.BoolVar(&writeBaseline, "write-baseline", false, "Saves current issues to a baseline file so that only new issues are reported.")
 	dbCmd.AddCommand(dbLintCmd)
 	// Build start command
 	dbCmd.AddCommand(dbStartCmd)
+	// Build snapshot command
+	dbSnapshotCmd.AddCommand(dbSnapshotSaveCmd)
+	dbSnapshotCmd.AddCommand(dbSnapshotRestoreCmd)
+	dbSnapshotCmd.AddCommand(dbSnapshotListCmd)
+	dbSnapshotCmd.AddCommand(dbSnapshotRemoveCmd)
+	dbCmd.AddCommand(dbSnapshotCmd)
 	// Build test command
 	dbCmd.AddCommand(dbTestCmd)
 	testFlags := dbTestCmd.Flags()
@@ -332,5 +462,13 @@ func init() {
 	testFlags.Bool("linked", false, "Runs pgTAP tests on the linked project.")
 	testFlags.Bool("local", true, "Runs pgTAP tests on the local database.")
 	dbTestCmd.MarkFlagsMutuallyExclusive("db-url", "linked", "local")
+	testFlags.UintVarP(&testJobs, "jobs", "j", 1, "Number of test files to run in parallel.")
+	testFlags.Var(&testReporter, "reporter", "Output format of test results.")
+	testFlags.BoolVar(&testIsolate, "isolate", false, "Runs each test file in a database cloned from local migrations and seeds.")
+	testFlags.BoolVar(&testCoverage, "coverage", false, "Writes lcov and HTML coverage reports of plpgsql functions.")
+	testFlags.BoolVar(&testWatch, "watch", false, "Re-runs tests when test files or migrations change.")
+	dbTestCmd.MarkFlagsMutuallyExclusive("watch", "coverage")
+	dbTestCmd.MarkFlagsMutuallyExclusive("isolate", "db-url")
+	dbTestCmd.MarkFlagsMutuallyExclusive("isolate", "linked")
 	rootCmd.AddCommand(dbCmd)
 }
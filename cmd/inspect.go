@@ -2,12 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/supabase/cli/internal/inspect/bloat"
 	"github.com/supabase/cli/internal/inspect/blocking"
 	"github.com/supabase/cli/internal/inspect/cache"
@@ -16,10 +19,15 @@ import (
 
 	"github.com/supabase/cli/internal/inspect"
 	"github.com/supabase/cli/internal/inspect/calls"
+	"github.com/supabase/cli/internal/inspect/check"
+	"github.com/supabase/cli/internal/inspect/compare"
+	"github.com/supabase/cli/internal/inspect/explain"
+	"github.com/supabase/cli/internal/inspect/index_advisor"
 	"github.com/supabase/cli/internal/inspect/index_sizes"
 	"github.com/supabase/cli/internal/inspect/index_usage"
 	"github.com/supabase/cli/internal/inspect/locks"
 	"github.com/supabase/cli/internal/inspect/long_running_queries"
+	"github.com/supabase/cli/internal/inspect/metrics"
 	"github.com/supabase/cli/internal/inspect/outliers"
 	"github.com/supabase/cli/internal/inspect/replication_slots"
 	"github.com/supabase/cli/internal/inspect/role_configs"
@@ -28,6 +36,7 @@ import (
 	"github.com/supabase/cli/internal/inspect/table_index_sizes"
 	"github.com/supabase/cli/internal/inspect/table_record_counts"
 	"github.com/supabase/cli/internal/inspect/table_sizes"
+	"github.com/supabase/cli/internal/inspect/top"
 	"github.com/supabase/cli/internal/inspect/total_index_size"
 	"github.com/supabase/cli/internal/inspect/total_table_sizes"
 	"github.com/supabase/cli/internal/inspect/unused_indexes"
@@ -213,9 +222,62 @@ var (
 
 	outputDir string
 
+	explainOptions = explain.Options{}
+
+	inspectExplainCmd = &cobra.Command{
+		Use:   "explain",
+		Short: "Explain the plans of the slowest statements from pg_stat_statements",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return explain.Run(cmd.Context(), explainOptions, flags.DbConfig, afero.NewOsFs())
+		},
+	}
+
+	advisorOptions = index_advisor.Options{}
+
+	inspectIndexAdvisorCmd = &cobra.Command{
+		Use:   "index-advisor",
+		Short: "Recommend indexes for the slowest statements using hypothetical indexes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return index_advisor.Run(cmd.Context(), advisorOptions, flags.DbConfig, afero.NewOsFs())
+		},
+	}
+
+	topInterval time.Duration
+
+	inspectTopCmd = &cobra.Command{
+		Use:   "top",
+		Short: "Show a live dashboard of active sessions, waits and blocking backends",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return top.Run(cmd.Context(), topInterval, flags.DbConfig, afero.NewOsFs())
+		},
+	}
+
+	checkRules string
+
+	inspectCheckCmd = &cobra.Command{
+		Use:   "check",
+		Short: "Check inspect results against threshold rules",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return check.Run(cmd.Context(), checkRules, flags.DbConfig, afero.NewOsFs())
+		},
+	}
+
+	metricsListen   string
+	metricsCacheTTL time.Duration
+	metricsTimeout  time.Duration
+
+	inspectServeMetricsCmd = &cobra.Command{
+		Use:   "serve-metrics",
+		Short: "Serve inspect results as Prometheus metrics",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, _ := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			return metrics.Run(ctx, metricsListen, metricsCacheTTL, metricsTimeout, flags.DbConfig, afero.NewOsFs())
+		},
+	}
+
 	reportCmd = &cobra.Command{
 		Use:   "report",
-		Short: "Generate a CSV output for all inspect commands",
+		Short: "Generate CSV and JSON output for all inspect commands",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			if len(outputDir) == 0 {
@@ -230,6 +292,15 @@ var (
 			return inspect.Report(ctx, outputDir, flags.DbConfig, afero.NewOsFs())
 		},
 	}
+
+	compareCmd = &cobra.Command{
+		Use:   "compare <old-report-dir> <new-report-dir>",
+		Short: "Compare two reports generated by inspect report",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return compare.Run(cmd.Context(), args[0], args[1], afero.NewOsFs())
+		},
+	}
 )
 
 func init() {
@@ -258,8 +329,105 @@ func init() {
 	inspectDBCmd.AddCommand(inspectVacuumStatsCmd)
 	inspectDBCmd.AddCommand(inspectRoleConfigsCmd)
 	inspectDBCmd.AddCommand(inspectRoleConnectionsCmd)
+	explainFlags := inspectExplainCmd.Flags()
+	explainFlags.UintVar(&explainOptions.Top, "top", 5, "Number of statements to explain, ordered by total execution time.")
+	explainFlags.Int64Var(&explainOptions.QueryId, "query-id", 0, "Explain the statement with this pg_stat_statements query id.")
+	explainFlags.BoolVar(&explainOptions.Analyze, "analyze", false, "Execute statements without parameters in a rolled back transaction to collect actual row counts.")
+	inspectExplainCmd.MarkFlagsMutuallyExclusive("top", "query-id")
+	inspectDBCmd.AddCommand(inspectExplainCmd)
+	advisorFlags := inspectIndexAdvisorCmd.Flags()
+	advisorFlags.UintVar(&advisorOptions.Top, "top", 10, "Number of statements to analyze, ordered by total execution time.")
+	advisorFlags.Float64Var(&advisorOptions.MinImprovement, "min-improvement", 0.1, "Minimum ratio of plan cost saved for an index to be recommended.")
+	advisorFlags.StringVar(&advisorOptions.Migration, "save", "", "Save the chosen indexes to a new migration file with this name.")
+	inspectDBCmd.AddCommand(inspectIndexAdvisorCmd)
+	inspectTopCmd.Flags().DurationVar(&topInterval, "interval", 2*time.Second, "Interval between refreshes.")
+	inspectDBCmd.AddCommand(inspectTopCmd)
+	inspectCheckCmd.Flags().StringVar(&checkRules, "rules", "", "Path to a TOML file of threshold rules. Uses the default rules if not specified.")
+	inspectDBCmd.AddCommand(inspectCheckCmd)
+	metricsFlags := inspectServeMetricsCmd.Flags()
+	metricsFlags.StringVar(&metricsListen, "listen", ":9187", "Address to serve metrics on.")
+	metricsFlags.DurationVar(&metricsCacheTTL, "cache-ttl", 30*time.Second, "Duration to reuse query results across scrapes.")
+	metricsFlags.DurationVar(&metricsTimeout, "timeout", 10*time.Second, "Maximum duration of queries on each scrape.")
+	inspectDBCmd.AddCommand(inspectServeMetricsCmd)
 	inspectCmd.AddCommand(inspectDBCmd)
-	reportCmd.Flags().StringVar(&outputDir, "output-dir", "", "Path to save CSV files in")
+	reportCmd.Flags().StringVar(&outputDir, "output-dir", "", "Path to save report files in")
 	inspectCmd.AddCommand(reportCmd)
+	inspectCmd.AddCommand(compareCmd)
 	rootCmd.AddCommand(inspectCmd)
 }
+
+// Registers supabase/inspect/<name>.sql files as subcommands. Runs before flags are
+// parsed, so the project directory is resolved from the workdir flag directly.
+func addCustomInspectCmds(args []string, fsys afero.Fs) {
+	// Avoids reading project files for unrelated commands
+	if !targetsInspectDB(args) {
+		return
+	}
+	workdir := os.Getenv("SUPABASE_WORKDIR")
+	pflags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	pflags.ParseErrorsWhitelist.UnknownFlags = true
+	pflags.SetOutput(io.Discard)
+	pflags.StringVar(&workdir, "workdir", workdir, "")
+	_ = pflags.Parse(args)
+	if len(workdir) == 0 {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return
+		}
+		workdir = utils.GetProjectRoot(cwd, fsys)
+	}
+	queries, err := inspect.LoadCustomQueries(afero.NewBasePathFs(fsys, workdir))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Skipping custom inspect queries:", err)
+		return
+	}
+	for _, q := range queries {
+		if cmd, _, err := inspectDBCmd.Find([]string{q.Name}); err == nil && cmd != inspectDBCmd {
+			fmt.Fprintln(os.Stderr, "Skipping custom inspect query that conflicts with an existing command:", q.Name)
+			continue
+		}
+		inspectDBCmd.AddCommand(newCustomInspectCmd(q))
+	}
+}
+
+// Returns true if args run inspect db itself, such as custom queries that are not yet
+// registered or its help text. Built-in inspect db subcommands are resolved as usual.
+func targetsInspectDB(args []string) bool {
+	if len(args) > 0 && (args[0] == cobra.ShellCompRequestCmd || args[0] == cobra.ShellCompNoDescRequestCmd) {
+		args = args[1:]
+	}
+	cmd, _, err := rootCmd.Find(args)
+	return err == nil && cmd == inspectDBCmd
+}
+
+func newCustomInspectCmd(query inspect.CustomQuery) *cobra.Command {
+	short := query.Description
+	if len(short) == 0 {
+		short = fmt.Sprintf("Run the custom query in %s", filepath.Join(utils.InspectDir, query.Name+".sql"))
+	}
+	values := map[string]*string{}
+	cmd := &cobra.Command{
+		Use:   query.Name,
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params := map[string]string{}
+			for name, value := range values {
+				if cmd.Flags().Changed(name) {
+					params[name] = *value
+				}
+			}
+			return inspect.RunCustom(cmd.Context(), query, params, flags.DbConfig, afero.NewOsFs())
+		},
+	}
+	for _, p := range query.Parameters {
+		var value string
+		if p.Default != nil {
+			value = *p.Default
+		}
+		values[p.Name] = cmd.Flags().String(p.Name, value, p.Description)
+		if p.Default == nil {
+			cobra.CheckErr(cmd.MarkFlagRequired(p.Name))
+		}
+	}
+	return cmd
+}
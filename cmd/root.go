@@ -133,6 +133,7 @@ var (
 
 func Execute() {
 	defer recoverAndExit()
+	addCustomInspectCmds(os.Args[1:], afero.NewOsFs())
 	if err := rootCmd.Execute(); err != nil {
 		panic(err)
 	}
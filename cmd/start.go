@@ -39,6 +39,9 @@ var (
 	excludedContainers []string
 	ignoreHealthCheck  bool
 	preview            bool
+	exportFormat       = utils.EnumFlag{
+		Allowed: []string{start.ExportCompose},
+	}
 
 	startCmd = &cobra.Command{
 		GroupID: groupLocalDev,
@@ -46,6 +49,9 @@ var (
 		Short:   "Start containers for Supabase local development",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			validateExcludedContainers(excludedContainers)
+			if len(exportFormat.Value) > 0 {
+				return start.Export(cmd.Context(), exportFormat.Value, os.Stdout, afero.NewOsFs(), excludedContainers)
+			}
 			return start.Run(cmd.Context(), afero.NewOsFs(), excludedContainers, ignoreHealthCheck)
 		},
 	}
@@ -56,6 +62,7 @@ func init() {
 	names := strings.Join(allowedContainers, ",")
 	flags.StringSliceVarP(&excludedContainers, "exclude", "x", []string{}, "Names of containers to not start. ["+names+"]")
 	flags.BoolVar(&ignoreHealthCheck, "ignore-health-check", false, "Ignore unhealthy services and exit 0")
+	flags.Var(&exportFormat, "export", "Print the containers as a file of the given format instead of starting them.")
 	flags.BoolVar(&preview, "preview", false, "Connect to feature preview branch")
 	cobra.CheckErr(flags.MarkHidden("preview"))
 	rootCmd.AddCommand(startCmd)
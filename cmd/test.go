@@ -6,6 +6,7 @@ import (
 
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	functest "github.com/supabase/cli/internal/functions/test"
 	"github.com/supabase/cli/internal/test/new"
 	"github.com/supabase/cli/internal/utils"
 )
@@ -23,8 +24,23 @@ var (
 		RunE:  dbTestCmd.RunE,
 	}
 
+	functionsReporter = utils.EnumFlag{
+		Allowed: functest.AllowedReporters,
+		Value:   functest.ReporterPretty,
+	}
+	functionsCoverage bool
+
+	testFunctionsCmd = &cobra.Command{
+		Use:   "functions [slug] ...",
+		Short: "Run Deno tests of Edge Functions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, _ := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			return functest.Run(ctx, args, envFilePath, functionsReporter.Value, functionsCoverage, afero.NewOsFs())
+		},
+	}
+
 	template = utils.EnumFlag{
-		Allowed: []string{new.TemplatePgTAP},
+		Allowed: []string{new.TemplatePgTAP, new.TemplatePolicy},
 		Value:   new.TemplatePgTAP,
 	}
 
@@ -46,7 +62,21 @@ func init() {
 	dbFlags.Bool("linked", false, "Runs pgTAP tests on the linked project.")
 	dbFlags.Bool("local", true, "Runs pgTAP tests on the local database.")
 	testDbCmd.MarkFlagsMutuallyExclusive("db-url", "linked", "local")
+	dbFlags.UintVarP(&testJobs, "jobs", "j", 1, "Number of test files to run in parallel.")
+	dbFlags.Var(&testReporter, "reporter", "Output format of test results.")
+	dbFlags.BoolVar(&testIsolate, "isolate", false, "Runs each test file in a database cloned from local migrations and seeds.")
+	dbFlags.BoolVar(&testCoverage, "coverage", false, "Writes lcov and HTML coverage reports of plpgsql functions.")
+	dbFlags.BoolVar(&testWatch, "watch", false, "Re-runs tests when test files or migrations change.")
+	testDbCmd.MarkFlagsMutuallyExclusive("watch", "coverage")
+	testDbCmd.MarkFlagsMutuallyExclusive("isolate", "db-url")
+	testDbCmd.MarkFlagsMutuallyExclusive("isolate", "linked")
 	testCmd.AddCommand(testDbCmd)
+	// Build functions command
+	funcFlags := testFunctionsCmd.Flags()
+	funcFlags.StringVar(&envFilePath, "env-file", "", "Path to an env file to be populated to the Function environment.")
+	funcFlags.Var(&functionsReporter, "reporter", "Output format of test results.")
+	funcFlags.BoolVar(&functionsCoverage, "coverage", false, "Writes lcov coverage report of Function code.")
+	testCmd.AddCommand(testFunctionsCmd)
 	// Build new command
 	newFlags := testNewCmd.Flags()
 	newFlags.VarP(&template, "template", "t", "Template framework to generate.")
@@ -0,0 +1,112 @@
+package list
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/supabase/cli/internal/migration/list"
+	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/internal/utils/flags"
+	"github.com/supabase/cli/pkg/api"
+)
+
+type Backup struct {
+	// Identifies the backup when restoring with --backup-id
+	Id         string `json:"id" yaml:"id" toml:"id"`
+	Type       string `json:"type" yaml:"type" toml:"type"`
+	Status     string `json:"status" yaml:"status" toml:"status"`
+	InsertedAt string `json:"inserted_at" yaml:"inserted_at" toml:"inserted_at"`
+}
+
+type Result struct {
+	PitrEnabled bool `json:"pitr_enabled" yaml:"pitr_enabled" toml:"pitr_enabled"`
+	// Unix seconds of the point-in-time recovery window, if enabled
+	EarliestRecoveryTime *int64   `json:"earliest_recovery_time,omitempty" yaml:"earliest_recovery_time,omitempty" toml:"earliest_recovery_time,omitempty"`
+	LatestRecoveryTime   *int64   `json:"latest_recovery_time,omitempty" yaml:"latest_recovery_time,omitempty" toml:"latest_recovery_time,omitempty"`
+	Backups              []Backup `json:"backups" yaml:"backups" toml:"backups"`
+}
+
+func Run(ctx context.Context) error {
+	resp, err := utils.GetSupabase().V1ListAllBackupsWithResponse(ctx, flags.ProjectRef)
+	if err != nil {
+		return errors.Errorf("failed to list backups: %w", err)
+	}
+
+	if resp.JSON200 == nil {
+		return errors.New("Unexpected error listing backups: " + string(resp.Body))
+	}
+
+	result := toResult(*resp.JSON200)
+	if utils.OutputFormat.Value == utils.OutputCsv {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result.Backups)
+	} else if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result)
+	}
+
+	if resp.JSON200.PitrEnabled {
+		physical := resp.JSON200.PhysicalBackupData
+		if physical.EarliestPhysicalBackupDateUnix != nil && physical.LatestPhysicalBackupDateUnix != nil {
+			fmt.Fprintf(os.Stderr, "Point-in-time recovery is available from %s to %s (UTC).\n",
+				formatUnix(*physical.EarliestPhysicalBackupDateUnix),
+				formatUnix(*physical.LatestPhysicalBackupDateUnix),
+			)
+		}
+	}
+
+	table := `|ID|TYPE|STATUS|CREATED AT (UTC)|
+|-|-|-|-|
+`
+	for _, backup := range result.Backups {
+		table += fmt.Sprintf(
+			"|`%s`|`%s`|`%s`|`%s`|\n",
+			backup.Id,
+			backup.Type,
+			backup.Status,
+			utils.FormatTimestamp(backup.InsertedAt),
+		)
+	}
+
+	return list.RenderTable(table)
+}
+
+func toResult(resp api.V1BackupsResponse) Result {
+	result := Result{
+		PitrEnabled: resp.PitrEnabled,
+		Backups:     make([]Backup, len(resp.Backups)),
+	}
+	if resp.PitrEnabled {
+		result.EarliestRecoveryTime = resp.PhysicalBackupData.EarliestPhysicalBackupDateUnix
+		result.LatestRecoveryTime = resp.PhysicalBackupData.LatestPhysicalBackupDateUnix
+	}
+	for i, backup := range resp.Backups {
+		kind := "logical"
+		if backup.IsPhysicalBackup {
+			kind = "physical"
+		}
+		result.Backups[i] = Backup{
+			Id:         GetBackupId(backup),
+			Type:       kind,
+			Status:     string(backup.Status),
+			InsertedAt: backup.InsertedAt,
+		}
+	}
+	return result
+}
+
+// The API does not assign backup IDs, so backups are identified by their creation time
+// in unix seconds, which is also the recovery target when restoring.
+func GetBackupId(backup api.V1Backup) string {
+	created, err := time.Parse(time.RFC3339, backup.InsertedAt)
+	if err != nil {
+		return backup.InsertedAt
+	}
+	return strconv.FormatInt(created.Unix(), 10)
+}
+
+func formatUnix(ts int64) string {
+	return time.Unix(ts, 0).UTC().Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,79 @@
+package list
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/supabase/cli/internal/testing/apitest"
+	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/internal/utils/flags"
+	"github.com/supabase/cli/pkg/api"
+	"github.com/supabase/cli/pkg/cast"
+)
+
+func TestListBackups(t *testing.T) {
+	// Setup valid project ref
+	flags.ProjectRef = apitest.RandomProjectRef()
+	// Setup valid access token
+	token := apitest.RandomAccessToken(t)
+	t.Setenv("SUPABASE_ACCESS_TOKEN", string(token))
+
+	t.Run("lists backups as json", func(t *testing.T) {
+		utils.OutputFormat.Value = utils.OutputJson
+		t.Cleanup(func() { utils.OutputFormat.Value = utils.OutputPretty })
+		// Setup mock api
+		defer gock.OffAll()
+		gock.New(utils.DefaultApiHost).
+			Get("/v1/projects/" + flags.ProjectRef + "/database/backups").
+			Reply(http.StatusOK).
+			JSON(api.V1BackupsResponse{
+				Backups: []api.V1Backup{{
+					InsertedAt: "2024-10-01T00:00:00Z",
+					Status:     api.V1BackupStatusCOMPLETED,
+				}},
+				PitrEnabled: true,
+				PhysicalBackupData: api.V1PhysicalBackup{
+					EarliestPhysicalBackupDateUnix: cast.Ptr(int64(1727740800)),
+					LatestPhysicalBackupDateUnix:   cast.Ptr(int64(1728000000)),
+				},
+			})
+		// Run test
+		err := Run(context.Background())
+		// Check error
+		assert.NoError(t, err)
+		assert.Empty(t, apitest.ListUnmatchedRequests())
+	})
+
+	t.Run("throws error on service unavailable", func(t *testing.T) {
+		// Setup mock api
+		defer gock.OffAll()
+		gock.New(utils.DefaultApiHost).
+			Get("/v1/projects/" + flags.ProjectRef + "/database/backups").
+			Reply(http.StatusServiceUnavailable)
+		// Run test
+		err := Run(context.Background())
+		// Check error
+		assert.ErrorContains(t, err, "Unexpected error listing backups:")
+	})
+}
+
+func TestToResult(t *testing.T) {
+	result := toResult(api.V1BackupsResponse{
+		Backups: []api.V1Backup{{
+			InsertedAt:       "2024-10-01T00:00:00Z",
+			IsPhysicalBackup: true,
+			Status:           api.V1BackupStatusCOMPLETED,
+		}},
+	})
+	assert.False(t, result.PitrEnabled)
+	assert.Nil(t, result.EarliestRecoveryTime)
+	assert.Equal(t, []Backup{{
+		Id:         "1727740800",
+		Type:       "physical",
+		Status:     "COMPLETED",
+		InsertedAt: "2024-10-01T00:00:00Z",
+	}}, result.Backups)
+}
@@ -0,0 +1,115 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/supabase/cli/internal/backups/list"
+	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/internal/utils/flags"
+	"github.com/supabase/cli/pkg/api"
+)
+
+var (
+	// Interval between polling project status
+	pollInterval = 5 * time.Second
+	// Number of polls to wait for the project to enter restoring state
+	maxPendingPolls = 3
+)
+
+func Run(ctx context.Context, timestamp, backupId string) error {
+	target, err := getRecoveryTarget(ctx, timestamp, backupId)
+	if err != nil {
+		return err
+	}
+	title := fmt.Sprintf("Do you want to restore project %s to %s?", utils.Aqua(flags.ProjectRef), utils.Bold(target.UTC().Format(time.RFC3339)))
+	if shouldRestore, err := utils.NewConsole().PromptYesNo(ctx, title, false); err != nil {
+		return err
+	} else if !shouldRestore {
+		return errors.New(context.Canceled)
+	}
+	start := time.Now()
+	body := api.V1RestorePitrBody{RecoveryTimeTargetUnix: target.Unix()}
+	resp, err := utils.GetSupabase().V1RestorePitrBackupWithResponse(ctx, flags.ProjectRef, body)
+	if err != nil {
+		return errors.Errorf("failed to restore backup: %w", err)
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return errors.New("Unexpected error restoring backup: " + string(resp.Body))
+	}
+	fmt.Fprintln(os.Stderr, "Waiting for project to become healthy...")
+	if err := waitForHealthyProject(ctx, flags.ProjectRef); err != nil {
+		return err
+	}
+	elapsed := time.Since(start).Round(time.Second)
+	fmt.Println("Finished restoring project " + utils.Aqua(flags.ProjectRef) + " in " + elapsed.String() + ".")
+	return nil
+}
+
+func getRecoveryTarget(ctx context.Context, timestamp, backupId string) (time.Time, error) {
+	if len(backupId) == 0 {
+		target, err := time.Parse(time.RFC3339, timestamp)
+		if err != nil {
+			return time.Time{}, errors.Errorf("failed to parse timestamp: %w", err)
+		}
+		return target, nil
+	}
+	resp, err := utils.GetSupabase().V1ListAllBackupsWithResponse(ctx, flags.ProjectRef)
+	if err != nil {
+		return time.Time{}, errors.Errorf("failed to list backups: %w", err)
+	}
+	if resp.JSON200 == nil {
+		return time.Time{}, errors.New("Unexpected error listing backups: " + string(resp.Body))
+	}
+	for _, backup := range resp.JSON200.Backups {
+		if list.GetBackupId(backup) != backupId {
+			continue
+		}
+		target, err := time.Parse(time.RFC3339, backup.InsertedAt)
+		if err != nil {
+			return time.Time{}, errors.Errorf("failed to parse backup time: %w", err)
+		}
+		return target, nil
+	}
+	utils.CmdSuggestion = fmt.Sprintf("Run %s to show available backups.", utils.Aqua("supabase backups list"))
+	return time.Time{}, errors.Errorf("backup not found: %s", backupId)
+}
+
+func waitForHealthyProject(ctx context.Context, projectRef string) error {
+	var last api.V1ProjectResponseStatus
+	restoring := false
+	for i := 0; ; i++ {
+		resp, err := utils.GetSupabase().V1GetProjectWithResponse(ctx, projectRef)
+		if err != nil {
+			return errors.Errorf("failed to get project: %w", err)
+		}
+		if resp.JSON200 == nil {
+			return errors.New("Unexpected error retrieving project: " + string(resp.Body))
+		}
+		status := resp.JSON200.Status
+		if status != last {
+			fmt.Fprintln(os.Stderr, "Project status:", status)
+			last = status
+		}
+		switch status {
+		case api.V1ProjectResponseStatusRESTOREFAILED:
+			return errors.New("Failed to restore project: " + projectRef)
+		case api.V1ProjectResponseStatusACTIVEHEALTHY:
+			// The project may still report healthy right after restore is requested
+			if restoring || i >= maxPendingPolls {
+				return nil
+			}
+		default:
+			restoring = true
+		}
+		select {
+		case <-ctx.Done():
+			return errors.New(ctx.Err())
+		case <-time.After(pollInterval):
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package restore
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/supabase/cli/internal/testing/apitest"
+	"github.com/supabase/cli/internal/testing/fstest"
+	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/internal/utils/flags"
+	"github.com/supabase/cli/pkg/api"
+)
+
+func TestRestoreBackup(t *testing.T) {
+	// Setup valid project ref
+	flags.ProjectRef = apitest.RandomProjectRef()
+	// Setup valid access token
+	token := apitest.RandomAccessToken(t)
+	t.Setenv("SUPABASE_ACCESS_TOKEN", string(token))
+	pollInterval = 0
+
+	t.Run("restores to point in time", func(t *testing.T) {
+		t.Cleanup(fstest.MockStdin(t, "y"))
+		// Setup mock api
+		defer gock.OffAll()
+		gock.New(utils.DefaultApiHost).
+			Post("/v1/projects/" + flags.ProjectRef + "/database/backups/restore-pitr").
+			JSON(api.V1RestorePitrBody{RecoveryTimeTargetUnix: 1727740800}).
+			Reply(http.StatusCreated)
+		gock.New(utils.DefaultApiHost).
+			Get("/v1/projects/" + flags.ProjectRef).
+			Reply(http.StatusOK).
+			JSON(api.V1ProjectResponse{Status: api.V1ProjectResponseStatusRESTORING})
+		gock.New(utils.DefaultApiHost).
+			Get("/v1/projects/" + flags.ProjectRef).
+			Reply(http.StatusOK).
+			JSON(api.V1ProjectResponse{Status: api.V1ProjectResponseStatusACTIVEHEALTHY})
+		// Run test
+		err := Run(context.Background(), "2024-10-01T00:00:00Z", "")
+		// Check error
+		assert.NoError(t, err)
+		assert.Empty(t, apitest.ListUnmatchedRequests())
+	})
+
+	t.Run("restores to backup id", func(t *testing.T) {
+		t.Cleanup(fstest.MockStdin(t, "y"))
+		// Setup mock api
+		defer gock.OffAll()
+		gock.New(utils.DefaultApiHost).
+			Get("/v1/projects/" + flags.ProjectRef + "/database/backups").
+			Reply(http.StatusOK).
+			JSON(api.V1BackupsResponse{Backups: []api.V1Backup{{
+				InsertedAt: "2024-09-30T00:00:00Z",
+				Status:     api.V1BackupStatusCOMPLETED,
+			}, {
+				InsertedAt: "2024-10-01T00:00:00Z",
+				Status:     api.V1BackupStatusCOMPLETED,
+			}}})
+		gock.New(utils.DefaultApiHost).
+			Post("/v1/projects/" + flags.ProjectRef + "/database/backups/restore-pitr").
+			JSON(api.V1RestorePitrBody{RecoveryTimeTargetUnix: 1727740800}).
+			Reply(http.StatusCreated)
+		gock.New(utils.DefaultApiHost).
+			Get("/v1/projects/" + flags.ProjectRef).
+			Reply(http.StatusOK).
+			JSON(api.V1ProjectResponse{Status: api.V1ProjectResponseStatusRESTORING})
+		gock.New(utils.DefaultApiHost).
+			Get("/v1/projects/" + flags.ProjectRef).
+			Reply(http.StatusOK).
+			JSON(api.V1ProjectResponse{Status: api.V1ProjectResponseStatusACTIVEHEALTHY})
+		// Run test
+		err := Run(context.Background(), "", "1727740800")
+		// Check error
+		assert.NoError(t, err)
+		assert.Empty(t, apitest.ListUnmatchedRequests())
+	})
+
+	t.Run("throws error on unknown backup id", func(t *testing.T) {
+		// Setup mock api
+		defer gock.OffAll()
+		gock.New(utils.DefaultApiHost).
+			Get("/v1/projects/" + flags.ProjectRef + "/database/backups").
+			Reply(http.StatusOK).
+			JSON(api.V1BackupsResponse{})
+		// Run test
+		err := Run(context.Background(), "", "1727740800")
+		// Check error
+		assert.ErrorContains(t, err, "backup not found: 1727740800")
+		assert.Empty(t, apitest.ListUnmatchedRequests())
+	})
+
+	t.Run("throws error on invalid timestamp", func(t *testing.T) {
+		err := Run(context.Background(), "yesterday", "")
+		assert.ErrorContains(t, err, "failed to parse timestamp")
+	})
+
+	t.Run("throws error on cancel", func(t *testing.T) {
+		t.Cleanup(fstest.MockStdin(t, "n"))
+		// Run test
+		err := Run(context.Background(), "2024-10-01T00:00:00Z", "")
+		// Check error
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("throws error on failed restore", func(t *testing.T) {
+		t.Cleanup(fstest.MockStdin(t, "y"))
+		// Setup mock api
+		defer gock.OffAll()
+		gock.New(utils.DefaultApiHost).
+			Post("/v1/projects/" + flags.ProjectRef + "/database/backups/restore-pitr").
+			Reply(http.StatusCreated)
+		gock.New(utils.DefaultApiHost).
+			Get("/v1/projects/" + flags.ProjectRef).
+			Reply(http.StatusOK).
+			JSON(api.V1ProjectResponse{Status: api.V1ProjectResponseStatusRESTOREFAILED})
+		// Run test
+		err := Run(context.Background(), "2024-10-01T00:00:00Z", "")
+		// Check error
+		assert.ErrorContains(t, err, "Failed to restore project:")
+	})
+
+	t.Run("throws error on service unavailable", func(t *testing.T) {
+		t.Cleanup(fstest.MockStdin(t, "y"))
+		// Setup mock api
+		defer gock.OffAll()
+		gock.New(utils.DefaultApiHost).
+			Post("/v1/projects/" + flags.ProjectRef + "/database/backups/restore-pitr").
+			Reply(http.StatusServiceUnavailable)
+		// Run test
+		err := Run(context.Background(), "2024-10-01T00:00:00Z", "")
+		// Check error
+		assert.ErrorContains(t, err, "Unexpected error restoring backup:")
+	})
+}
@@ -0,0 +1,278 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/utils"
+)
+
+const LIST_TABLE_COLUMNS = `SELECT a.attname, COALESCE(a.attnum = ANY(i.indkey), false)
+FROM pg_attribute a
+LEFT JOIN pg_index i ON i.indrelid = a.attrelid AND i.indisprimary
+WHERE a.attrelid = $1::text::regclass AND a.attnum > 0 AND NOT a.attisdropped AND a.attgenerated = ''
+ORDER BY a.attnum`
+
+type Column struct {
+	Name    string
+	Primary bool
+}
+
+type TableDiff struct {
+	Table    pgx.Identifier
+	Columns  []Column
+	Inserted []string
+	Updated  []string
+	Deleted  []string
+}
+
+func RunData(ctx context.Context, tables []string, file string, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
+	// Sanity checks.
+	if err := utils.LoadConfigFS(fsys); err != nil {
+		return err
+	}
+	if utils.IsLocalDatabase(config) {
+		return errors.New("Data diff requires a remote database: specify --linked or --db-url.")
+	}
+	local, err := utils.ConnectLocalPostgres(ctx, pgconn.Config{}, options...)
+	if err != nil {
+		return err
+	}
+	defer local.Close(context.Background())
+	remote, err := utils.ConnectByConfig(ctx, config, options...)
+	if err != nil {
+		return err
+	}
+	defer remote.Close(context.Background())
+	var result []TableDiff
+	for _, name := range tables {
+		fmt.Fprintln(os.Stderr, "Diffing table:", name)
+		d, err := DiffTableData(ctx, name, local, remote)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "%d inserted, %d updated, %d deleted\n", len(d.Inserted), len(d.Updated), len(d.Deleted))
+		result = append(result, d)
+	}
+	fmt.Fprintln(os.Stderr, "Finished "+utils.Aqua("supabase db diff --data")+".\n")
+	out := ToSyncStatements(result)
+	if len(out) == 0 {
+		fmt.Fprintln(os.Stderr, "No data changes found")
+		return nil
+	}
+	return SaveDiff(out, file, fsys)
+}
+
+// Compares rows of a single table by primary key and content hash. Inserted and updated
+// rows contain the local row as json, while deleted rows contain only the primary key.
+func DiffTableData(ctx context.Context, name string, local, remote *pgx.Conn) (TableDiff, error) {
+	result := TableDiff{Table: parseTableName(name)}
+	rows, err := local.Query(ctx, LIST_TABLE_COLUMNS, result.Table.Sanitize())
+	if err != nil {
+		return result, errors.Errorf("failed to list columns: %w", err)
+	}
+	if result.Columns, err = collectColumns(rows); err != nil {
+		return result, err
+	}
+	var pkey []string
+	for _, c := range result.Columns {
+		if c.Primary {
+			pkey = append(pkey, c.Name)
+		}
+	}
+	if len(pkey) == 0 {
+		return result, errors.Errorf("table %s has no primary key", name)
+	}
+	hashSql := selectRowHashes(result.Table, pkey)
+	want, err := queryRowHashes(ctx, local, hashSql)
+	if err != nil {
+		return result, err
+	}
+	got, err := queryRowHashes(ctx, remote, hashSql)
+	if err != nil {
+		return result, err
+	}
+	// Classify rows by primary key
+	var changed []string
+	for key, hash := range want {
+		if remoteHash, ok := got[key]; !ok || remoteHash != hash {
+			changed = append(changed, key)
+		}
+	}
+	for key := range got {
+		if _, ok := want[key]; !ok {
+			result.Deleted = append(result.Deleted, key)
+		}
+	}
+	slices.Sort(result.Deleted)
+	if len(changed) == 0 {
+		return result, nil
+	}
+	slices.Sort(changed)
+	rows, err = local.Query(ctx, selectRowData(result.Table, pkey), changed)
+	if err != nil {
+		return result, errors.Errorf("failed to select rows: %w", err)
+	}
+	data, err := collectRowData(rows)
+	if err != nil {
+		return result, err
+	}
+	for _, key := range changed {
+		if _, ok := got[key]; ok {
+			result.Updated = append(result.Updated, data[key])
+		} else {
+			result.Inserted = append(result.Inserted, data[key])
+		}
+	}
+	return result, nil
+}
+
+func parseTableName(name string) pgx.Identifier {
+	if schema, table, found := strings.Cut(name, "."); found {
+		return pgx.Identifier{schema, table}
+	}
+	return pgx.Identifier{"public", name}
+}
+
+func collectColumns(rows pgx.Rows) ([]Column, error) {
+	defer rows.Close()
+	var result []Column
+	for rows.Next() {
+		var c Column
+		if err := rows.Scan(&c.Name, &c.Primary); err != nil {
+			return nil, errors.Errorf("failed to scan rows: %w", err)
+		}
+		result = append(result, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Errorf("failed to parse rows: %w", err)
+	}
+	return result, nil
+}
+
+func buildKeyObject(pkey []string) string {
+	var args []string
+	for _, c := range pkey {
+		args = append(args, quoteLiteral(c), "t."+pgx.Identifier{c}.Sanitize())
+	}
+	return fmt.Sprintf("jsonb_build_object(%s)::text", strings.Join(args, ", "))
+}
+
+func selectRowHashes(table pgx.Identifier, pkey []string) string {
+	return fmt.Sprintf("SELECT %s, md5(to_jsonb(t)::text) FROM %s t", buildKeyObject(pkey), table.Sanitize())
+}
+
+func selectRowData(table pgx.Identifier, pkey []string) string {
+	key := buildKeyObject(pkey)
+	return fmt.Sprintf("SELECT %s, to_jsonb(t)::text FROM %s t WHERE %s = ANY($1)", key, table.Sanitize(), key)
+}
+
+func queryRowHashes(ctx context.Context, conn *pgx.Conn, sql string) (map[string]string, error) {
+	rows, err := conn.Query(ctx, sql)
+	if err != nil {
+		return nil, errors.Errorf("failed to select rows: %w", err)
+	}
+	return collectRowData(rows)
+}
+
+func collectRowData(rows pgx.Rows) (map[string]string, error) {
+	defer rows.Close()
+	result := map[string]string{}
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, errors.Errorf("failed to scan rows: %w", err)
+		}
+		result[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Errorf("failed to parse rows: %w", err)
+	}
+	return result, nil
+}
+
+// Generates statements that sync the target tables with local data. Deletes are
+// emitted in reverse table order so that referencing rows are removed first.
+func ToSyncStatements(result []TableDiff) string {
+	var lines []string
+	for i := len(result) - 1; i >= 0; i-- {
+		d := result[i]
+		for _, key := range d.Deleted {
+			lines = append(lines, d.deleteRow(key))
+		}
+	}
+	for _, d := range result {
+		for _, row := range d.Updated {
+			if stat := d.updateRow(row); len(stat) > 0 {
+				lines = append(lines, stat)
+			}
+		}
+		for _, row := range d.Inserted {
+			lines = append(lines, d.insertRow(row))
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (d TableDiff) populateRecord(data string) string {
+	return fmt.Sprintf("jsonb_populate_record(NULL::%s, %s)", d.Table.Sanitize(), quoteLiteral(data))
+}
+
+func (d TableDiff) matchKey() string {
+	var cond []string
+	for _, c := range d.Columns {
+		if c.Primary {
+			col := pgx.Identifier{c.Name}.Sanitize()
+			cond = append(cond, fmt.Sprintf("t.%s = r.%s", col, col))
+		}
+	}
+	return strings.Join(cond, " AND ")
+}
+
+func (d TableDiff) deleteRow(key string) string {
+	return fmt.Sprintf("DELETE FROM %s t USING %s r WHERE %s;", d.Table.Sanitize(), d.populateRecord(key), d.matchKey())
+}
+
+func (d TableDiff) updateRow(data string) string {
+	var cols, values []string
+	for _, c := range d.Columns {
+		if !c.Primary {
+			col := pgx.Identifier{c.Name}.Sanitize()
+			cols = append(cols, col)
+			values = append(values, "r."+col)
+		}
+	}
+	if len(cols) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("UPDATE %s t SET (%s) = ROW(%s) FROM %s r WHERE %s;",
+		d.Table.Sanitize(),
+		strings.Join(cols, ", "),
+		strings.Join(values, ", "),
+		d.populateRecord(data),
+		d.matchKey(),
+	)
+}
+
+func (d TableDiff) insertRow(data string) string {
+	var cols []string
+	for _, c := range d.Columns {
+		cols = append(cols, pgx.Identifier{c.Name}.Sanitize())
+	}
+	list := strings.Join(cols, ", ")
+	return fmt.Sprintf("INSERT INTO %s (%s) OVERRIDING SYSTEM VALUE SELECT %s FROM %s;", d.Table.Sanitize(), list, list, d.populateRecord(data))
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
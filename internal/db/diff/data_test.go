@@ -0,0 +1,100 @@
+package diff
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/supabase/cli/pkg/pgtest"
+)
+
+func TestDiffTableData(t *testing.T) {
+	table := pgx.Identifier{"public", "plans"}
+	pkey := []string{"id"}
+
+	t.Run("classifies changed rows", func(t *testing.T) {
+		// Setup mock postgres
+		local := pgtest.NewConn()
+		defer local.Close(t)
+		local.Query(LIST_TABLE_COLUMNS, table.Sanitize()).
+			Reply("SELECT 2", []interface{}{"id", true}, []interface{}{"name", false}).
+			Query(selectRowHashes(table, pkey)).
+			Reply("SELECT 3",
+				[]interface{}{`{"id": 1}`, "a"},
+				[]interface{}{`{"id": 2}`, "b"},
+				[]interface{}{`{"id": 3}`, "c"},
+			).
+			Query(selectRowData(table, pkey), []string{`{"id": 2}`, `{"id": 3}`}).
+			Reply("SELECT 2",
+				[]interface{}{`{"id": 2}`, `{"id": 2, "name": "pro"}`},
+				[]interface{}{`{"id": 3}`, `{"id": 3, "name": "team"}`},
+			)
+		remote := pgtest.NewConn()
+		defer remote.Close(t)
+		remote.Query(selectRowHashes(table, pkey)).
+			Reply("SELECT 3",
+				[]interface{}{`{"id": 1}`, "a"},
+				[]interface{}{`{"id": 2}`, "x"},
+				[]interface{}{`{"id": 4}`, "d"},
+			)
+		// Run test
+		result, err := DiffTableData(context.Background(), "public.plans", local.MockClient(t), remote.MockClient(t))
+		// Check error
+		assert.NoError(t, err)
+		assert.Equal(t, []string{`{"id": 3, "name": "team"}`}, result.Inserted)
+		assert.Equal(t, []string{`{"id": 2, "name": "pro"}`}, result.Updated)
+		assert.Equal(t, []string{`{"id": 4}`}, result.Deleted)
+	})
+
+	t.Run("throws error on missing primary key", func(t *testing.T) {
+		// Setup mock postgres
+		local := pgtest.NewConn()
+		defer local.Close(t)
+		local.Query(LIST_TABLE_COLUMNS, table.Sanitize()).
+			Reply("SELECT 1", []interface{}{"name", false})
+		// Run test
+		_, err := DiffTableData(context.Background(), "plans", local.MockClient(t), nil)
+		// Check error
+		assert.ErrorContains(t, err, "table plans has no primary key")
+	})
+
+	t.Run("throws error on missing table", func(t *testing.T) {
+		// Setup mock postgres
+		local := pgtest.NewConn()
+		defer local.Close(t)
+		local.Query(LIST_TABLE_COLUMNS, table.Sanitize()).
+			ReplyError(pgerrcode.UndefinedTable, `relation "public.plans" does not exist`)
+		// Run test
+		_, err := DiffTableData(context.Background(), "public.plans", local.MockClient(t), nil)
+		// Check error
+		assert.ErrorContains(t, err, `ERROR: relation "public.plans" does not exist (SQLSTATE 42P01)`)
+	})
+}
+
+func TestSyncStatements(t *testing.T) {
+	t.Run("generates statements in dependency order", func(t *testing.T) {
+		result := []TableDiff{{
+			Table:    pgx.Identifier{"public", "plans"},
+			Columns:  []Column{{Name: "id", Primary: true}, {Name: "name"}},
+			Inserted: []string{`{"id": 3, "name": "O'Brien"}`},
+			Updated:  []string{`{"id": 2, "name": "pro"}`},
+		}, {
+			Table:   pgx.Identifier{"public", "features"},
+			Columns: []Column{{Name: "id", Primary: true}},
+			Deleted: []string{`{"id": 4}`},
+		}}
+		// Run test
+		out := ToSyncStatements(result)
+		// Check output
+		assert.Equal(t, `DELETE FROM "public"."features" t USING jsonb_populate_record(NULL::"public"."features", '{"id": 4}') r WHERE t."id" = r."id";
+UPDATE "public"."plans" t SET ("name") = ROW(r."name") FROM jsonb_populate_record(NULL::"public"."plans", '{"id": 2, "name": "pro"}') r WHERE t."id" = r."id";
+INSERT INTO "public"."plans" ("id", "name") OVERRIDING SYSTEM VALUE SELECT "id", "name" FROM jsonb_populate_record(NULL::"public"."plans", '{"id": 3, "name": "O''Brien"}');
+`, out)
+	})
+
+	t.Run("returns empty on no changes", func(t *testing.T) {
+		assert.Empty(t, ToSyncStatements([]TableDiff{{Table: pgx.Identifier{"public", "plans"}}}))
+	})
+}
@@ -0,0 +1,177 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/db/start"
+	"github.com/supabase/cli/internal/utils"
+)
+
+// Diffs the schema produced by applying local migrations at two git revisions.
+func RunRefs(ctx context.Context, fromRef, toRef string, schema []string, file string, differ DiffFunc, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
+	// Sanity checks.
+	if err := utils.LoadConfigFS(fsys); err != nil {
+		return err
+	}
+	opts := &git.PlainOpenOptions{DetectDotGit: true}
+	repo, err := git.PlainOpenWithOptions(".", opts)
+	if err != nil {
+		return errors.Errorf("failed to open git repository: %w", err)
+	}
+	prefix, err := getRepoPrefix(repo)
+	if err != nil {
+		return err
+	}
+	fromFs, err := LoadMigrationsAtRef(repo, fromRef, prefix)
+	if err != nil {
+		return err
+	}
+	toFs, err := LoadMigrationsAtRef(repo, toRef, prefix)
+	if err != nil {
+		return err
+	}
+	// 1. Apply migrations at each revision to its own shadow database
+	fromPort := utils.Config.Db.ShadowPort
+	source, err := createShadowAtRef(ctx, fromRef, fromPort, fromFs, options...)
+	if len(source) > 0 {
+		defer utils.DockerRemove(source)
+	}
+	if err != nil {
+		return err
+	}
+	toPort, err := getFreePort()
+	if err != nil {
+		return err
+	}
+	target, err := createShadowAtRef(ctx, toRef, toPort, toFs, options...)
+	if len(target) > 0 {
+		defer utils.DockerRemove(target)
+	}
+	if err != nil {
+		return err
+	}
+	// 2. Load all user defined schemas
+	fromConfig := getShadowConfig(fromPort)
+	toConfig := getShadowConfig(toPort)
+	if len(schema) == 0 {
+		if schema, err = loadSchema(ctx, toConfig, options...); err != nil {
+			return err
+		}
+	}
+	// 3. Diff the two shadow databases
+	fmt.Fprintln(os.Stderr, "Diffing schemas:", strings.Join(schema, ","))
+	out, err := differ(ctx, utils.ToPostgresURL(fromConfig), utils.ToPostgresURL(toConfig), schema)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Finished "+utils.Aqua("supabase db diff")+" from "+utils.Aqua(fromRef)+" to "+utils.Aqua(toRef)+".\n")
+	return SaveDiff(out, file, fsys)
+}
+
+// Returns the path of current working directory relative to the repository root.
+func getRepoPrefix(repo *git.Repository) (string, error) {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", errors.Errorf("failed to load git worktree: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", errors.Errorf("failed to get working directory: %w", err)
+	}
+	rel, err := filepath.Rel(wt.Filesystem.Root(), cwd)
+	if err != nil {
+		return "", errors.Errorf("failed to resolve project path: %w", err)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// Copies local migrations and custom roles at the given git revision into an in-memory fs.
+func LoadMigrationsAtRef(repo *git.Repository, ref, prefix string) (afero.Fs, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, errors.Errorf("failed to resolve git revision %s: %w", ref, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, errors.Errorf("failed to load git commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, errors.Errorf("failed to load git tree: %w", err)
+	}
+	fsys := afero.NewMemMapFs()
+	migrationsDir := path.Join(prefix, filepath.ToSlash(utils.MigrationsDir))
+	if dir, err := tree.Tree(migrationsDir); errors.Is(err, object.ErrDirectoryNotFound) {
+		fmt.Fprintf(os.Stderr, "No migrations found at %s\n", utils.Aqua(ref))
+	} else if err != nil {
+		return nil, errors.Errorf("failed to load migrations dir: %w", err)
+	} else if err := dir.Files().ForEach(func(f *object.File) error {
+		return copyGitFile(f, filepath.Join(utils.MigrationsDir, filepath.FromSlash(f.Name)), fsys)
+	}); err != nil {
+		return nil, err
+	}
+	rolesPath := path.Join(prefix, filepath.ToSlash(utils.CustomRolesPath))
+	if f, err := tree.File(rolesPath); err == nil {
+		if err := copyGitFile(f, utils.CustomRolesPath, fsys); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, object.ErrFileNotFound) {
+		return nil, errors.Errorf("failed to load custom roles: %w", err)
+	}
+	return fsys, nil
+}
+
+func copyGitFile(f *object.File, dst string, fsys afero.Fs) error {
+	contents, err := f.Contents()
+	if err != nil {
+		return errors.Errorf("failed to read git file: %w", err)
+	}
+	return utils.WriteFile(dst, []byte(contents), fsys)
+}
+
+func createShadowAtRef(ctx context.Context, ref string, port uint16, fsys afero.Fs, options ...func(*pgx.ConnConfig)) (string, error) {
+	fmt.Fprintln(os.Stderr, "Creating shadow database for "+utils.Aqua(ref)+"...")
+	shadow, err := CreateShadowDatabase(ctx, port)
+	if err != nil {
+		return "", err
+	}
+	if err := start.WaitForHealthyService(ctx, start.HealthTimeout, shadow); err != nil {
+		return shadow, err
+	}
+	opts := append([]func(*pgx.ConnConfig){func(cc *pgx.ConnConfig) {
+		cc.Port = port
+	}}, options...)
+	return shadow, MigrateShadowDatabase(ctx, shadow, fsys, opts...)
+}
+
+func getShadowConfig(port uint16) pgconn.Config {
+	return pgconn.Config{
+		Host:     utils.Config.Hostname,
+		Port:     port,
+		User:     "postgres",
+		Password: utils.Config.Db.Password,
+		Database: "postgres",
+	}
+}
+
+func getFreePort() (uint16, error) {
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return 0, errors.Errorf("failed to find free port: %w", err)
+	}
+	defer l.Close()
+	return uint16(l.Addr().(*net.TCPAddr).Port), nil
+}
@@ -0,0 +1,84 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/internal/utils"
+)
+
+func commitFile(t *testing.T, repo *git.Repository, root, name, contents string) {
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	path := filepath.Join(root, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+	_, err = wt.Add(filepath.ToSlash(name))
+	require.NoError(t, err)
+	_, err = wt.Commit("add "+name, &git.CommitOptions{Author: &object.Signature{
+		Name: "test",
+		When: time.Now(),
+	}})
+	require.NoError(t, err)
+}
+
+func TestLoadMigrationsAtRef(t *testing.T) {
+	// Setup git repo
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	require.NoError(t, err)
+	first := filepath.Join("app", utils.MigrationsDir, "0_init.sql")
+	commitFile(t, repo, root, first, "create schema first")
+	second := filepath.Join("app", utils.MigrationsDir, "1_next.sql")
+	commitFile(t, repo, root, second, "create schema second")
+	commitFile(t, repo, root, filepath.Join("app", utils.CustomRolesPath), "create role test")
+
+	t.Run("loads migrations at revision", func(t *testing.T) {
+		// Run test
+		fsys, err := LoadMigrationsAtRef(repo, "HEAD~1", "app")
+		// Check error
+		assert.NoError(t, err)
+		files, err := afero.ReadDir(fsys, utils.MigrationsDir)
+		assert.NoError(t, err)
+		require.Len(t, files, 2)
+		assert.Equal(t, "0_init.sql", files[0].Name())
+		assert.Equal(t, "1_next.sql", files[1].Name())
+		exists, err := afero.Exists(fsys, utils.CustomRolesPath)
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("loads custom roles at head", func(t *testing.T) {
+		// Run test
+		fsys, err := LoadMigrationsAtRef(repo, "HEAD", "app")
+		// Check error
+		assert.NoError(t, err)
+		contents, err := afero.ReadFile(fsys, utils.CustomRolesPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "create role test", string(contents))
+	})
+
+	t.Run("ignores missing migrations dir", func(t *testing.T) {
+		// Run test
+		fsys, err := LoadMigrationsAtRef(repo, "HEAD", "other")
+		// Check error
+		assert.NoError(t, err)
+		exists, err := afero.DirExists(fsys, utils.MigrationsDir)
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("throws error on unknown revision", func(t *testing.T) {
+		// Run test
+		_, err := LoadMigrationsAtRef(repo, "missing", "app")
+		// Check error
+		assert.ErrorContains(t, err, "failed to resolve git revision missing")
+	})
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/jackc/pgconn"
 	"github.com/spf13/afero"
 	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/pkg/archive"
 	cliConfig "github.com/supabase/cli/pkg/config"
 )
 
@@ -26,9 +27,9 @@ var (
 	dumpRoleScript string
 )
 
-func Run(ctx context.Context, path string, config pgconn.Config, schema, excludeTable []string, dataOnly, roleOnly, keepComments, useCopy, dryRun bool, fsys afero.Fs) error {
+func Run(ctx context.Context, path string, compression archive.Compression, config pgconn.Config, schema, excludeTable []string, dataOnly, roleOnly, keepComments, useCopy, dryRun bool, fsys afero.Fs) (err error) {
 	// Initialize output stream
-	var outStream afero.File
+	var outStream io.Writer
 	if len(path) > 0 {
 		f, err := fsys.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
 		if err != nil {
@@ -42,6 +43,18 @@ func Run(ctx context.Context, path string, config pgconn.Config, schema, exclude
 	// Load the requested script
 	if dryRun {
 		fmt.Fprintln(os.Stderr, "DRY RUN: *only* printing the pg_dump script to console.")
+	} else {
+		w, err := newOutputWriter(outStream, path, compression)
+		if err != nil {
+			return err
+		}
+		// Flush compressed output before closing the dump file
+		defer func() {
+			if closeErr := w.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		outStream = w
 	}
 	db := "remote"
 	if utils.IsLocalDatabase(config) {
@@ -58,6 +71,20 @@ func Run(ctx context.Context, path string, config pgconn.Config, schema, exclude
 	return DumpSchema(ctx, config, schema, keepComments, dryRun, outStream)
 }
 
+func newOutputWriter(w io.Writer, path string, compression archive.Compression) (io.WriteCloser, error) {
+	if len(compression) == 0 {
+		compression = archive.InferCompression(path)
+	}
+	recipients, err := archive.ParseRecipients(utils.Config.Db.Dump.Recipients)
+	if err != nil {
+		return nil, err
+	}
+	if len(recipients) > 0 {
+		fmt.Fprintf(os.Stderr, "Encrypting dump to %d recipients...\n", len(recipients))
+	}
+	return archive.NewWriter(w, compression, recipients...)
+}
+
 func DumpSchema(ctx context.Context, config pgconn.Config, schema []string, keepComments, dryRun bool, stdout io.Writer) error {
 	var env []string
 	if len(schema) > 0 {
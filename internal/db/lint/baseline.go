@@ -0,0 +1,195 @@
+package lint
+
+import (
+	"context"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/utils"
+)
+
+var (
+	//go:embed templates/suppressions.sql
+	listSuppressionsScript string
+
+	ignorePattern = regexp.MustCompile(`--\s*supabase-lint-ignore:[ \t]*([\w \t,.-]+)`)
+)
+
+// Returns a stable identifier for an issue that does not depend on line numbers.
+func Fingerprint(function string, issue Issue) string {
+	h := sha256.New()
+	for _, s := range []string{function, ruleId(issue), issue.Level, issue.Message} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Returns true if rule matches the rule name, SQL state, or category of an issue.
+func matchRule(rule string, issue Issue) bool {
+	if rule == ruleId(issue) {
+		return true
+	}
+	category := CategoryPlpgsql
+	for _, r := range Rules {
+		if r.Name == issue.Rule {
+			category = r.Category
+		}
+	}
+	return rule == category
+}
+
+type Suppressions struct {
+	Rules   []string
+	Objects []string
+	// Rules ignored by inline comments, keyed by object name
+	Inline map[string][]string
+}
+
+func (s Suppressions) Ignore(function string, issue Issue) bool {
+	for _, pattern := range s.Objects {
+		if matched, _ := path.Match(pattern, function); matched {
+			return true
+		}
+	}
+	for _, rule := range s.Inline[function] {
+		if matchRule(rule, issue) {
+			return true
+		}
+	}
+	for _, rule := range s.Rules {
+		if matchRule(rule, issue) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parses inline suppressions from function bodies, ie. -- supabase-lint-ignore: rls_no_policy
+func ParseSuppressions(body string) (rules []string) {
+	for _, m := range ignorePattern.FindAllStringSubmatch(body, -1) {
+		for _, r := range strings.Split(m[1], ",") {
+			if r = strings.TrimSpace(r); len(r) > 0 {
+				rules = append(rules, r)
+			}
+		}
+	}
+	return rules
+}
+
+// Maps each function with inline suppressions to the list of ignored rules.
+func ListSuppressions(ctx context.Context, conn *pgx.Conn, names []string) (map[string][]string, error) {
+	rows, err := conn.Query(ctx, listSuppressionsScript, names)
+	if err != nil {
+		return nil, errors.Errorf("failed to list suppressions: %w", err)
+	}
+	result := map[string][]string{}
+	for rows.Next() {
+		var name, body string
+		if err := rows.Scan(&name, &body); err != nil {
+			return nil, errors.Errorf("failed to scan rows: %w", err)
+		}
+		result[name] = append(result[name], ParseSuppressions(body)...)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Errorf("failed to parse rows: %w", err)
+	}
+	return result, nil
+}
+
+func filterIssues(result []Result, skip func(string, Issue) bool) (filtered []Result, skipped int) {
+	for _, r := range result {
+		out := Result{Function: r.Function}
+		for _, issue := range r.Issues {
+			if skip(r.Function, issue) {
+				skipped++
+			} else {
+				out.Issues = append(out.Issues, issue)
+			}
+		}
+		if len(out.Issues) > 0 {
+			filtered = append(filtered, out)
+		}
+	}
+	return filtered, skipped
+}
+
+type BaselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Function    string `json:"function"`
+	Rule        string `json:"rule"`
+	Message     string `json:"message"`
+}
+
+type Baseline struct {
+	Issues []BaselineEntry `json:"issues"`
+}
+
+func (b Baseline) Contains(function string, issue Issue) bool {
+	fp := Fingerprint(function, issue)
+	return slices.ContainsFunc(b.Issues, func(e BaselineEntry) bool {
+		return e.Fingerprint == fp
+	})
+}
+
+func NewBaseline(result []Result) Baseline {
+	baseline := Baseline{Issues: []BaselineEntry{}}
+	for _, r := range result {
+		for _, issue := range r.Issues {
+			baseline.Issues = append(baseline.Issues, BaselineEntry{
+				Fingerprint: Fingerprint(r.Function, issue),
+				Function:    r.Function,
+				Rule:        ruleId(issue),
+				Message:     issue.Message,
+			})
+		}
+	}
+	// Sort entries to minimise diffs when the baseline is committed
+	sort.SliceStable(baseline.Issues, func(i, j int) bool {
+		return baseline.Issues[i].Fingerprint < baseline.Issues[j].Fingerprint
+	})
+	return baseline
+}
+
+func LoadBaseline(fsys afero.Fs) (Baseline, error) {
+	var baseline Baseline
+	data, err := afero.ReadFile(fsys, utils.LintBaselinePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return baseline, nil
+	} else if err != nil {
+		return baseline, errors.Errorf("failed to read lint baseline: %w", err)
+	}
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return baseline, errors.Errorf("failed to parse lint baseline: %w", err)
+	}
+	return baseline, nil
+}
+
+func WriteBaseline(result []Result, fsys afero.Fs) error {
+	baseline := NewBaseline(result)
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return errors.Errorf("failed to marshal lint baseline: %w", err)
+	}
+	if err := utils.MkdirIfNotExistFS(fsys, filepath.Dir(utils.LintBaselinePath)); err != nil {
+		return err
+	}
+	if err := afero.WriteFile(fsys, utils.LintBaselinePath, append(data, '\n'), 0644); err != nil {
+		return errors.Errorf("failed to write lint baseline: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d issues to %s\n", len(baseline.Issues), utils.Bold(utils.LintBaselinePath))
+	return nil
+}
@@ -0,0 +1,145 @@
+package lint
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/pkg/pgtest"
+)
+
+func TestParseSuppressions(t *testing.T) {
+	body := `
+begin
+  -- supabase-lint-ignore: 42703, never_read
+  perform 1;
+  --supabase-lint-ignore:plpgsql
+end;`
+	assert.Equal(t, []string{"42703", "never_read", "plpgsql"}, ParseSuppressions(body))
+	assert.Empty(t, ParseSuppressions("select 1"))
+}
+
+func TestSuppressions(t *testing.T) {
+	plpgsql := Issue{Level: "error", Message: "undefined column", SQLState: "42703"}
+	security := Issue{Level: "warning", Message: "no policy", Rule: "rls_no_policy"}
+
+	t.Run("ignores by rule name or category", func(t *testing.T) {
+		s := Suppressions{Rules: []string{"security"}}
+		assert.True(t, s.Ignore("public.t1", security))
+		assert.False(t, s.Ignore("public.f1", plpgsql))
+		s = Suppressions{Rules: []string{"42703"}}
+		assert.True(t, s.Ignore("public.f1", plpgsql))
+	})
+
+	t.Run("ignores by object pattern", func(t *testing.T) {
+		s := Suppressions{Objects: []string{"audit.*"}}
+		assert.True(t, s.Ignore("audit.logs", security))
+		assert.False(t, s.Ignore("public.logs", security))
+	})
+
+	t.Run("ignores inline rules per object", func(t *testing.T) {
+		s := Suppressions{Inline: map[string][]string{"public.f1": {"plpgsql"}}}
+		assert.True(t, s.Ignore("public.f1", plpgsql))
+		assert.False(t, s.Ignore("public.f2", plpgsql))
+	})
+}
+
+func TestBaseline(t *testing.T) {
+	result := []Result{{
+		Function: "public.f1",
+		Issues: []Issue{{
+			Level:   "error",
+			Message: "test error",
+			Statement: &Statement{
+				LineNumber: "3",
+				Text:       "RAISE",
+			},
+		}},
+	}}
+
+	t.Run("fingerprint ignores line numbers", func(t *testing.T) {
+		moved := result[0].Issues[0]
+		moved.Statement = &Statement{LineNumber: "7", Text: "RAISE"}
+		assert.Equal(t, Fingerprint("public.f1", result[0].Issues[0]), Fingerprint("public.f1", moved))
+		assert.NotEqual(t, Fingerprint("public.f1", moved), Fingerprint("public.f2", moved))
+	})
+
+	t.Run("writes and loads baseline", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		// Run test
+		require.NoError(t, WriteBaseline(result, fsys))
+		baseline, err := LoadBaseline(fsys)
+		// Check error
+		assert.NoError(t, err)
+		require.Len(t, baseline.Issues, 1)
+		assert.Equal(t, "plpgsql_check", baseline.Issues[0].Rule)
+		assert.True(t, baseline.Contains("public.f1", result[0].Issues[0]))
+	})
+
+	t.Run("loads empty baseline", func(t *testing.T) {
+		baseline, err := LoadBaseline(afero.NewMemMapFs())
+		assert.NoError(t, err)
+		assert.False(t, baseline.Contains("public.f1", result[0].Issues[0]))
+	})
+
+	t.Run("throws error on malformed baseline", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, utils.LintBaselinePath, []byte("malformed"), 0644))
+		// Run test
+		_, err := LoadBaseline(fsys)
+		// Check error
+		assert.ErrorContains(t, err, "failed to parse lint baseline:")
+	})
+
+	t.Run("fails only on new issues", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, WriteBaseline([]Result{{
+			Function: "public.f1",
+			Issues:   []Issue{{Level: "error", Message: "old error"}},
+		}}, fsys))
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query("begin").Reply("BEGIN").
+			Query(ENABLE_PGSQL_CHECK).
+			Reply("CREATE EXTENSION").
+			Query(checkSchemaScript, "public").
+			Reply("SELECT 1", []interface{}{"f1", `{"function":"22751","issues":[{"level":"error","message":"old error"}]}`}).
+			Query("rollback").Reply("ROLLBACK").
+			Query(listSuppressionsScript, []string{"public.f1"}).
+			Reply("SELECT 0")
+		// Run test
+		err := Run(context.Background(), []string{"public"}, []string{CategoryPlpgsql}, "warning", "error", FormatJson, false, dbConfig, fsys, conn.Intercept)
+		// Check error
+		assert.NoError(t, err)
+	})
+
+	t.Run("suppresses inline rules", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query("begin").Reply("BEGIN").
+			Query(ENABLE_PGSQL_CHECK).
+			Reply("CREATE EXTENSION").
+			Query(checkSchemaScript, "public").
+			Reply("SELECT 1", []interface{}{"f1", `{"function":"22751","issues":[{"level":"error","message":"test error","sqlState":"42703"}]}`}).
+			Query("rollback").Reply("ROLLBACK").
+			Query(listSuppressionsScript, []string{"public.f1"}).
+			Reply("SELECT 1", []interface{}{"public.f1", "-- supabase-lint-ignore: 42703"})
+		// Run test
+		err := Run(context.Background(), []string{"public"}, []string{CategoryPlpgsql}, "warning", "error", FormatJson, true, dbConfig, fsys, conn.Intercept)
+		// Check error
+		assert.NoError(t, err)
+		baseline, err := LoadBaseline(fsys)
+		assert.NoError(t, err)
+		assert.Empty(t, baseline.Issues)
+	})
+}
@@ -0,0 +1,325 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/pkg/junit"
+	"github.com/supabase/cli/pkg/migration"
+)
+
+const (
+	FormatJson   = "json"
+	FormatSarif  = "sarif"
+	FormatJunit  = "junit"
+	FormatGithub = "github"
+)
+
+var (
+	AllowedFormats = []string{
+		FormatJson,
+		FormatSarif,
+		FormatJunit,
+		FormatGithub,
+	}
+
+	createObjectPattern = regexp.MustCompile(`(?i)\bcreate\s+(?:or\s+replace\s+)?(?:(function)|(?:unlogged\s+)?table|(?:materialized\s+)?view)(?:\s+if\s+not\s+exists)?\s+(?:("[^"]+"|\w+)\s*\.\s*)?("[^"]+"|\w+)`)
+	dollarQuotePattern  = regexp.MustCompile(`\$\w*\$`)
+)
+
+// Location of a database object in local migration files.
+type Location struct {
+	Path string
+	// Line number where the function body or object definition starts
+	Line int
+}
+
+// Resolves the file and line number of an issue. Returns an empty path if the
+// object is not defined in any local migration file.
+func (l Location) Resolve(issue Issue) (string, int) {
+	if len(l.Path) == 0 {
+		return "", 0
+	}
+	line := l.Line
+	if issue.Statement != nil {
+		// Statement line numbers are relative to the start of function body
+		if n, err := strconv.Atoi(issue.Statement.LineNumber); err == nil && n > 0 {
+			line += n - 1
+		}
+	}
+	return filepath.ToSlash(l.Path), line
+}
+
+// Maps each fully qualified function, table, or view name to its last definition
+// in local migrations.
+func LoadObjectLocations(fsys afero.Fs) (map[string]Location, error) {
+	migrations, err := migration.ListLocalMigrations(utils.MigrationsDir, afero.NewIOFS(fsys))
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]Location{}
+	for _, path := range migrations {
+		contents, err := afero.ReadFile(fsys, path)
+		if err != nil {
+			return nil, errors.Errorf("failed to read migration: %w", err)
+		}
+		sql := string(contents)
+		for _, m := range createObjectPattern.FindAllStringSubmatchIndex(sql, -1) {
+			schema := "public"
+			if m[4] >= 0 {
+				schema = unquoteIdent(sql[m[4]:m[5]])
+			}
+			name := schema + "." + unquoteIdent(sql[m[6]:m[7]])
+			start := m[0]
+			// Function issues are reported relative to the start of its body
+			if m[2] >= 0 {
+				if loc := dollarQuotePattern.FindStringIndex(sql[m[1]:]); loc != nil {
+					start = m[1] + loc[0]
+				}
+			}
+			result[name] = Location{
+				Path: path,
+				Line: strings.Count(sql[:start], "\n") + 1,
+			}
+		}
+	}
+	return result, nil
+}
+
+func unquoteIdent(ident string) string {
+	if unquoted, ok := strings.CutPrefix(ident, `"`); ok {
+		return strings.TrimSuffix(unquoted, `"`)
+	}
+	return strings.ToLower(ident)
+}
+
+// Maps lint level to SARIF severity, ie. error, warning, or note.
+func toSeverity(level string) string {
+	switch {
+	case toEnum(level) == 1:
+		return "error"
+	case level == AllowedLevels[0]:
+		return "warning"
+	}
+	// Extra and performance warnings are informational
+	return "note"
+}
+
+func printResult(result []Result, format string, stdout io.Writer, fsys afero.Fs) error {
+	if format == FormatJson {
+		return printResultJSON(result, stdout)
+	}
+	locations, err := LoadObjectLocations(fsys)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case FormatSarif:
+		return printResultSarif(result, locations, stdout)
+	case FormatJunit:
+		return printResultJunit(result, locations, stdout)
+	case FormatGithub:
+		return printResultGithub(result, locations, stdout)
+	}
+	return errors.Errorf("unsupported format: %s", format)
+}
+
+func ruleId(issue Issue) string {
+	if len(issue.Rule) > 0 {
+		return issue.Rule
+	}
+	if len(issue.SQLState) > 0 {
+		return issue.SQLState
+	}
+	return "plpgsql_check"
+}
+
+// Ref: https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string `json:"name"`
+	InformationUri string `json:"informationUri"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+func printResultSarif(result []Result, locations map[string]Location, stdout io.Writer) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "supabase-db-lint",
+			InformationUri: "https://supabase.com/docs/reference/cli/supabase-db-lint",
+		}},
+		Results: []sarifResult{},
+	}
+	for _, r := range result {
+		for _, issue := range r.Issues {
+			kind := "function"
+			if len(issue.Rule) > 0 {
+				kind = "resource"
+			}
+			loc := sarifLocation{LogicalLocations: []sarifLogicalLocation{{
+				FullyQualifiedName: r.Function,
+				Kind:               kind,
+			}}}
+			if path, line := locations[r.Function].Resolve(issue); len(path) > 0 {
+				loc.PhysicalLocation = &sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{Uri: path},
+					Region:           &sarifRegion{StartLine: line},
+				}
+			}
+			run.Results = append(run.Results, sarifResult{
+				RuleId:    ruleId(issue),
+				Level:     toSeverity(issue.Level),
+				Message:   sarifMessage{Text: formatMessage(issue)},
+				Locations: []sarifLocation{loc},
+			})
+		}
+	}
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(log); err != nil {
+		return errors.Errorf("failed to print result sarif: %w", err)
+	}
+	return nil
+}
+
+func printResultJunit(result []Result, locations map[string]Location, stdout io.Writer) error {
+	suite := junit.TestSuite{Name: "db lint"}
+	for _, r := range result {
+		for _, issue := range r.Issues {
+			path, line := locations[r.Function].Resolve(issue)
+			tc := junit.TestCase{
+				Name:      r.Function,
+				ClassName: ruleId(issue),
+				File:      path,
+				Line:      line,
+			}
+			msg := junit.Message{
+				Message: issue.Message,
+				Type:    issue.Level,
+				Text:    formatMessage(issue),
+			}
+			// JUnit has no concept of severity so we report errors separately from failures
+			if toSeverity(issue.Level) == "error" {
+				tc.Error = &msg
+			} else {
+				tc.Failure = &msg
+			}
+			suite.Add(tc)
+		}
+	}
+	report := junit.TestSuites{Name: "supabase"}
+	report.Add(suite)
+	return report.Write(stdout)
+}
+
+// Ref: https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#setting-an-error-message
+func printResultGithub(result []Result, locations map[string]Location, stdout io.Writer) error {
+	for _, r := range result {
+		for _, issue := range r.Issues {
+			command := toSeverity(issue.Level)
+			if command == "note" {
+				command = "notice"
+			}
+			props := []string{"title=" + escapeProperty(r.Function)}
+			if path, line := locations[r.Function].Resolve(issue); len(path) > 0 {
+				props = append(props, "file="+escapeProperty(path), "line="+strconv.Itoa(line))
+			}
+			if _, err := fmt.Fprintf(stdout, "::%s %s::%s\n", command, strings.Join(props, ","), escapeData(formatMessage(issue))); err != nil {
+				return errors.Errorf("failed to print result annotation: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func escapeData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+func escapeProperty(s string) string {
+	s = escapeData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
+
+func formatMessage(issue Issue) string {
+	lines := []string{issue.Message}
+	if issue.Statement != nil {
+		lines = append(lines, "Statement: "+issue.Statement.Text)
+	}
+	if issue.Query != nil {
+		lines = append(lines, "Query: "+issue.Query.Text)
+	}
+	if len(issue.Detail) > 0 {
+		lines = append(lines, "Detail: "+issue.Detail)
+	}
+	if len(issue.Hint) > 0 {
+		lines = append(lines, "Hint: "+issue.Hint)
+	}
+	if len(issue.Context) > 0 {
+		lines = append(lines, "Context: "+issue.Context)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,125 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/internal/utils"
+)
+
+const testMigration = `create table t1 (id int);
+
+create or replace function public.f1()
+returns void
+language plpgsql
+as $$
+declare
+  r record;
+begin
+  select * into r from t1;
+  raise notice '%', r.c;
+end;
+$$;
+
+CREATE FUNCTION "Private"."F2"() RETURNS int AS $fn$ select 1 $fn$ LANGUAGE sql;
+`
+
+func TestLoadObjectLocations(t *testing.T) {
+	t.Run("maps objects to migration lines", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		path := filepath.Join(utils.MigrationsDir, "20240101000000_test.sql")
+		require.NoError(t, afero.WriteFile(fsys, path, []byte(testMigration), 0644))
+		// Run test
+		locations, err := LoadObjectLocations(fsys)
+		// Check error
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]Location{
+			"public.t1":  {Path: path, Line: 1},
+			"public.f1":  {Path: path, Line: 6},
+			"Private.F2": {Path: path, Line: 15},
+		}, locations)
+	})
+
+	t.Run("ignores missing migrations", func(t *testing.T) {
+		locations, err := LoadObjectLocations(afero.NewMemMapFs())
+		assert.NoError(t, err)
+		assert.Empty(t, locations)
+	})
+}
+
+func TestPrintFormats(t *testing.T) {
+	result := []Result{{
+		Function: "public.f1",
+		Issues: []Issue{{
+			Level:   "error",
+			Message: `record "r" has no field "c"`,
+			Statement: &Statement{
+				LineNumber: "6",
+				Text:       "RAISE",
+			},
+			SQLState: "42703",
+		}, {
+			Level:   "warning extra",
+			Message: "never read variable",
+		}},
+	}, {
+		Function: "public.f3",
+		Issues: []Issue{{
+			Level:   "warning",
+			Message: "test, warning",
+		}},
+	}}
+	locations := map[string]Location{
+		"public.f1": {Path: "supabase/migrations/0_test.sql", Line: 6},
+	}
+
+	t.Run("prints sarif", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, printResultSarif(result, locations, &out))
+		// Validate output
+		var actual sarifLog
+		require.NoError(t, json.Unmarshal(out.Bytes(), &actual))
+		require.Len(t, actual.Runs, 1)
+		results := actual.Runs[0].Results
+		require.Len(t, results, 3)
+		assert.Equal(t, "error", results[0].Level)
+		assert.Equal(t, "42703", results[0].RuleId)
+		assert.Equal(t, "supabase/migrations/0_test.sql", results[0].Locations[0].PhysicalLocation.ArtifactLocation.Uri)
+		assert.Equal(t, 11, results[0].Locations[0].PhysicalLocation.Region.StartLine)
+		assert.Equal(t, "note", results[1].Level)
+		assert.Equal(t, 6, results[1].Locations[0].PhysicalLocation.Region.StartLine)
+		assert.Equal(t, "warning", results[2].Level)
+		assert.Nil(t, results[2].Locations[0].PhysicalLocation)
+	})
+
+	t.Run("prints junit", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, printResultJunit(result, locations, &out))
+		// Validate output
+		assert.Contains(t, out.String(), `<testsuites name="supabase" tests="3" failures="2" errors="1"`)
+		assert.Contains(t, out.String(), `<testcase name="public.f1" classname="42703" file="supabase/migrations/0_test.sql" line="11"`)
+		assert.Contains(t, out.String(), `<error message="record &#34;r&#34; has no field &#34;c&#34;" type="error">`)
+	})
+
+	t.Run("prints github annotations", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, printResultGithub(result, locations, &out))
+		// Validate output
+		assert.Equal(t, `::error title=public.f1,file=supabase/migrations/0_test.sql,line=11::record "r" has no field "c"%0AStatement: RAISE
+::notice title=public.f1,file=supabase/migrations/0_test.sql,line=6::never read variable
+::warning title=public.f3::test, warning
+`, out.String())
+	})
+
+	t.Run("prints empty report", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, printResult(nil, FormatSarif, &out, afero.NewMemMapFs()))
+		assert.Contains(t, out.String(), `"results": []`)
+	})
+}
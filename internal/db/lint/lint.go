@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/go-errors/errors"
@@ -39,27 +40,44 @@ func toEnum(level string) LintLevel {
 	return -1
 }
 
-func Run(ctx context.Context, schema []string, level string, failOn string, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
+func Run(ctx context.Context, schema, categories []string, level, failOn, format string, writeBaseline bool, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
 	// Sanity checks.
+	if err := validateCategories(categories); err != nil {
+		return err
+	}
 	conn, err := utils.ConnectByConfig(ctx, config, options...)
 	if err != nil {
 		return err
 	}
 	defer conn.Close(context.Background())
 	// Run lint script
-	result, err := LintDatabase(ctx, conn, schema)
+	result, err := LintDatabase(ctx, conn, schema, categories)
 	if err != nil {
 		return err
 	}
 	if len(result) == 0 {
 		fmt.Fprintln(os.Stderr, "\nNo schema errors found")
-		return nil
+	} else if result, err = applySuppressions(ctx, conn, result); err != nil {
+		return err
 	}
 
 	// Apply filtering based on the minimum level
 	minLevel := toEnum(level)
 	filtered := filterResult(result, minLevel)
-	err = printResultJSON(filtered, os.Stdout)
+	if writeBaseline {
+		return WriteBaseline(filtered, fsys)
+	}
+	// Only new issues are reported when a baseline exists
+	baseline, err := LoadBaseline(fsys)
+	if err != nil {
+		return err
+	}
+	filtered, skipped := filterIssues(filtered, baseline.Contains)
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, "Skipped %d issues in %s\n", skipped, utils.Bold(utils.LintBaselinePath))
+	}
+	// CI reports are written even when there are no issues
+	err = printResult(filtered, format, os.Stdout, fsys)
 	if err != nil {
 		return err
 	}
@@ -77,6 +95,27 @@ func Run(ctx context.Context, schema []string, level string, failOn string, conf
 	return nil
 }
 
+func applySuppressions(ctx context.Context, conn *pgx.Conn, result []Result) ([]Result, error) {
+	names := make([]string, len(result))
+	for i, r := range result {
+		names[i] = r.Function
+	}
+	inline, err := ListSuppressions(ctx, conn, names)
+	if err != nil {
+		return nil, err
+	}
+	s := Suppressions{
+		Rules:   utils.Config.Db.Lint.IgnoreRules,
+		Objects: utils.Config.Db.Lint.IgnoreObjects,
+		Inline:  inline,
+	}
+	filtered, skipped := filterIssues(result, s.Ignore)
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, "Suppressed %d issues\n", skipped)
+	}
+	return filtered, nil
+}
+
 func filterResult(result []Result, minLevel LintLevel) (filtered []Result) {
 	for _, r := range result {
 		out := Result{Function: r.Function}
@@ -105,7 +144,7 @@ func printResultJSON(result []Result, stdout io.Writer) error {
 	return nil
 }
 
-func LintDatabase(ctx context.Context, conn *pgx.Conn, schema []string) ([]Result, error) {
+func LintDatabase(ctx context.Context, conn *pgx.Conn, schema, categories []string) ([]Result, error) {
 	tx, err := conn.Begin(ctx)
 	if err != nil {
 		return nil, errors.Errorf("failed to begin transaction: %w", err)
@@ -122,6 +161,20 @@ func LintDatabase(ctx context.Context, conn *pgx.Conn, schema []string) ([]Resul
 			fmt.Fprintln(os.Stderr, err)
 		}
 	}()
+	var result []Result
+	if slices.Contains(categories, CategoryPlpgsql) {
+		if result, err = lintFunctions(ctx, conn, schema); err != nil {
+			return nil, err
+		}
+	}
+	issues, err := LintRules(ctx, conn, schema, categories)
+	if err != nil {
+		return nil, err
+	}
+	return append(result, issues...), nil
+}
+
+func lintFunctions(ctx context.Context, conn *pgx.Conn, schema []string) ([]Result, error) {
 	if _, err := conn.Exec(ctx, ENABLE_PGSQL_CHECK); err != nil {
 		return nil, errors.Errorf("failed to enable pgsql_check: %w", err)
 	}
@@ -181,6 +234,7 @@ type Issue struct {
 	Detail    string     `json:"detail,omitempty"`
 	Context   string     `json:"context,omitempty"`
 	SQLState  string     `json:"sqlState,omitempty"`
+	Rule      string     `json:"rule,omitempty"`
 }
 
 type Result struct {
@@ -63,9 +63,11 @@ func TestLintCommand(t *testing.T) {
 		Reply("CREATE EXTENSION").
 		Query(checkSchemaScript, "public").
 		Reply("SELECT 1", []interface{}{"f1", string(data)}).
-		Query("rollback").Reply("ROLLBACK")
+		Query("rollback").Reply("ROLLBACK").
+		Query(listSuppressionsScript, []string{"public.f1"}).
+		Reply("SELECT 0")
 	// Run test
-	err = Run(context.Background(), []string{"public"}, "warning", "none", dbConfig, fsys, conn.Intercept)
+	err = Run(context.Background(), []string{"public"}, []string{CategoryPlpgsql}, "warning", "none", FormatJson, false, dbConfig, fsys, conn.Intercept)
 	// Check error
 	assert.NoError(t, err)
 	assert.Empty(t, apitest.ListUnmatchedRequests())
@@ -122,7 +124,7 @@ func TestLintDatabase(t *testing.T) {
 			).
 			Query("rollback").Reply("ROLLBACK")
 		// Run test
-		result, err := LintDatabase(context.Background(), conn.MockClient(t), []string{"public"})
+		result, err := LintDatabase(context.Background(), conn.MockClient(t), []string{"public"}, []string{CategoryPlpgsql})
 		assert.NoError(t, err)
 		// Validate result
 		assert.ElementsMatch(t, expected, result)
@@ -163,7 +165,7 @@ func TestLintDatabase(t *testing.T) {
 			Reply("SELECT 1", []interface{}{"f2", string(r2)}).
 			Query("rollback").Reply("ROLLBACK")
 		// Run test
-		result, err := LintDatabase(context.Background(), conn.MockClient(t), []string{"public", "private"})
+		result, err := LintDatabase(context.Background(), conn.MockClient(t), []string{"public", "private"}, []string{CategoryPlpgsql})
 		// Check error
 		assert.NoError(t, err)
 		assert.ElementsMatch(t, expected, result)
@@ -178,7 +180,7 @@ func TestLintDatabase(t *testing.T) {
 			ReplyError(pgerrcode.UndefinedFile, `could not open extension control file "/usr/share/postgresql/14/extension/plpgsql_check.control": No such file or directory"`).
 			Query("rollback").Reply("ROLLBACK")
 		// Run test
-		_, err := LintDatabase(context.Background(), conn.MockClient(t), []string{"public"})
+		_, err := LintDatabase(context.Background(), conn.MockClient(t), []string{"public"}, []string{CategoryPlpgsql})
 		// Check error
 		assert.Error(t, err)
 	})
@@ -194,7 +196,7 @@ func TestLintDatabase(t *testing.T) {
 			Reply("SELECT 1", []interface{}{"f1", "malformed"}).
 			Query("rollback").Reply("ROLLBACK")
 		// Run test
-		_, err := LintDatabase(context.Background(), conn.MockClient(t), []string{"public"})
+		_, err := LintDatabase(context.Background(), conn.MockClient(t), []string{"public"}, []string{CategoryPlpgsql})
 		// Check error
 		assert.Error(t, err)
 	})
@@ -254,9 +256,11 @@ func TestPrintResult(t *testing.T) {
 			Reply("CREATE EXTENSION").
 			Query(checkSchemaScript, "public").
 			Reply("SELECT 1", []interface{}{"f1", `{"function":"22751","issues":[{"level":"warning","message":"test warning"}]}`}).
-			Query("rollback").Reply("ROLLBACK")
+			Query("rollback").Reply("ROLLBACK").
+			Query(listSuppressionsScript, []string{"public.f1"}).
+			Reply("SELECT 0")
 		// Run test
-		err := Run(context.Background(), []string{"public"}, "warning", "warning", dbConfig, fsys, conn.Intercept)
+		err := Run(context.Background(), []string{"public"}, []string{CategoryPlpgsql}, "warning", "warning", FormatJson, false, dbConfig, fsys, conn.Intercept)
 		// Check error
 		assert.ErrorContains(t, err, "fail-on is set to warning, non-zero exit")
 	})
@@ -272,9 +276,11 @@ func TestPrintResult(t *testing.T) {
 			Reply("CREATE EXTENSION").
 			Query(checkSchemaScript, "public").
 			Reply("SELECT 1", []interface{}{"f1", `{"function":"22751","issues":[{"level":"error","message":"test error"}]}`}).
-			Query("rollback").Reply("ROLLBACK")
+			Query("rollback").Reply("ROLLBACK").
+			Query(listSuppressionsScript, []string{"public.f1"}).
+			Reply("SELECT 0")
 		// Run test
-		err := Run(context.Background(), []string{"public"}, "warning", "error", dbConfig, fsys, conn.Intercept)
+		err := Run(context.Background(), []string{"public"}, []string{CategoryPlpgsql}, "warning", "error", FormatJson, false, dbConfig, fsys, conn.Intercept)
 		// Check error
 		assert.ErrorContains(t, err, "fail-on is set to error, non-zero exit")
 	})
@@ -290,9 +296,11 @@ func TestPrintResult(t *testing.T) {
 			Reply("CREATE EXTENSION").
 			Query(checkSchemaScript, "public").
 			Reply("SELECT 1", []interface{}{"f1", `{"function":"22751","issues":[{"level":"error","message":"test error"}]}`}).
-			Query("rollback").Reply("ROLLBACK")
+			Query("rollback").Reply("ROLLBACK").
+			Query(listSuppressionsScript, []string{"public.f1"}).
+			Reply("SELECT 0")
 		// Run test
-		err := Run(context.Background(), []string{"public"}, "warning", "none", dbConfig, fsys, conn.Intercept)
+		err := Run(context.Background(), []string{"public"}, []string{CategoryPlpgsql}, "warning", "none", FormatJson, false, dbConfig, fsys, conn.Intercept)
 		// Check error
 		assert.NoError(t, err)
 	})
@@ -0,0 +1,170 @@
+package lint
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/jackc/pgx/v4"
+	"github.com/supabase/cli/internal/utils"
+)
+
+const (
+	CategorySecurity    = "security"
+	CategoryPerformance = "performance"
+	CategoryPlpgsql     = "plpgsql"
+)
+
+var (
+	AllowedCategories = []string{
+		CategorySecurity,
+		CategoryPerformance,
+		CategoryPlpgsql,
+	}
+	// Catalog based rules are opt-in so that upgrading does not fail existing lint jobs
+	DefaultCategories = []string{CategoryPlpgsql}
+
+	//go:embed templates/rules/*.sql
+	rulesFS embed.FS
+
+	// Catalog based checks, ordered by category
+	Rules = []Rule{{
+		Name:     "rls_disabled",
+		Category: CategorySecurity,
+		Level:    "error",
+		Exposed:  true,
+		Hint:     "Run ALTER TABLE ... ENABLE ROW LEVEL SECURITY and create policies for each role.",
+	}, {
+		Name:     "rls_no_policy",
+		Category: CategorySecurity,
+		Level:    "warning",
+		Hint:     "Create a policy so that the table is accessible to non-superuser roles.",
+	}, {
+		Name:     "rls_references_user_metadata",
+		Category: CategorySecurity,
+		Level:    "error",
+		Hint:     "Use raw_app_meta_data or a separate table instead, since user_metadata can be modified by end users.",
+	}, {
+		Name:     "function_search_path_mutable",
+		Category: CategorySecurity,
+		Level:    "warning",
+		Exposed:  true,
+		Hint:     "Add SET search_path = '' to the function definition and schema qualify all references.",
+	}, {
+		Name:     "security_definer_view",
+		Category: CategorySecurity,
+		Level:    "error",
+		Exposed:  true,
+		Hint:     "Run ALTER VIEW ... SET (security_invoker = on) on Postgres 15 and above.",
+	}, {
+		Name:     "extension_in_public",
+		Category: CategorySecurity,
+		Level:    "warning",
+		Hint:     "Install the extension in a separate schema, such as extensions.",
+	}, {
+		Name:     "unindexed_foreign_key",
+		Category: CategoryPerformance,
+		Level:    "warning performance",
+		Hint:     "Create an index on the referencing columns of the foreign key.",
+	}, {
+		Name:     "no_primary_key",
+		Category: CategoryPerformance,
+		Level:    "warning performance",
+		Hint:     "Add a primary key to the table.",
+	}}
+)
+
+// Rule is a catalog query that returns one row of (object, message) per violation.
+type Rule struct {
+	Name     string
+	Category string
+	Level    string
+	// Only checks schemas exposed via the Data API
+	Exposed bool
+	Hint    string
+}
+
+func (r Rule) Query() (string, error) {
+	sql, err := rulesFS.ReadFile(path.Join("templates", "rules", r.Name+".sql"))
+	if err != nil {
+		return "", errors.Errorf("failed to read rule: %w", err)
+	}
+	return string(sql), nil
+}
+
+func validateCategories(categories []string) error {
+	for _, c := range categories {
+		if !slices.Contains(AllowedCategories, c) {
+			return errors.Errorf("Invalid lint rules: %s. Must be one of [ %s ]", c, strings.Join(AllowedCategories, " | "))
+		}
+	}
+	return nil
+}
+
+// Runs all catalog rules in the given categories against schema.
+func LintRules(ctx context.Context, conn *pgx.Conn, schema, categories []string) ([]Result, error) {
+	exposed := utils.Config.Api.Schemas
+	if len(exposed) == 0 {
+		exposed = []string{"public"}
+	}
+	// Group issues by object name, preserving order
+	var result []Result
+	index := map[string]int{}
+	for _, r := range Rules {
+		if !slices.Contains(categories, r.Category) {
+			continue
+		}
+		args := schema
+		if r.Exposed {
+			args = intersect(schema, exposed)
+		}
+		if len(args) == 0 {
+			continue
+		}
+		sql, err := r.Query()
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintln(os.Stderr, "Checking rule:", r.Name)
+		rows, err := conn.Query(ctx, sql, args)
+		if err != nil {
+			return nil, errors.Errorf("failed to query rows: %w", err)
+		}
+		for rows.Next() {
+			var name, message string
+			if err := rows.Scan(&name, &message); err != nil {
+				return nil, errors.Errorf("failed to scan rows: %w", err)
+			}
+			issue := Issue{
+				Level:   r.Level,
+				Message: message,
+				Hint:    r.Hint,
+				Rule:    r.Name,
+			}
+			if i, ok := index[name]; ok {
+				result[i].Issues = append(result[i].Issues, issue)
+				continue
+			}
+			index[name] = len(result)
+			result = append(result, Result{Function: name, Issues: []Issue{issue}})
+		}
+		if err := rows.Err(); err != nil {
+			return nil, errors.Errorf("failed to parse rows: %w", err)
+		}
+	}
+	return result, nil
+}
+
+func intersect(a, b []string) (result []string) {
+	for _, s := range a {
+		if slices.Contains(b, s) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,110 @@
+package lint
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/pkg/pgtest"
+)
+
+func mustQuery(t *testing.T, name string) string {
+	for _, r := range Rules {
+		if r.Name == name {
+			sql, err := r.Query()
+			require.NoError(t, err)
+			return sql
+		}
+	}
+	require.Fail(t, "unknown rule: "+name)
+	return ""
+}
+
+func TestLintRules(t *testing.T) {
+	utils.Config.Api.Schemas = []string{"public"}
+
+	t.Run("loads all rule templates", func(t *testing.T) {
+		for _, r := range Rules {
+			sql, err := r.Query()
+			assert.NoError(t, err)
+			assert.NotEmpty(t, sql)
+			assert.Contains(t, AllowedCategories, r.Category)
+		}
+	})
+
+	t.Run("groups security issues by object", func(t *testing.T) {
+		schema := []string{"public", "private"}
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query(mustQuery(t, "rls_disabled"), []string{"public"}).
+			Reply("SELECT 1", []interface{}{"public.todos", "rls disabled"}).
+			Query(mustQuery(t, "rls_no_policy"), schema).
+			Reply("SELECT 1", []interface{}{"private.notes", "no policy"}).
+			Query(mustQuery(t, "rls_references_user_metadata"), schema).
+			Reply("SELECT 1", []interface{}{"public.todos", "user metadata"}).
+			Query(mustQuery(t, "function_search_path_mutable"), []string{"public"}).
+			Reply("SELECT 0").
+			Query(mustQuery(t, "security_definer_view"), []string{"public"}).
+			Reply("SELECT 0").
+			Query(mustQuery(t, "extension_in_public"), schema).
+			Reply("SELECT 0")
+		// Run test
+		result, err := LintRules(context.Background(), conn.MockClient(t), schema, []string{CategorySecurity})
+		// Check error
+		assert.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.Equal(t, "public.todos", result[0].Function)
+		require.Len(t, result[0].Issues, 2)
+		assert.Equal(t, "rls_disabled", result[0].Issues[0].Rule)
+		assert.Equal(t, "error", result[0].Issues[0].Level)
+		assert.Equal(t, "rls_references_user_metadata", result[0].Issues[1].Rule)
+		assert.Equal(t, "private.notes", result[1].Function)
+		assert.Equal(t, "rls_no_policy", result[1].Issues[0].Rule)
+	})
+
+	t.Run("skips exposed rules for private schemas", func(t *testing.T) {
+		schema := []string{"private"}
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query(mustQuery(t, "unindexed_foreign_key"), schema).
+			Reply("SELECT 0").
+			Query(mustQuery(t, "no_primary_key"), schema).
+			Reply("SELECT 1", []interface{}{"private.logs", "no primary key"})
+		// Run test
+		result, err := LintRules(context.Background(), conn.MockClient(t), schema, []string{CategoryPerformance})
+		// Check error
+		assert.NoError(t, err)
+		assert.Equal(t, []Result{{
+			Function: "private.logs",
+			Issues: []Issue{{
+				Level:   "warning performance",
+				Message: "no primary key",
+				Hint:    "Add a primary key to the table.",
+				Rule:    "no_primary_key",
+			}},
+		}}, result)
+	})
+
+	t.Run("throws error on query failure", func(t *testing.T) {
+		schema := []string{"private"}
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query(mustQuery(t, "unindexed_foreign_key"), schema).
+			ReplyError(pgerrcode.UndefinedFunction, "function cardinality does not exist")
+		// Run test
+		_, err := LintRules(context.Background(), conn.MockClient(t), schema, []string{CategoryPerformance})
+		// Check error
+		assert.ErrorContains(t, err, "function cardinality does not exist")
+	})
+
+	t.Run("throws error on invalid category", func(t *testing.T) {
+		err := Run(context.Background(), nil, []string{"style"}, "warning", "none", FormatJson, false, dbConfig, nil)
+		assert.ErrorContains(t, err, "Invalid lint rules: style")
+	})
+}
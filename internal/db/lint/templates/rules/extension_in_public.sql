@@ -0,0 +1,6 @@
+-- Extensions installed in public schema are exposed via the Data API
+SELECT n.nspname || '.' || e.extname, format('Extension %I is installed in schema %I', e.extname, n.nspname)
+FROM pg_catalog.pg_extension e
+JOIN pg_catalog.pg_namespace n ON e.extnamespace = n.oid
+WHERE n.nspname = 'public' AND n.nspname = ANY($1::text[])
+ORDER BY 1;
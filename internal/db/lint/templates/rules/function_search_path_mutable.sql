@@ -0,0 +1,7 @@
+-- Security definer functions resolve objects using the caller's search path unless it is fixed
+SELECT n.nspname || '.' || p.proname, format('Function %I.%I(%s) is security definer but does not set search_path', n.nspname, p.proname, pg_catalog.pg_get_function_identity_arguments(p.oid))
+FROM pg_catalog.pg_proc p
+JOIN pg_catalog.pg_namespace n ON p.pronamespace = n.oid
+WHERE p.prosecdef AND n.nspname = ANY($1::text[])
+  AND NOT EXISTS (SELECT 1 FROM unnest(p.proconfig) c WHERE c LIKE 'search_path=%')
+ORDER BY 1;
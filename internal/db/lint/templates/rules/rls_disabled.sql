@@ -0,0 +1,6 @@
+-- Tables in exposed schemas must enable row level security
+SELECT n.nspname || '.' || c.relname, format('Table %I.%I is exposed via the Data API but does not have row level security enabled', n.nspname, c.relname)
+FROM pg_catalog.pg_class c
+JOIN pg_catalog.pg_namespace n ON c.relnamespace = n.oid
+WHERE c.relkind IN ('r', 'p') AND NOT c.relrowsecurity AND n.nspname = ANY($1::text[])
+ORDER BY 1;
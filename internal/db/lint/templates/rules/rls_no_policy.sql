@@ -0,0 +1,7 @@
+-- Tables with row level security enabled but no policy deny all access
+SELECT n.nspname || '.' || c.relname, format('Table %I.%I has row level security enabled but no policies', n.nspname, c.relname)
+FROM pg_catalog.pg_class c
+JOIN pg_catalog.pg_namespace n ON c.relnamespace = n.oid
+WHERE c.relkind IN ('r', 'p') AND c.relrowsecurity AND n.nspname = ANY($1::text[])
+  AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_policy p WHERE p.polrelid = c.oid)
+ORDER BY 1;
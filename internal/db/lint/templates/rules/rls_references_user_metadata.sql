@@ -0,0 +1,8 @@
+-- User metadata is editable by end users and must not be used for authorization
+SELECT n.nspname || '.' || c.relname, format('Policy %I on table %I.%I references user_metadata', p.polname, n.nspname, c.relname)
+FROM pg_catalog.pg_policy p
+JOIN pg_catalog.pg_class c ON p.polrelid = c.oid
+JOIN pg_catalog.pg_namespace n ON c.relnamespace = n.oid
+WHERE n.nspname = ANY($1::text[])
+  AND concat(pg_catalog.pg_get_expr(p.polqual, p.polrelid), pg_catalog.pg_get_expr(p.polwithcheck, p.polrelid)) LIKE '%user_metadata%'
+ORDER BY 1, p.polname;
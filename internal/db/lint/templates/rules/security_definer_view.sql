@@ -0,0 +1,8 @@
+-- Views run with the privileges of their owner unless security_invoker is set
+SELECT n.nspname || '.' || c.relname, format('View %I.%I bypasses row level security of its underlying tables', n.nspname, c.relname)
+FROM pg_catalog.pg_class c
+JOIN pg_catalog.pg_namespace n ON c.relnamespace = n.oid
+WHERE c.relkind = 'v' AND n.nspname = ANY($1::text[])
+  AND NOT coalesce(c.reloptions && ARRAY['security_invoker=true', 'security_invoker=on', 'security_invoker=1'], false)
+  AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend d WHERE d.objid = c.oid AND d.deptype = 'e')
+ORDER BY 1;
@@ -0,0 +1,12 @@
+-- Foreign keys without a covering index slow down joins and cascading deletes
+SELECT n.nspname || '.' || c.relname, format('Foreign key %I on table %I.%I does not have a covering index', con.conname, n.nspname, c.relname)
+FROM pg_catalog.pg_constraint con
+JOIN pg_catalog.pg_class c ON con.conrelid = c.oid
+JOIN pg_catalog.pg_namespace n ON c.relnamespace = n.oid
+WHERE con.contype = 'f' AND n.nspname = ANY($1::text[])
+  AND NOT EXISTS (
+    SELECT 1 FROM pg_catalog.pg_index i
+    WHERE i.indrelid = con.conrelid
+      AND (i.indkey::int2[])[0:cardinality(con.conkey) - 1] @> con.conkey
+  )
+ORDER BY 1, con.conname;
@@ -0,0 +1,5 @@
+-- Lists function bodies that may contain inline lint suppressions
+SELECT n.nspname || '.' || p.proname, p.prosrc
+FROM pg_catalog.pg_namespace n
+JOIN pg_catalog.pg_proc p ON pronamespace = n.oid
+WHERE n.nspname || '.' || p.proname = ANY($1::text[]) AND p.prosrc LIKE '%supabase-lint-ignore%';
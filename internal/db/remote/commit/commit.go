@@ -4,6 +4,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/go-errors/errors"
@@ -69,7 +70,13 @@ func fetchRemote(p utils.Program, ctx context.Context, schema []string, timestam
 		return err
 	} else if len(migrations) == 0 {
 		p.Send(utils.StatusMsg("Committing initial migration on remote database..."))
-		return dump.Run(ctx, path, config, nil, nil, false, false, false, false, false, fsys)
+		// Migrations are always saved as plain text
+		f, err := fsys.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return errors.Errorf("failed to open migration file: %w", err)
+		}
+		defer f.Close()
+		return dump.DumpSchema(ctx, config, nil, false, false, f)
 	}
 
 	w := utils.StatusWriter{Program: p}
@@ -19,6 +19,7 @@ import (
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v4"
 	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/db/snapshot"
 	"github.com/supabase/cli/internal/db/start"
 	"github.com/supabase/cli/internal/gen/keys"
 	"github.com/supabase/cli/internal/migration/apply"
@@ -50,6 +51,9 @@ func Run(ctx context.Context, version string, config pgconn.Config, fsys afero.F
 	if err := utils.AssertSupabaseDbIsRunning(); err != nil {
 		return err
 	}
+	if err := promptSnapshot(ctx); err != nil {
+		return err
+	}
 	// Reset postgres database because extensions (pg_cron, pg_net) require postgres
 	if err := resetDatabase(ctx, version, fsys, options...); err != nil {
 		return err
@@ -70,6 +74,20 @@ func Run(ctx context.Context, version string, config pgconn.Config, fsys afero.F
 	return nil
 }
 
+// Offers to save the local database before it is dropped, so the reset can be undone.
+func promptSnapshot(ctx context.Context) error {
+	msg := "Do you want to save a snapshot of the local database first?"
+	if shouldSave, err := utils.NewConsole().PromptYesNo(ctx, msg, false); err != nil || !shouldSave {
+		return err
+	}
+	name := "reset_" + time.Now().UTC().Format("20060102150405")
+	if err := snapshot.CreateSnapshot(ctx, name, os.Stderr); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Saved snapshot "+utils.Aqua(name)+". Run "+utils.Aqua("supabase db snapshot restore "+name)+" to undo the reset.")
+	return nil
+}
+
 func resetDatabase(ctx context.Context, version string, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
 	fmt.Fprintln(os.Stderr, "Resetting local database"+toLogMessage(version))
 	if utils.Config.Db.MajorVersion <= 14 {
@@ -0,0 +1,111 @@
+package restore
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/pkg/migration"
+	"github.com/supabase/cli/pkg/parser"
+)
+
+const DISABLE_TRIGGERS = "SET LOCAL session_replication_role = replica"
+
+var (
+	//go:embed templates/reset_sequences.sql
+	resetSequencesScript string
+)
+
+func Run(ctx context.Context, schemaPath, dataPath, rolesPath string, disableTriggers bool, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
+	if len(schemaPath) == 0 && len(dataPath) == 0 && len(rolesPath) == 0 {
+		return errors.New("Missing flag: specify at least one of --schema, --data or --roles.")
+	}
+	conn, err := utils.ConnectByConfig(ctx, config, options...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+	// Roles must be created before schema that references them
+	if len(rolesPath) > 0 {
+		fmt.Fprintln(os.Stderr, "Restoring roles from "+utils.Bold(rolesPath)+"...")
+		if _, err := RestoreFile(ctx, rolesPath, conn, fsys); err != nil {
+			return err
+		}
+	}
+	if len(schemaPath) > 0 {
+		fmt.Fprintln(os.Stderr, "Restoring schema from "+utils.Bold(schemaPath)+"...")
+		if _, err := RestoreFile(ctx, schemaPath, conn, fsys); err != nil {
+			return err
+		}
+	}
+	if len(dataPath) > 0 {
+		fmt.Fprintln(os.Stderr, "Restoring data from "+utils.Bold(dataPath)+"...")
+		var setup []string
+		if disableTriggers {
+			setup = append(setup, DISABLE_TRIGGERS)
+		}
+		if _, err := RestoreFile(ctx, dataPath, conn, fsys, setup...); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "Resetting sequences...")
+		if _, err := conn.Exec(ctx, resetSequencesScript); err != nil {
+			return errors.Errorf("failed to reset sequences: %w", err)
+		}
+	}
+	fmt.Fprintln(os.Stderr, "Finished "+utils.Aqua("supabase db restore")+".")
+	return nil
+}
+
+// Executes all statements in a dump file within a single transaction. COPY FROM stdin
+// statements are streamed to the server using the copy protocol. Returns the number of
+// copied rows.
+func RestoreFile(ctx context.Context, path string, conn *pgx.Conn, fsys afero.Fs, setup ...string) (int64, error) {
+	// Compressed or encrypted dumps are decoded transparently
+	file, err := migration.NewMigrationFromFile(path, afero.NewIOFS(fsys))
+	if err != nil {
+		return 0, err
+	}
+	lines := file.Statements
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return 0, errors.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(context.Background()); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+	for _, line := range setup {
+		if _, err := tx.Exec(ctx, line); err != nil {
+			return 0, errors.Errorf("failed to setup transaction: %w", err)
+		}
+	}
+	var rows int64
+	for i, line := range lines {
+		if stat, data, found := parser.SplitCopy(line); found {
+			tag, err := conn.PgConn().CopyFrom(ctx, strings.NewReader(data), stat)
+			if err != nil {
+				return 0, errors.Errorf("%w\nAt statement %d: %s", err, i, stat)
+			}
+			rows += tag.RowsAffected()
+		} else if _, err := tx.Exec(ctx, line); err != nil {
+			return 0, errors.Errorf("%w\nAt statement %d: %s", err, i, line)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, errors.Errorf("failed to commit transaction: %w", err)
+	}
+	if rows > 0 {
+		fmt.Fprintf(os.Stderr, "Restored %d statements and %d copied rows.\n", len(lines), rows)
+	} else {
+		fmt.Fprintf(os.Stderr, "Restored %d statements.\n", len(lines))
+	}
+	return rows, nil
+}
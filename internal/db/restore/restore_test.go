@@ -0,0 +1,117 @@
+package restore
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/pkg/pgtest"
+)
+
+var dbConfig = pgconn.Config{
+	Host:     "127.0.0.1",
+	Port:     5432,
+	User:     "admin",
+	Password: "password",
+	Database: "postgres",
+}
+
+func TestRestoreCommand(t *testing.T) {
+	t.Run("restores roles, schema and data", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, "roles.sql", []byte("create role test;"), 0644))
+		require.NoError(t, afero.WriteFile(fsys, "schema.sql", []byte("create table test (id serial primary key);"), 0644))
+		require.NoError(t, afero.WriteFile(fsys, "data.sql", []byte("insert into test values (1);"), 0644))
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query("begin").Reply("BEGIN").
+			Query("create role test").
+			Reply("CREATE ROLE").
+			Query("commit").Reply("COMMIT").
+			Query("begin").Reply("BEGIN").
+			Query("create table test (id serial primary key)").
+			Reply("CREATE TABLE").
+			Query("commit").Reply("COMMIT").
+			Query("begin").Reply("BEGIN").
+			Query(DISABLE_TRIGGERS).
+			Reply("SET").
+			Query("insert into test values (1)").
+			Reply("INSERT 0 1").
+			Query("commit").Reply("COMMIT").
+			Query(resetSequencesScript).
+			Reply("DO")
+		// Run test
+		err := Run(context.Background(), "schema.sql", "data.sql", "roles.sql", true, dbConfig, fsys, conn.Intercept)
+		// Check error
+		assert.NoError(t, err)
+	})
+
+	t.Run("throws error on missing flags", func(t *testing.T) {
+		err := Run(context.Background(), "", "", "", false, dbConfig, afero.NewMemMapFs())
+		assert.ErrorContains(t, err, "Missing flag")
+	})
+
+	t.Run("throws error on missing file", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		// Run test
+		err := Run(context.Background(), "schema.sql", "", "", false, dbConfig, fsys, conn.Intercept)
+		// Check error
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("rolls back on statement error", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, "schema.sql", []byte("create schema test;\ncreate table test.t();"), 0644))
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query("begin").Reply("BEGIN").
+			Query("create schema test").
+			Reply("CREATE SCHEMA").
+			Query("create table test.t()").
+			ReplyError(pgerrcode.DuplicateTable, `relation "t" already exists`).
+			Query("rollback").Reply("ROLLBACK")
+		// Run test
+		err := Run(context.Background(), "schema.sql", "", "", false, dbConfig, fsys, conn.Intercept)
+		// Check error
+		assert.ErrorContains(t, err, `ERROR: relation "t" already exists (SQLSTATE 42P07)`)
+		assert.ErrorContains(t, err, "At statement 1: create table test.t()")
+	})
+}
+
+func TestRestoreFile(t *testing.T) {
+	t.Run("copies data from stdin", func(t *testing.T) {
+		rows := "1\tvalue\n2\tother\n"
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, "data.sql", []byte("SET search_path = public;\nCOPY public.test (id, name) FROM stdin;\n"+rows+"\\.\n"), 0644))
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query("begin").Reply("BEGIN").
+			Query(DISABLE_TRIGGERS).
+			Reply("SET").
+			Query("SET search_path = public").
+			Reply("SET").
+			Query("COPY public.test (id, name) FROM stdin").
+			ReplyCopy("COPY 2", rows).
+			Query("commit").Reply("COMMIT")
+		// Run test
+		copied, err := RestoreFile(context.Background(), "data.sql", conn.MockClient(t), fsys, DISABLE_TRIGGERS)
+		// Check error
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), copied)
+	})
+}
@@ -0,0 +1,27 @@
+DO $$
+DECLARE
+  r record;
+  v bigint;
+  next bigint;
+BEGIN
+  FOR r IN
+    SELECT n.nspname, c.relname, a.attname, pg_get_serial_sequence(format('%I.%I', n.nspname, c.relname), a.attname) AS seq
+    FROM pg_attribute a
+    JOIN pg_class c ON c.oid = a.attrelid
+    JOIN pg_namespace n ON n.oid = c.relnamespace
+    WHERE c.relkind IN ('r', 'p')
+      AND a.attnum > 0
+      AND NOT a.attisdropped
+      AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+      AND n.nspname NOT LIKE 'pg\_%'
+  LOOP
+    CONTINUE WHEN r.seq IS NULL;
+    EXECUTE format('SELECT max(%I) FROM %I.%I', r.attname, r.nspname, r.relname) INTO v;
+    EXECUTE format('SELECT CASE WHEN is_called THEN last_value + 1 ELSE last_value END FROM %s', r.seq) INTO next;
+    -- Only move sequences forward so that tables untouched by the dump keep their values
+    IF v IS NOT NULL AND v >= next THEN
+      PERFORM setval(r.seq, v);
+    END IF;
+  END LOOP;
+END
+$$
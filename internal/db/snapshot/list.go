@@ -0,0 +1,80 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/migration/list"
+	"github.com/supabase/cli/internal/utils"
+)
+
+type Snapshot struct {
+	Name            string `json:"name" toml:"name"`
+	PostgresVersion string `json:"postgres_version" toml:"postgres_version"`
+	Storage         bool   `json:"storage" toml:"storage"`
+	CreatedAt       string `json:"created_at" toml:"created_at"`
+}
+
+func List(ctx context.Context, fsys afero.Fs) error {
+	if err := utils.LoadConfigFS(fsys); err != nil {
+		return err
+	}
+	snapshots, err := ListSnapshots(ctx)
+	if err != nil {
+		return err
+	}
+	if utils.OutputFormat.Value == utils.OutputPretty {
+		table := `|NAME|POSTGRES|STORAGE|CREATED AT (UTC)|
+|-|-|-|-|
+`
+		for _, s := range snapshots {
+			table += fmt.Sprintf("|`%s`|`%s`|`%t`|`%s`|\n", s.Name, s.PostgresVersion, s.Storage, utils.FormatTimestamp(s.CreatedAt))
+		}
+		return list.RenderTable(table)
+	} else if utils.OutputFormat.Value == utils.OutputToml {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, struct {
+			Snapshots []Snapshot `toml:"snapshots"`
+		}{
+			Snapshots: snapshots,
+		})
+	}
+	return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, snapshots)
+}
+
+// Lists snapshots of the current project, sorted by creation time.
+func ListSnapshots(ctx context.Context) ([]Snapshot, error) {
+	volumes, err := listVolumes(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]*Snapshot{}
+	for _, v := range volumes {
+		name := v.Labels[snapshotLabel]
+		s, ok := byName[name]
+		if !ok {
+			s = &Snapshot{Name: name}
+			byName[name] = s
+		}
+		switch v.Labels[snapshotVolumeLabel] {
+		case volumeDb:
+			s.PostgresVersion = v.Labels[snapshotVersionLabel]
+			s.CreatedAt = v.Labels[snapshotCreatedLabel]
+		case volumeStorage:
+			s.Storage = true
+		}
+	}
+	snapshots := make([]Snapshot, 0, len(byName))
+	for _, s := range byName {
+		snapshots = append(snapshots, *s)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		if snapshots[i].CreatedAt == snapshots[j].CreatedAt {
+			return snapshots[i].Name < snapshots[j].Name
+		}
+		return snapshots[i].CreatedAt < snapshots[j].CreatedAt
+	})
+	return snapshots, nil
+}
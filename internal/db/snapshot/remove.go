@@ -0,0 +1,40 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/go-errors/errors"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/utils"
+)
+
+func Remove(ctx context.Context, name string, fsys afero.Fs) error {
+	if err := utils.LoadConfigFS(fsys); err != nil {
+		return err
+	}
+	if volumes, err := listVolumes(ctx, name); err != nil {
+		return err
+	} else if len(volumes) == 0 {
+		return errors.Errorf("snapshot not found: %s", name)
+	}
+	if err := removeVolumes(ctx, name); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Removed snapshot "+utils.Aqua(name)+".")
+	return nil
+}
+
+func removeVolumes(ctx context.Context, name string) error {
+	volumes, err := listVolumes(ctx, name)
+	if err != nil {
+		return err
+	}
+	for _, v := range volumes {
+		if err := utils.Docker.VolumeRemove(ctx, v.Name, false); err != nil {
+			return errors.Errorf("failed to remove volume: %w", err)
+		}
+	}
+	return nil
+}
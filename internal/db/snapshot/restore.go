@@ -0,0 +1,64 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/go-errors/errors"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/utils"
+)
+
+func Restore(ctx context.Context, name string, fsys afero.Fs) error {
+	if err := utils.LoadConfigFS(fsys); err != nil {
+		return err
+	}
+	volumes, err := listVolumes(ctx, name)
+	if err != nil {
+		return err
+	} else if len(volumes) == 0 {
+		utils.CmdSuggestion = fmt.Sprintf("Run %s to show available snapshots.", utils.Aqua("supabase db snapshot list"))
+		return errors.Errorf("snapshot not found: %s", name)
+	}
+	sources := map[string]string{}
+	for _, v := range volumes {
+		kind := v.Labels[snapshotVolumeLabel]
+		sources[kind] = v.Name
+		if kind != volumeDb {
+			continue
+		}
+		// Data directory is incompatible across major versions
+		if version := strconv.FormatUint(uint64(utils.Config.Db.MajorVersion), 10); v.Labels[snapshotVersionLabel] != version {
+			return errors.Errorf("snapshot %s was saved with Postgres %s, but db.major_version is %s", name, v.Labels[snapshotVersionLabel], version)
+		}
+	}
+	if _, ok := sources[volumeDb]; !ok {
+		return errors.Errorf("snapshot is missing database volume: %s", name)
+	}
+	// Docker would otherwise create the volume without the project label used for cleanup
+	if exists, err := volumeExists(ctx, utils.DbId); err != nil {
+		return err
+	} else if !exists {
+		utils.CmdSuggestion = fmt.Sprintf("Run %s to create the local database first.", utils.Aqua("supabase start"))
+		return errors.Errorf("database volume not found: %s", utils.DbId)
+	}
+	fmt.Fprintln(os.Stderr, "Restoring snapshot "+utils.Aqua(name)+"...")
+	if err := pauseServices(ctx, os.Stderr, func() error {
+		if err := copyVolume(ctx, sources[volumeDb], utils.DbId); err != nil {
+			return err
+		}
+		// Storage objects must match the restored database, so empty the volume if none was saved
+		if src, ok := sources[volumeStorage]; ok {
+			return copyVolume(ctx, src, utils.StorageId)
+		} else if exists, err := volumeExists(ctx, utils.StorageId); err != nil || !exists {
+			return err
+		}
+		return copyVolume(ctx, "", utils.StorageId)
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Restored snapshot "+utils.Aqua(name)+".")
+	return nil
+}
@@ -0,0 +1,85 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/docker/docker/api/types/volume"
+	"github.com/go-errors/errors"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/utils"
+)
+
+func Save(ctx context.Context, name string, fsys afero.Fs) error {
+	if err := utils.LoadConfigFS(fsys); err != nil {
+		return err
+	}
+	if err := CreateSnapshot(ctx, name, os.Stderr); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Saved snapshot "+utils.Aqua(name)+".")
+	return nil
+}
+
+// Copies the local database and storage volumes to a named snapshot.
+func CreateSnapshot(ctx context.Context, name string, w io.Writer) error {
+	if err := assertValidName(name); err != nil {
+		return err
+	}
+	if volumes, err := listVolumes(ctx, name); err != nil {
+		return err
+	} else if len(volumes) > 0 {
+		utils.CmdSuggestion = fmt.Sprintf("Run %s to remove it first.", utils.Aqua("supabase db snapshot rm "+name))
+		return errors.Errorf("snapshot already exists: %s", name)
+	}
+	sources := map[string]string{}
+	if exists, err := volumeExists(ctx, utils.DbId); err != nil {
+		return err
+	} else if !exists {
+		utils.CmdSuggestion = fmt.Sprintf("Have you started the local database with %s?", utils.Aqua("supabase start"))
+		return errors.Errorf("local database volume not found: %s", utils.DbId)
+	}
+	sources[volumeDb] = utils.DbId
+	if exists, err := volumeExists(ctx, utils.StorageId); err != nil {
+		return err
+	} else if exists {
+		sources[volumeStorage] = utils.StorageId
+	}
+	labels := map[string]string{
+		snapshotProjectLabel: utils.Config.ProjectId,
+		snapshotLabel:        name,
+		snapshotVersionLabel: strconv.FormatUint(uint64(utils.Config.Db.MajorVersion), 10),
+		snapshotCreatedLabel: time.Now().UTC().Format(time.RFC3339),
+	}
+	fmt.Fprintln(w, "Saving snapshot "+utils.Aqua(name)+"...")
+	return pauseServices(ctx, w, func() error {
+		for _, kind := range []string{volumeDb, volumeStorage} {
+			src, ok := sources[kind]
+			if !ok {
+				continue
+			}
+			if err := copySnapshotVolume(ctx, src, getVolumeName(name, kind), kind, labels); err != nil {
+				// Cleanup partial snapshot so that it can be saved again
+				return errors.Join(err, removeVolumes(ctx, name))
+			}
+		}
+		return nil
+	})
+}
+
+func copySnapshotVolume(ctx context.Context, src, dst, kind string, labels map[string]string) error {
+	labels = maps.Clone(labels)
+	labels[snapshotVolumeLabel] = kind
+	if _, err := utils.Docker.VolumeCreate(ctx, volume.CreateOptions{
+		Name:   dst,
+		Labels: labels,
+	}); err != nil {
+		return errors.Errorf("failed to create volume: %w", err)
+	}
+	return copyVolume(ctx, src, dst)
+}
@@ -0,0 +1,147 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/errdefs"
+	"github.com/go-errors/errors"
+	"github.com/supabase/cli/internal/db/start"
+	"github.com/supabase/cli/internal/utils"
+)
+
+const (
+	snapshotLabel        = "com.supabase.cli.snapshot"
+	snapshotVolumeLabel  = "com.supabase.cli.snapshot.volume"
+	snapshotVersionLabel = "com.supabase.cli.snapshot.postgres"
+	snapshotCreatedLabel = "com.supabase.cli.snapshot.created"
+	// Not using the cli project label keeps snapshots when pruning volumes on stop
+	snapshotProjectLabel = "com.supabase.cli.snapshot.project"
+
+	volumeDb      = "db"
+	volumeStorage = "storage"
+)
+
+var namePattern = regexp.MustCompile(`^[0-9A-Za-z_-]+$`)
+
+func assertValidName(name string) error {
+	if !namePattern.MatchString(name) {
+		return errors.New("Snapshot name " + utils.Aqua(name) + " is invalid. Must match [0-9A-Za-z_-]+.")
+	}
+	return nil
+}
+
+func getVolumeName(name, kind string) string {
+	return utils.GetId("snapshot_" + kind + "_" + name)
+}
+
+// Lists the volumes of a named snapshot, or of all snapshots if name is empty.
+func listVolumes(ctx context.Context, name string) ([]*volume.Volume, error) {
+	args := filters.NewArgs(filters.Arg("label", snapshotProjectLabel+"="+utils.Config.ProjectId))
+	if len(name) > 0 {
+		args.Add("label", snapshotLabel+"="+name)
+	} else {
+		args.Add("label", snapshotLabel)
+	}
+	resp, err := utils.Docker.VolumeList(ctx, volume.ListOptions{Filters: args})
+	if err != nil {
+		return nil, errors.Errorf("failed to list volumes: %w", err)
+	}
+	return resp.Volumes, nil
+}
+
+func volumeExists(ctx context.Context, name string) (bool, error) {
+	if _, err := utils.Docker.VolumeInspect(ctx, name); errdefs.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Errorf("failed to inspect volume: %w", err)
+	}
+	return true, nil
+}
+
+// Stops the database and storage containers while running fn, so that their volumes
+// are copied in a consistent state.
+func pauseServices(ctx context.Context, w io.Writer, fn func() error) error {
+	var stopped []string
+	for _, id := range []string{utils.DbId, utils.StorageId} {
+		resp, err := utils.Docker.ContainerInspect(ctx, id)
+		if errdefs.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return errors.Errorf("failed to inspect container: %w", err)
+		}
+		if resp.ContainerJSONBase == nil || resp.State == nil || !resp.State.Running {
+			continue
+		}
+		if err := utils.Docker.ContainerStop(ctx, id, container.StopOptions{}); err != nil {
+			return errors.Errorf("failed to stop container: %w", err)
+		}
+		stopped = append(stopped, id)
+	}
+	err := fn()
+	if len(stopped) == 0 {
+		return err
+	}
+	return errors.Join(err, resumeServices(ctx, w, stopped))
+}
+
+func resumeServices(ctx context.Context, w io.Writer, stopped []string) error {
+	fmt.Fprintln(w, "Restarting containers...")
+	for _, id := range stopped {
+		if err := utils.Docker.ContainerStart(ctx, id, container.StartOptions{}); err != nil {
+			return errors.Errorf("failed to start container: %w", err)
+		}
+		if id != utils.DbId {
+			continue
+		}
+		if err := start.WaitForHealthyService(ctx, start.HealthTimeout, id); err != nil {
+			return err
+		}
+	}
+	if !slices.Contains(stopped, utils.DbId) {
+		return nil
+	}
+	// Services holding database connections must be restarted after the database
+	services := []string{utils.GotrueId, utils.RealtimeId, utils.PoolerId}
+	result := utils.WaitAll(services, func(id string) error {
+		if err := utils.Docker.ContainerRestart(ctx, id, container.StopOptions{}); err != nil && !errdefs.IsNotFound(err) {
+			return errors.Errorf("failed to restart %s: %w", id, err)
+		}
+		return nil
+	})
+	return errors.Join(result...)
+}
+
+// Replaces the contents of dst volume with those of src volume, or empties dst if src is unset.
+func copyVolume(ctx context.Context, src, dst string) error {
+	script := "find /target -mindepth 1 -delete"
+	binds := []string{dst + ":/target"}
+	if len(src) > 0 {
+		script += " && cp -a /source/. /target/"
+		binds = append(binds, src+":/source:ro")
+	}
+	return utils.DockerRunOnceWithConfig(
+		ctx,
+		container.Config{
+			Image:      utils.Config.Db.Image,
+			User:       "root",
+			Entrypoint: []string{"sh", "-c", script},
+		},
+		container.HostConfig{
+			Binds:       binds,
+			NetworkMode: network.NetworkNone,
+		},
+		network.NetworkingConfig{},
+		"",
+		io.Discard,
+		os.Stderr,
+	)
+}
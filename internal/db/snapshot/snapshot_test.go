@@ -0,0 +1,359 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/h2non/gock"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/internal/testing/apitest"
+	"github.com/supabase/cli/internal/utils"
+)
+
+func newSnapshotVolume(name, kind string) *volume.Volume {
+	return &volume.Volume{
+		Name: getVolumeName(name, kind),
+		Labels: map[string]string{
+			snapshotLabel:        name,
+			snapshotVolumeLabel:  kind,
+			snapshotVersionLabel: "15",
+			snapshotCreatedLabel: "2024-01-01T12:00:00Z",
+		},
+	}
+}
+
+func mockVolumeList(volumes ...*volume.Volume) {
+	gock.New(utils.Docker.DaemonHost()).
+		Get("/v" + utils.Docker.ClientVersion() + "/volumes").
+		Reply(http.StatusOK).
+		JSON(volume.ListResponse{Volumes: volumes})
+}
+
+func mockVolumeInspect(name string, status int) {
+	gock.New(utils.Docker.DaemonHost()).
+		Get("/v" + utils.Docker.ClientVersion() + "/volumes/" + name).
+		Reply(status).
+		JSON(volume.Volume{Name: name})
+}
+
+func mockContainerInspect(id string, running bool) {
+	if !running {
+		gock.New(utils.Docker.DaemonHost()).
+			Get("/v" + utils.Docker.ClientVersion() + "/containers/" + id + "/json").
+			Reply(http.StatusNotFound)
+		return
+	}
+	gock.New(utils.Docker.DaemonHost()).
+		Get("/v" + utils.Docker.ClientVersion() + "/containers/" + id + "/json").
+		Reply(http.StatusOK).
+		JSON(types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{
+			State: &types.ContainerState{
+				Running: true,
+				Health:  &types.Health{Status: types.Healthy},
+			},
+		}})
+}
+
+func mockCopyVolume(t *testing.T, id string) {
+	apitest.MockDockerStart(utils.Docker, utils.GetRegistryImageUrl(utils.Config.Db.Image), id)
+	require.NoError(t, apitest.MockDockerLogs(utils.Docker, id, ""))
+}
+
+func TestSaveSnapshot(t *testing.T) {
+	t.Run("saves database and storage volumes", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, utils.WriteConfig(fsys, false))
+		require.NoError(t, utils.LoadConfigFS(fsys))
+		// Setup mock docker
+		require.NoError(t, apitest.MockDocker(utils.Docker))
+		defer gock.OffAll()
+		mockVolumeList()
+		mockVolumeInspect(utils.DbId, http.StatusOK)
+		mockVolumeInspect(utils.StorageId, http.StatusOK)
+		mockContainerInspect(utils.DbId, false)
+		mockContainerInspect(utils.StorageId, false)
+		mockCopyVolume(t, "test-copy-db")
+		mockCopyVolume(t, "test-copy-storage")
+		// Run test
+		err := Save(context.Background(), "before-rls", fsys)
+		// Check error
+		assert.NoError(t, err)
+		assert.Empty(t, apitest.ListUnmatchedRequests())
+	})
+
+	t.Run("throws error on invalid name", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, utils.WriteConfig(fsys, false))
+		// Run test
+		err := Save(context.Background(), "../db", fsys)
+		// Check error
+		assert.ErrorContains(t, err, "is invalid. Must match [0-9A-Za-z_-]+.")
+	})
+
+	t.Run("throws error on existing snapshot", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, utils.WriteConfig(fsys, false))
+		// Setup mock docker
+		require.NoError(t, apitest.MockDocker(utils.Docker))
+		defer gock.OffAll()
+		mockVolumeList(newSnapshotVolume("before-rls", volumeDb))
+		// Run test
+		err := Save(context.Background(), "before-rls", fsys)
+		// Check error
+		assert.ErrorContains(t, err, "snapshot already exists: before-rls")
+		assert.Empty(t, apitest.ListUnmatchedRequests())
+	})
+
+	t.Run("throws error on missing database", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, utils.WriteConfig(fsys, false))
+		require.NoError(t, utils.LoadConfigFS(fsys))
+		// Setup mock docker
+		require.NoError(t, apitest.MockDocker(utils.Docker))
+		defer gock.OffAll()
+		mockVolumeList()
+		mockVolumeInspect(utils.DbId, http.StatusNotFound)
+		// Run test
+		err := Save(context.Background(), "before-rls", fsys)
+		// Check error
+		assert.ErrorContains(t, err, "local database volume not found:")
+		assert.Empty(t, apitest.ListUnmatchedRequests())
+	})
+}
+
+func TestStopNoBackup(t *testing.T) {
+	t.Run("keeps snapshot volumes", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, utils.WriteConfig(fsys, false))
+		require.NoError(t, utils.LoadConfigFS(fsys))
+		// Setup mock docker
+		require.NoError(t, apitest.MockDocker(utils.Docker))
+		defer gock.OffAll()
+		var labels []map[string]string
+		gock.New(utils.Docker.DaemonHost()).
+			Post("/v" + utils.Docker.ClientVersion() + "/volumes/create").
+			AddMatcher(func(req *http.Request, _ *gock.Request) (bool, error) {
+				var body volume.CreateOptions
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					return false, err
+				}
+				labels = append(labels, body.Labels)
+				return true, nil
+			}).
+			Reply(http.StatusCreated).
+			JSON(volume.Volume{})
+		mockVolumeList()
+		mockVolumeInspect(utils.DbId, http.StatusOK)
+		mockVolumeInspect(utils.StorageId, http.StatusNotFound)
+		mockContainerInspect(utils.DbId, false)
+		mockContainerInspect(utils.StorageId, false)
+		mockCopyVolume(t, "test-copy-db")
+		require.NoError(t, Save(context.Background(), "before-rls", fsys))
+		require.Len(t, labels, 1)
+		// Stop with --no-backup
+		utils.NoBackupVolume = true
+		defer func() { utils.NoBackupVolume = false }()
+		var pruned filters.Args
+		gock.New(utils.Docker.DaemonHost()).
+			Get("/v" + utils.Docker.ClientVersion() + "/containers/json").
+			Reply(http.StatusOK).
+			JSON([]types.Container{})
+		gock.New(utils.Docker.DaemonHost()).
+			Post("/v" + utils.Docker.ClientVersion() + "/containers/prune").
+			Reply(http.StatusOK).
+			JSON(container.PruneReport{})
+		gock.New(utils.Docker.DaemonHost()).
+			Post("/v" + utils.Docker.ClientVersion() + "/volumes/prune").
+			AddMatcher(func(req *http.Request, _ *gock.Request) (bool, error) {
+				var err error
+				pruned, err = filters.FromJSON(req.URL.Query().Get("filters"))
+				return err == nil, err
+			}).
+			Reply(http.StatusOK).
+			JSON(volume.PruneReport{})
+		gock.New(utils.Docker.DaemonHost()).
+			Post("/v" + utils.Docker.ClientVersion() + "/networks/prune").
+			Reply(http.StatusOK).
+			JSON(network.PruneReport{})
+		// Run test
+		err := utils.DockerRemoveAll(context.Background(), io.Discard, utils.Config.ProjectId)
+		// Check error
+		assert.NoError(t, err)
+		assert.Empty(t, apitest.ListUnmatchedRequests())
+		assert.True(t, pruned.Contains("label"))
+		assert.False(t, pruned.MatchKVList("label", labels[0]))
+	})
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	t.Run("restores running database", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, utils.WriteConfig(fsys, false))
+		require.NoError(t, utils.LoadConfigFS(fsys))
+		// Setup mock docker
+		require.NoError(t, apitest.MockDocker(utils.Docker))
+		defer gock.OffAll()
+		mockVolumeList(newSnapshotVolume("before-rls", volumeDb))
+		mockVolumeInspect(utils.DbId, http.StatusOK)
+		mockContainerInspect(utils.DbId, true)
+		gock.New(utils.Docker.DaemonHost()).
+			Post("/v" + utils.Docker.ClientVersion() + "/containers/" + utils.DbId + "/stop").
+			Reply(http.StatusOK)
+		mockContainerInspect(utils.StorageId, false)
+		mockCopyVolume(t, "test-copy-db")
+		mockVolumeInspect(utils.StorageId, http.StatusOK)
+		mockCopyVolume(t, "test-clear-storage")
+		// Restarts services
+		gock.New(utils.Docker.DaemonHost()).
+			Post("/v" + utils.Docker.ClientVersion() + "/containers/" + utils.DbId + "/start").
+			Reply(http.StatusAccepted)
+		mockContainerInspect(utils.DbId, true)
+		for _, id := range []string{utils.GotrueId, utils.RealtimeId, utils.PoolerId} {
+			gock.New(utils.Docker.DaemonHost()).
+				Post("/v" + utils.Docker.ClientVersion() + "/containers/" + id + "/restart").
+				Reply(http.StatusOK)
+		}
+		// Run test
+		err := Restore(context.Background(), "before-rls", fsys)
+		// Check error
+		assert.NoError(t, err)
+		assert.Empty(t, apitest.ListUnmatchedRequests())
+	})
+
+	t.Run("throws error on missing snapshot", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, utils.WriteConfig(fsys, false))
+		// Setup mock docker
+		require.NoError(t, apitest.MockDocker(utils.Docker))
+		defer gock.OffAll()
+		mockVolumeList()
+		// Run test
+		err := Restore(context.Background(), "before-rls", fsys)
+		// Check error
+		assert.ErrorContains(t, err, "snapshot not found: before-rls")
+		assert.Empty(t, apitest.ListUnmatchedRequests())
+	})
+
+	t.Run("throws error on missing database volume", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, utils.WriteConfig(fsys, false))
+		// Setup mock docker
+		require.NoError(t, apitest.MockDocker(utils.Docker))
+		defer gock.OffAll()
+		mockVolumeList(newSnapshotVolume("before-rls", volumeDb))
+		mockVolumeInspect(utils.DbId, http.StatusNotFound)
+		// Run test
+		err := Restore(context.Background(), "before-rls", fsys)
+		// Check error
+		assert.ErrorContains(t, err, "database volume not found: "+utils.DbId)
+		assert.Empty(t, apitest.ListUnmatchedRequests())
+	})
+
+	t.Run("throws error on major version mismatch", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, utils.WriteConfig(fsys, false))
+		// Setup mock docker
+		require.NoError(t, apitest.MockDocker(utils.Docker))
+		defer gock.OffAll()
+		snapshot := newSnapshotVolume("before-rls", volumeDb)
+		snapshot.Labels[snapshotVersionLabel] = "14"
+		mockVolumeList(snapshot)
+		// Run test
+		err := Restore(context.Background(), "before-rls", fsys)
+		// Check error
+		assert.ErrorContains(t, err, "snapshot before-rls was saved with Postgres 14, but db.major_version is 15")
+		assert.Empty(t, apitest.ListUnmatchedRequests())
+	})
+}
+
+func TestListSnapshots(t *testing.T) {
+	t.Run("groups volumes by snapshot", func(t *testing.T) {
+		// Setup mock docker
+		require.NoError(t, apitest.MockDocker(utils.Docker))
+		defer gock.OffAll()
+		older := newSnapshotVolume("initial", volumeDb)
+		older.Labels[snapshotCreatedLabel] = "2023-01-01T12:00:00Z"
+		mockVolumeList(
+			newSnapshotVolume("before-rls", volumeStorage),
+			newSnapshotVolume("before-rls", volumeDb),
+			older,
+		)
+		// Run test
+		snapshots, err := ListSnapshots(context.Background())
+		// Check error
+		assert.NoError(t, err)
+		assert.Equal(t, []Snapshot{{
+			Name:            "initial",
+			PostgresVersion: "15",
+			CreatedAt:       "2023-01-01T12:00:00Z",
+		}, {
+			Name:            "before-rls",
+			PostgresVersion: "15",
+			Storage:         true,
+			CreatedAt:       "2024-01-01T12:00:00Z",
+		}}, snapshots)
+		assert.Empty(t, apitest.ListUnmatchedRequests())
+	})
+}
+
+func TestRemoveSnapshot(t *testing.T) {
+	t.Run("removes snapshot volumes", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, utils.WriteConfig(fsys, false))
+		require.NoError(t, utils.LoadConfigFS(fsys))
+		// Setup mock docker
+		require.NoError(t, apitest.MockDocker(utils.Docker))
+		defer gock.OffAll()
+		volumes := []*volume.Volume{
+			newSnapshotVolume("before-rls", volumeDb),
+			newSnapshotVolume("before-rls", volumeStorage),
+		}
+		mockVolumeList(volumes...)
+		mockVolumeList(volumes...)
+		for _, v := range volumes {
+			gock.New(utils.Docker.DaemonHost()).
+				Delete("/v" + utils.Docker.ClientVersion() + "/volumes/" + v.Name).
+				Reply(http.StatusNoContent)
+		}
+		// Run test
+		err := Remove(context.Background(), "before-rls", fsys)
+		// Check error
+		assert.NoError(t, err)
+		assert.Empty(t, apitest.ListUnmatchedRequests())
+	})
+
+	t.Run("throws error on missing snapshot", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, utils.WriteConfig(fsys, false))
+		// Setup mock docker
+		require.NoError(t, apitest.MockDocker(utils.Docker))
+		defer gock.OffAll()
+		mockVolumeList()
+		// Run test
+		err := Remove(context.Background(), "before-rls", fsys)
+		// Check error
+		assert.ErrorContains(t, err, "snapshot not found: before-rls")
+		assert.Empty(t, apitest.ListUnmatchedRequests())
+	})
+}
@@ -43,6 +43,11 @@ func Run(ctx context.Context, fsys afero.Fs) error {
 	} else if !errors.Is(err, utils.ErrNotRunning) {
 		return err
 	}
+	if err := utils.Config.ReassignPorts(); err != nil {
+		return err
+	} else if err := utils.SaveAutoPorts(fsys); err != nil {
+		return err
+	}
 	err := StartDatabase(ctx, fsys, os.Stderr)
 	if err != nil {
 		if err := utils.DockerRemoveAll(context.Background(), os.Stderr, utils.Config.ProjectId); err != nil {
@@ -116,7 +121,8 @@ func NewHostConfig() container.HostConfig {
 	return hostConfig
 }
 
-func StartDatabase(ctx context.Context, fsys afero.Fs, w io.Writer, options ...func(*pgx.ConnConfig)) error {
+// Returns the configs used by StartDatabase to create the local database container.
+func NewDatabaseConfig() (container.Config, container.HostConfig, network.NetworkingConfig) {
 	config := NewContainerConfig()
 	hostConfig := NewHostConfig()
 	networkingConfig := network.NetworkingConfig{
@@ -137,6 +143,11 @@ EOF
 EOF`}
 		hostConfig.Tmpfs = map[string]string{"/docker-entrypoint-initdb.d": ""}
 	}
+	return config, hostConfig, networkingConfig
+}
+
+func StartDatabase(ctx context.Context, fsys afero.Fs, w io.Writer, options ...func(*pgx.ConnConfig)) error {
+	config, hostConfig, networkingConfig := NewDatabaseConfig()
 	// Creating volume will not override existing volume, so we must inspect explicitly
 	_, err := utils.Docker.VolumeInspect(ctx, utils.DbId)
 	utils.NoBackupVolume = client.IsErrNotFound(err)
@@ -0,0 +1,280 @@
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-errors/errors"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/db/lint"
+	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/pkg/migration"
+)
+
+const (
+	ENABLE_PROFILER  = "SET plpgsql_check.profiler TO on"
+	RESET_PROFILER   = "SELECT plpgsql_profiler_reset_all()"
+	CHECK_PLPGSQL    = "SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'plpgsql_check')"
+	DISABLE_PLPGSQL  = "DROP EXTENSION IF EXISTS plpgsql_check"
+	LCOV_FILENAME    = "lcov.info"
+	HTML_FILENAME    = "index.html"
+	coverageExcludes = "extensions"
+)
+
+var (
+	//go:embed templates/profile.sql
+	profileScript string
+	//go:embed templates/coverage.html
+	coverageEmbed    string
+	coverageTemplate = template.Must(template.New("coverage").Funcs(template.FuncMap{
+		"percent": func(hit, found int) string {
+			if found == 0 {
+				return "-"
+			}
+			return fmt.Sprintf("%.1f%%", float64(hit)*100/float64(found))
+		},
+	}).Parse(coverageEmbed))
+)
+
+// Collects line coverage of plpgsql functions using plpgsql_check profiler.
+type Profiler struct {
+	mu sync.Mutex
+	// Hit counts of instrumented lines relative to the function body, keyed by function name
+	functions map[string]map[int]int64
+}
+
+func NewProfiler() *Profiler {
+	return &Profiler{functions: map[string]map[int]int64{}}
+}
+
+// Enables the profiler for the current session. Requires plpgsql_check extension.
+func EnableProfiler(ctx context.Context, conn *pgx.Conn) error {
+	if _, err := conn.Exec(ctx, ENABLE_PROFILER); err != nil {
+		return errors.Errorf("failed to enable profiler: %w", err)
+	}
+	return nil
+}
+
+// Merges the profiles visible to conn. Profiles stored in shared memory are visible
+// to all sessions, so set sum to false when collecting from multiple connections to
+// the same database.
+func (p *Profiler) Collect(ctx context.Context, conn *pgx.Conn, sum bool) error {
+	excludes := append([]string{coverageExcludes}, migration.ManagedSchemas...)
+	rows, err := conn.Query(ctx, profileScript, excludes)
+	if err != nil {
+		return errors.Errorf("failed to query profile: %w", err)
+	}
+	defer rows.Close()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for rows.Next() {
+		var name, signature string
+		var line int
+		var hits int64
+		if err := rows.Scan(&name, &signature, &line, &hits); err != nil {
+			return errors.Errorf("failed to scan profile: %w", err)
+		}
+		lines, ok := p.functions[name]
+		if !ok {
+			lines = map[int]int64{}
+			p.functions[name] = lines
+		}
+		if sum {
+			lines[line] += hits
+		} else {
+			lines[line] = max(lines[line], hits)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Errorf("failed to parse profile: %w", err)
+	}
+	return nil
+}
+
+type FunctionCoverage struct {
+	Name string
+	Line int
+	Hits int64
+}
+
+type SourceLine struct {
+	Number int
+	Text   string
+	Hits   int64
+	// Empty if the line is not instrumented
+	Class string
+}
+
+type FileCoverage struct {
+	Path      string
+	Functions []FunctionCoverage
+	// Hit counts of instrumented lines in the migration file
+	Lines  map[int]int64
+	Source []SourceLine
+}
+
+func (f FileCoverage) Found() int {
+	return len(f.Lines)
+}
+
+func (f FileCoverage) Hit() (count int) {
+	for _, hits := range f.Lines {
+		if hits > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func (f FileCoverage) FunctionsHit() (count int) {
+	for _, fn := range f.Functions {
+		if fn.Hits > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// Maps collected profiles to the migration files where each function is last defined.
+func (p *Profiler) Coverage(fsys afero.Fs) ([]FileCoverage, error) {
+	locations, err := lint.LoadObjectLocations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]*FileCoverage{}
+	for name, lines := range p.functions {
+		loc, ok := locations[name]
+		if !ok {
+			fmt.Fprintln(os.Stderr, "Skipping coverage of function not found in migrations:", name)
+			continue
+		}
+		f, ok := files[loc.Path]
+		if !ok {
+			f = &FileCoverage{Path: loc.Path, Lines: map[int]int64{}}
+			files[loc.Path] = f
+		}
+		fn := FunctionCoverage{Name: name, Line: loc.Line}
+		for line, hits := range lines {
+			// Profiler line numbers are relative to the start of function body
+			f.Lines[loc.Line+line-1] += hits
+			fn.Hits = max(fn.Hits, hits)
+		}
+		f.Functions = append(f.Functions, fn)
+	}
+	result := make([]FileCoverage, 0, len(files))
+	for _, f := range files {
+		sort.Slice(f.Functions, func(i, j int) bool {
+			return f.Functions[i].Line < f.Functions[j].Line
+		})
+		if err := f.loadSource(fsys); err != nil {
+			return nil, err
+		}
+		result = append(result, *f)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result, nil
+}
+
+func (f *FileCoverage) loadSource(fsys afero.Fs) error {
+	contents, err := afero.ReadFile(fsys, f.Path)
+	if err != nil {
+		return errors.Errorf("failed to read migration: %w", err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	scanner.Buffer(nil, len(contents)+1)
+	for i := 1; scanner.Scan(); i++ {
+		line := SourceLine{Number: i, Text: scanner.Text()}
+		if hits, ok := f.Lines[i]; ok {
+			line.Hits = hits
+			line.Class = "miss"
+			if hits > 0 {
+				line.Class = "hit"
+			}
+		}
+		f.Source = append(f.Source, line)
+	}
+	return nil
+}
+
+// Ref: https://github.com/linux-test-project/lcov/blob/master/man/geninfo.1#L989
+func WriteLcov(files []FileCoverage, w io.Writer) error {
+	var out strings.Builder
+	for _, f := range files {
+		out.WriteString("TN:\n")
+		fmt.Fprintf(&out, "SF:%s\n", filepath.ToSlash(f.Path))
+		for _, fn := range f.Functions {
+			fmt.Fprintf(&out, "FN:%d,%s\n", fn.Line, fn.Name)
+		}
+		for _, fn := range f.Functions {
+			fmt.Fprintf(&out, "FNDA:%d,%s\n", fn.Hits, fn.Name)
+		}
+		fmt.Fprintf(&out, "FNF:%d\nFNH:%d\n", len(f.Functions), f.FunctionsHit())
+		lines := make([]int, 0, len(f.Lines))
+		for line := range f.Lines {
+			lines = append(lines, line)
+		}
+		sort.Ints(lines)
+		for _, line := range lines {
+			fmt.Fprintf(&out, "DA:%d,%d\n", line, f.Lines[line])
+		}
+		fmt.Fprintf(&out, "LF:%d\nLH:%d\n", f.Found(), f.Hit())
+		out.WriteString("end_of_record\n")
+	}
+	if _, err := io.WriteString(w, out.String()); err != nil {
+		return errors.Errorf("failed to write lcov report: %w", err)
+	}
+	return nil
+}
+
+func WriteHtml(files []FileCoverage, w io.Writer) error {
+	var hit, found int
+	for _, f := range files {
+		hit += f.Hit()
+		found += f.Found()
+	}
+	data := struct {
+		Files      []FileCoverage
+		Hit, Found int
+	}{files, hit, found}
+	if err := coverageTemplate.Execute(w, data); err != nil {
+		return errors.Errorf("failed to write html report: %w", err)
+	}
+	return nil
+}
+
+func (p *Profiler) WriteReports(fsys afero.Fs) error {
+	files, err := p.Coverage(fsys)
+	if err != nil {
+		return err
+	}
+	var lcov, html bytes.Buffer
+	if err := WriteLcov(files, &lcov); err != nil {
+		return err
+	}
+	if err := WriteHtml(files, &html); err != nil {
+		return err
+	}
+	lcovPath := filepath.Join(utils.DbCoverageDir, LCOV_FILENAME)
+	if err := utils.WriteFile(lcovPath, lcov.Bytes(), fsys); err != nil {
+		return err
+	}
+	htmlPath := filepath.Join(utils.DbCoverageDir, HTML_FILENAME)
+	if err := utils.WriteFile(htmlPath, html.Bytes(), fsys); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Wrote coverage reports to", utils.Bold(utils.DbCoverageDir))
+	return nil
+}
@@ -0,0 +1,139 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/pkg/migration"
+	"github.com/supabase/cli/pkg/pgtest"
+)
+
+const functionSql = `create table t (id int);
+
+create function public.f1(x int) returns int language plpgsql as $$
+begin
+  if x > 0 then
+    return x;
+  end if;
+  return 0;
+end
+$$;
+`
+
+var profileExcludes = append([]string{coverageExcludes}, migration.ManagedSchemas...)
+
+func TestCollectProfile(t *testing.T) {
+	t.Run("merges profiles from connections", func(t *testing.T) {
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query(profileScript, profileExcludes).
+			Reply("SELECT 2",
+				[]interface{}{"public.f1", "f1(integer)", 2, int64(2)},
+				[]interface{}{"public.f1", "f1(integer)", 3, int64(1)},
+			).
+			Query(profileScript, profileExcludes).
+			Reply("SELECT 1",
+				[]interface{}{"public.f1", "f1(integer)", 2, int64(3)},
+			)
+		// Run test
+		p := NewProfiler()
+		assert.NoError(t, p.Collect(context.Background(), conn.MockClient(t), false))
+		assert.NoError(t, p.Collect(context.Background(), conn.MockClient(t), true))
+		// Check error
+		assert.Equal(t, map[string]map[int]int64{
+			"public.f1": {2: 5, 3: 1},
+		}, p.functions)
+	})
+
+	t.Run("throws error on missing extension", func(t *testing.T) {
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query(profileScript, profileExcludes).
+			ReplyError(pgerrcode.UndefinedFunction, "function plpgsql_profiler_function_tb(oid) does not exist")
+		// Run test
+		err := NewProfiler().Collect(context.Background(), conn.MockClient(t), true)
+		// Check error
+		assert.ErrorContains(t, err, "function plpgsql_profiler_function_tb(oid) does not exist")
+	})
+}
+
+func TestCoverageReport(t *testing.T) {
+	migrationPath := filepath.Join(utils.MigrationsDir, "20240101000000_init.sql")
+	// Setup in-memory fs
+	fsys := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fsys, migrationPath, []byte(functionSql), 0644))
+	p := NewProfiler()
+	p.functions = map[string]map[int]int64{
+		"public.f1":      {2: 1, 3: 1, 4: 0, 6: 0},
+		"public.missing": {2: 1},
+	}
+
+	t.Run("maps profile to migration lines", func(t *testing.T) {
+		files, err := p.Coverage(fsys)
+		// Check error
+		assert.NoError(t, err)
+		require.Len(t, files, 1)
+		assert.Equal(t, migrationPath, files[0].Path)
+		assert.Equal(t, []FunctionCoverage{{Name: "public.f1", Line: 3, Hits: 1}}, files[0].Functions)
+		assert.Equal(t, map[int]int64{4: 1, 5: 1, 6: 0, 8: 0}, files[0].Lines)
+		assert.Equal(t, 2, files[0].Hit())
+		assert.Equal(t, 4, files[0].Found())
+		assert.Equal(t, "hit", files[0].Source[4].Class)
+		assert.Equal(t, "miss", files[0].Source[5].Class)
+		assert.Empty(t, files[0].Source[6].Class)
+	})
+
+	t.Run("writes lcov report", func(t *testing.T) {
+		files, err := p.Coverage(fsys)
+		require.NoError(t, err)
+		// Run test
+		var out bytes.Buffer
+		assert.NoError(t, WriteLcov(files, &out))
+		// Check output
+		assert.Equal(t, `TN:
+SF:supabase/migrations/20240101000000_init.sql
+FN:3,public.f1
+FNDA:1,public.f1
+FNF:1
+FNH:1
+DA:4,1
+DA:5,1
+DA:6,0
+DA:8,0
+LF:4
+LH:2
+end_of_record
+`, out.String())
+	})
+
+	t.Run("writes html report", func(t *testing.T) {
+		files, err := p.Coverage(fsys)
+		require.NoError(t, err)
+		// Run test
+		var out bytes.Buffer
+		assert.NoError(t, WriteHtml(files, &out))
+		// Check output
+		assert.Contains(t, out.String(), "2 of 4 lines covered (50.0%)")
+		assert.Contains(t, out.String(), `<tr class="miss"><td class="num">6</td><td class="num">0</td><td>    return x;</td></tr>`)
+	})
+
+	t.Run("writes reports to coverage dir", func(t *testing.T) {
+		assert.NoError(t, p.WriteReports(fsys))
+		// Check output
+		exists, err := afero.Exists(fsys, filepath.Join(utils.DbCoverageDir, LCOV_FILENAME))
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		exists, err = afero.Exists(fsys, filepath.Join(utils.DbCoverageDir, HTML_FILENAME))
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+}
@@ -0,0 +1,227 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/db/diff"
+	"github.com/supabase/cli/internal/db/lint"
+	"github.com/supabase/cli/internal/db/reset"
+	"github.com/supabase/cli/internal/db/start"
+	"github.com/supabase/cli/internal/migration/apply"
+	"github.com/supabase/cli/internal/utils"
+)
+
+const (
+	// Migrations are applied to the postgres database of the shadow container, which is then
+	// copied to a dedicated template because pg_cron and pg_net workers stay connected to it.
+	TEMPLATE_DATABASE = "supabase_test_template"
+	DROP_TEMPLATE     = "DROP DATABASE IF EXISTS " + TEMPLATE_DATABASE
+	CREATE_TEMPLATE   = "CREATE DATABASE " + TEMPLATE_DATABASE + " TEMPLATE postgres"
+	LOCK_TEMPLATE     = "ALTER DATABASE " + TEMPLATE_DATABASE + " ALLOW_CONNECTIONS false"
+	UNLOCK_POSTGRES   = "ALTER DATABASE postgres ALLOW_CONNECTIONS true"
+	CREATE_CLONE      = `CREATE DATABASE "%s" TEMPLATE ` + TEMPLATE_DATABASE
+	DROP_CLONE        = `DROP DATABASE IF EXISTS "%s" WITH (FORCE)`
+)
+
+// Runs each test file in its own database cloned from a template that is built
+// once from local migrations and seeds.
+func RunIsolated(ctx context.Context, files []string, jobs uint, profiler *Profiler, fsys afero.Fs, options ...func(*pgx.ConnConfig)) ([]FileResult, error) {
+	s, err := openIsolated(ctx, jobs, profiler, fsys, options...)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	return RunTests(ctx, files, s.Runners()), nil
+}
+
+// A shadow database container holding the template database and its clones.
+type isolatedSession struct {
+	shadow   string
+	jobs     uint
+	profiler *Profiler
+	cloner   *cloner
+}
+
+func openIsolated(ctx context.Context, jobs uint, profiler *Profiler, fsys afero.Fs, options ...func(*pgx.ConnConfig)) (*isolatedSession, error) {
+	fmt.Fprintln(os.Stderr, "Creating template database...")
+	shadow, err := diff.CreateShadowDatabase(ctx, utils.Config.Db.ShadowPort)
+	if err != nil {
+		return nil, err
+	}
+	s := &isolatedSession{shadow: shadow, jobs: jobs, profiler: profiler}
+	if err := s.init(ctx, fsys, options...); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *isolatedSession) init(ctx context.Context, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
+	if err := start.WaitForHealthyService(ctx, start.HealthTimeout, s.shadow); err != nil {
+		return err
+	}
+	if err := buildTemplate(ctx, s.shadow, s.profiler != nil, fsys, options...); err != nil {
+		return err
+	}
+	// Clones are managed from a separate database since the template must not have active connections
+	admin, err := diff.ConnectShadowDatabase(ctx, 10*time.Second, append(options, func(cc *pgx.ConnConfig) {
+		cc.Database = "template1"
+	})...)
+	if err != nil {
+		return err
+	}
+	s.cloner = &cloner{
+		admin:    admin,
+		config:   pgconn.Config{Port: utils.Config.Db.ShadowPort},
+		options:  options,
+		profiler: s.profiler,
+		fsys:     fsys,
+	}
+	return s.cloner.copyTemplate(ctx)
+}
+
+func (s *isolatedSession) Runners() []Runner {
+	runners := make([]Runner, max(s.jobs, 1))
+	for i := range runners {
+		runners[i] = s.cloner.Run
+	}
+	return runners
+}
+
+// Applies new local migrations to the template database, or rebuilds the template
+// if any existing migration is edited.
+func (s *isolatedSession) Migrate(ctx context.Context, edited bool) error {
+	if edited {
+		fsys, options := s.cloner.fsys, s.cloner.options
+		s.Close()
+		rebuilt, err := openIsolated(ctx, s.jobs, s.profiler, fsys, options...)
+		if err != nil {
+			return err
+		}
+		*s = *rebuilt
+		return nil
+	}
+	conn, err := diff.ConnectShadowDatabase(ctx, 10*time.Second, s.cloner.options...)
+	if err != nil {
+		return err
+	}
+	// Must disconnect before postgres can be copied to the template
+	err = applyPending(ctx, conn, s.cloner.fsys)
+	conn.Close(context.Background())
+	if err != nil {
+		return err
+	}
+	return s.cloner.copyTemplate(ctx)
+}
+
+func (s *isolatedSession) Close() {
+	if s.cloner != nil {
+		s.cloner.admin.Close(context.Background())
+	}
+	utils.DockerRemove(s.shadow)
+}
+
+func buildTemplate(ctx context.Context, container string, coverage bool, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
+	conn, err := diff.ConnectShadowDatabase(ctx, 10*time.Second, options...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+	if err := start.SetupDatabase(ctx, conn, container[:12], os.Stderr, fsys); err != nil {
+		return err
+	}
+	if err := apply.MigrateAndSeed(ctx, "", conn, fsys); err != nil {
+		return err
+	}
+	if _, err := conn.Exec(ctx, ENABLE_PGTAP); err != nil {
+		return errors.Errorf("failed to enable pgTAP: %w", err)
+	}
+	if coverage {
+		if _, err := conn.Exec(ctx, lint.ENABLE_PGSQL_CHECK); err != nil {
+			return errors.Errorf("failed to enable plpgsql_check: %w", err)
+		}
+	}
+	return nil
+}
+
+type cloner struct {
+	// Serialises create and drop database statements on the admin connection
+	mu      sync.Mutex
+	admin   *pgx.Conn
+	config  pgconn.Config
+	options []func(*pgx.ConnConfig)
+	// Collects coverage from each clone before it is dropped, nil if disabled
+	profiler *Profiler
+	fsys     afero.Fs
+}
+
+func (c *cloner) exec(ctx context.Context, sql string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := c.admin.Exec(ctx, sql)
+	return err
+}
+
+// Replaces the template database with a copy of the migrated postgres database.
+func (c *cloner) copyTemplate(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Background workers must be disconnected before postgres can be copied
+	if err := reset.DisconnectClients(ctx, c.admin); err != nil {
+		return err
+	}
+	// Database commands cannot run in a transaction block, so they are executed one by one
+	var err error
+	for _, sql := range []string{DROP_TEMPLATE, CREATE_TEMPLATE, LOCK_TEMPLATE} {
+		if _, err = c.admin.Exec(ctx, sql); err != nil {
+			err = errors.Errorf("failed to copy template database: %w", err)
+			break
+		}
+	}
+	// Always allow connections to postgres again for applying new migrations
+	if _, unlockErr := c.admin.Exec(ctx, UNLOCK_POSTGRES); unlockErr != nil {
+		return errors.Join(err, errors.Errorf("failed to unlock database: %w", unlockErr))
+	}
+	return err
+}
+
+func (c *cloner) Run(ctx context.Context, index int, path string) FileResult {
+	name := fmt.Sprintf("supabase_test_%d", index)
+	if err := c.exec(ctx, fmt.Sprintf(CREATE_CLONE, name)); err != nil {
+		return FileResult{Path: path, Plan: -1, Error: fmt.Sprintf("failed to clone template database: %s", err)}
+	}
+	defer func() {
+		if err := c.exec(context.Background(), fmt.Sprintf(DROP_CLONE, name)); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to drop database:", err)
+		}
+	}()
+	config := c.config
+	config.Database = name
+	conn, err := utils.ConnectLocalPostgres(ctx, config, c.options...)
+	if err != nil {
+		return FileResult{Path: path, Plan: -1, Error: err.Error()}
+	}
+	defer conn.Close(context.Background())
+	if c.profiler != nil {
+		if err := EnableProfiler(ctx, conn); err != nil {
+			return FileResult{Path: path, Plan: -1, Error: err.Error()}
+		}
+	}
+	// Tests are free to commit since the clone is dropped afterwards
+	result := runFile(ctx, path, conn, false, c.fsys)
+	if c.profiler != nil {
+		// Each clone has its own profiles so they are summed
+		if err := c.profiler.Collect(ctx, conn, true); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to collect coverage:", err)
+		}
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/pkg/pgtest"
+)
+
+func TestCloner(t *testing.T) {
+	t.Run("runs test file on cloned database", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, "a_test.sql", []byte(testSql), 0644))
+		// Setup mock postgres
+		admin := pgtest.NewConn()
+		defer admin.Close(t)
+		admin.Query(fmt.Sprintf(CREATE_CLONE, "supabase_test_3")).
+			Reply("CREATE DATABASE").
+			Query(fmt.Sprintf(DROP_CLONE, "supabase_test_3")).
+			Reply("DROP DATABASE")
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		// Transaction control statements are executed as is
+		conn.Query("begin").Reply("BEGIN").
+			Query("select plan(1)").
+			Reply("SELECT 1", []interface{}{"1..1"}).
+			Query("select ok(true, 'works')").
+			Reply("SELECT 1", []interface{}{"ok 1 - works"}).
+			Query("select * from finish()").
+			Reply("SELECT 0").
+			Query("rollback").Reply("ROLLBACK")
+		c := cloner{
+			admin:   admin.MockClient(t),
+			config:  pgconn.Config{Host: "127.0.0.1", Port: 54320},
+			options: []func(*pgx.ConnConfig){conn.Intercept},
+			fsys:    fsys,
+		}
+		// Run test
+		result := c.Run(context.Background(), 3, "a_test.sql")
+		// Check error
+		assert.True(t, result.Passed(), result.Error)
+		assert.Len(t, result.Assertions, 1)
+	})
+
+	t.Run("throws error on clone failure", func(t *testing.T) {
+		// Setup mock postgres
+		admin := pgtest.NewConn()
+		defer admin.Close(t)
+		admin.Query(fmt.Sprintf(CREATE_CLONE, "supabase_test_0")).
+			ReplyError(pgerrcode.ObjectInUse, `source database "postgres" is being accessed by other users`)
+		c := cloner{admin: admin.MockClient(t), fsys: afero.NewMemMapFs()}
+		// Run test
+		result := c.Run(context.Background(), 0, "a_test.sql")
+		// Check error
+		assert.Contains(t, result.Error, "failed to clone template database:")
+	})
+}
+
+func TestCopyTemplate(t *testing.T) {
+	t.Run("copies postgres to dedicated template", func(t *testing.T) {
+		// Setup mock postgres
+		admin := pgtest.NewConn()
+		defer admin.Close(t)
+		admin.Query("ALTER DATABASE postgres ALLOW_CONNECTIONS false;").
+			Reply("ALTER DATABASE").
+			Query(fmt.Sprintf(utils.TerminateDbSqlFmt, "postgres")).
+			Reply("SELECT 1").
+			Query(DROP_TEMPLATE).
+			Reply("DROP DATABASE").
+			Query(CREATE_TEMPLATE).
+			Reply("CREATE DATABASE").
+			Query(LOCK_TEMPLATE).
+			Reply("ALTER DATABASE").
+			Query(UNLOCK_POSTGRES).
+			Reply("ALTER DATABASE")
+		c := cloner{admin: admin.MockClient(t)}
+		// Run test
+		err := c.copyTemplate(context.Background())
+		// Check error
+		assert.NoError(t, err)
+	})
+
+	t.Run("unlocks postgres on copy failure", func(t *testing.T) {
+		// Setup mock postgres
+		admin := pgtest.NewConn()
+		defer admin.Close(t)
+		admin.Query("ALTER DATABASE postgres ALLOW_CONNECTIONS false;").
+			Reply("ALTER DATABASE").
+			Query(fmt.Sprintf(utils.TerminateDbSqlFmt, "postgres")).
+			Reply("SELECT 1").
+			Query(DROP_TEMPLATE).
+			Reply("DROP DATABASE").
+			Query(CREATE_TEMPLATE).
+			ReplyError(pgerrcode.ObjectInUse, `source database "postgres" is being accessed by other users`).
+			Query(UNLOCK_POSTGRES).
+			Reply("ALTER DATABASE")
+		c := cloner{admin: admin.MockClient(t)}
+		// Run test
+		err := c.copyTemplate(context.Background())
+		// Check error
+		assert.ErrorContains(t, err, "failed to copy template database:")
+	})
+}
@@ -0,0 +1,388 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SET_JWT_CLAIMS = "SELECT set_config('request.jwt.claims', $1, true)"
+	SET_LOCAL_ROLE = "SET LOCAL ROLE "
+	// Role assumed by cases without a role claim
+	DEFAULT_ROLE = "anon"
+
+	OperationSelect = "select"
+	OperationInsert = "insert"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+)
+
+// Declarative RLS test cases loaded from supabase/tests/policies/*.yaml
+type PolicySuite struct {
+	// Default table of all cases
+	Table string       `yaml:"table"`
+	Cases []PolicyCase `yaml:"cases"`
+}
+
+type PolicyCase struct {
+	Name  string `yaml:"name"`
+	Table string `yaml:"table"`
+	// JWT claims, ie. role, sub, and any custom claims
+	Claims    map[string]interface{} `yaml:"claims"`
+	Operation string                 `yaml:"operation"`
+	// Column values to insert or update
+	Values map[string]interface{} `yaml:"values"`
+	// Optional SQL condition to filter rows
+	Where  string       `yaml:"where"`
+	Expect PolicyExpect `yaml:"expect"`
+}
+
+type PolicyExpect struct {
+	// Number of rows returned or affected by the operation
+	Rows *int64 `yaml:"rows"`
+	// Rows returned or affected by the operation in any order, comparing only the listed columns
+	Data []map[string]interface{} `yaml:"data"`
+	// Expected SQLSTATE or substring of the error message
+	Error string `yaml:"error"`
+}
+
+type PolicyResult struct {
+	Table     string `json:"table"`
+	Operation string `json:"operation"`
+	Role      string `json:"role"`
+	Passed    bool   `json:"passed"`
+}
+
+// Only YAML files under the policies directory are run, so that other fixtures are ignored.
+func isPolicyFile(path string) bool {
+	if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+		return false
+	}
+	dir, err := filepath.Abs(utils.DbPolicyTestsDir)
+	if err != nil {
+		return false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(dir, abs)
+	return err == nil && !strings.HasPrefix(rel, "..")
+}
+
+func LoadPolicySuite(path string, fsys afero.Fs) (PolicySuite, error) {
+	var suite PolicySuite
+	f, err := fsys.Open(path)
+	if err != nil {
+		return suite, errors.Errorf("failed to open policy file: %w", err)
+	}
+	defer f.Close()
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&suite); err != nil && !errors.Is(err, io.EOF) {
+		return suite, errors.Errorf("failed to parse policy file: %w", err)
+	}
+	for i := range suite.Cases {
+		c := &suite.Cases[i]
+		if len(c.Table) == 0 {
+			c.Table = suite.Table
+		}
+		if err := c.Validate(); err != nil {
+			return suite, errors.Errorf("invalid case %d: %w", i+1, err)
+		}
+	}
+	return suite, nil
+}
+
+func (c PolicyCase) Validate() error {
+	if len(c.Table) == 0 {
+		return errors.New("missing table")
+	}
+	switch c.Operation {
+	case OperationSelect, OperationDelete:
+	case OperationInsert, OperationUpdate:
+		if len(c.Values) == 0 {
+			return errors.Errorf("missing values to %s", c.Operation)
+		}
+	default:
+		return errors.Errorf("operation must be one of [ %s ]", strings.Join([]string{
+			OperationSelect,
+			OperationInsert,
+			OperationUpdate,
+			OperationDelete,
+		}, " | "))
+	}
+	hasRows := c.Expect.Rows != nil || len(c.Expect.Data) > 0
+	if hasRows == (len(c.Expect.Error) > 0) {
+		return errors.New("expect must specify either rows or error")
+	}
+	if c.Expect.Rows != nil && len(c.Expect.Data) > 0 && *c.Expect.Rows != int64(len(c.Expect.Data)) {
+		return errors.New("expect rows must match the number of data rows")
+	}
+	return nil
+}
+
+func (c PolicyCase) Role() string {
+	if role, ok := c.Claims["role"].(string); ok && len(role) > 0 {
+		return role
+	}
+	return DEFAULT_ROLE
+}
+
+func (c PolicyCase) Description() string {
+	if len(c.Name) > 0 {
+		return c.Name
+	}
+	return fmt.Sprintf("%s can %s %s", c.Role(), c.Operation, c.Table)
+}
+
+// Builds the statement to run as the case role. Values are cast to column types by
+// populating a record of the target table.
+func (c PolicyCase) Statement() (string, []interface{}, error) {
+	table := pgx.Identifier(strings.Split(c.Table, ".")).Sanitize()
+	where := ""
+	if len(c.Where) > 0 {
+		where = " WHERE " + c.Where
+	}
+	var columns []string
+	for k := range c.Values {
+		columns = append(columns, pgx.Identifier{k}.Sanitize())
+	}
+	sort.Strings(columns)
+	cols := strings.Join(columns, ", ")
+	var args []interface{}
+	if len(c.Values) > 0 {
+		values, err := json.Marshal(c.Values)
+		if err != nil {
+			return "", nil, errors.Errorf("failed to encode values: %w", err)
+		}
+		args = append(args, string(values))
+	}
+	// Rows are returned as json objects only when their contents are checked
+	parts := strings.Split(c.Table, ".")
+	row := "to_jsonb(" + pgx.Identifier{parts[len(parts)-1]}.Sanitize() + ")"
+	returning := ""
+	if len(c.Expect.Data) > 0 {
+		returning = " RETURNING " + row
+	} else {
+		row = "1"
+	}
+	switch c.Operation {
+	case OperationInsert:
+		return fmt.Sprintf("INSERT INTO %[1]s (%[2]s) SELECT %[2]s FROM json_populate_record(NULL::%[1]s, $1::json)%[3]s", table, cols, returning), args, nil
+	case OperationUpdate:
+		return fmt.Sprintf("UPDATE %[1]s SET (%[2]s) = (SELECT %[2]s FROM json_populate_record(NULL::%[1]s, $1::json))%[3]s%[4]s", table, cols, where, returning), args, nil
+	case OperationDelete:
+		return fmt.Sprintf("DELETE FROM %s%s%s", table, where, returning), args, nil
+	}
+	return fmt.Sprintf("SELECT %s FROM %s%s", row, table, where), args, nil
+}
+
+// Runs all cases in a policy file, emitting one TAP assertion per case.
+func execPolicyFile(ctx context.Context, path string, conn *pgx.Conn, p *TapParser, fsys afero.Fs) ([]PolicyResult, error) {
+	suite, err := LoadPolicySuite(path, fsys)
+	if err != nil {
+		return nil, err
+	}
+	p.Feed(fmt.Sprintf("1..%d", len(suite.Cases)), 0)
+	results := make([]PolicyResult, len(suite.Cases))
+	for i, c := range suite.Cases {
+		start := time.Now()
+		failure := runPolicyCase(ctx, conn, c)
+		results[i] = PolicyResult{
+			Table:     c.Table,
+			Operation: c.Operation,
+			Role:      c.Role(),
+			Passed:    failure == nil,
+		}
+		line := fmt.Sprintf("ok %d - %s", i+1, c.Description())
+		if failure != nil {
+			line = fmt.Sprintf("not ok %d - %s\n# %s", i+1, c.Description(), failure.Error())
+		}
+		p.Feed(line, time.Since(start))
+	}
+	return results, nil
+}
+
+// Runs a case in a transaction that is always rolled back. Returns nil if the outcome
+// matches the expectation.
+func runPolicyCase(ctx context.Context, conn *pgx.Conn, c PolicyCase) error {
+	claims := c.Claims
+	if claims == nil {
+		claims = map[string]interface{}{"role": DEFAULT_ROLE}
+	}
+	encoded, err := json.Marshal(claims)
+	if err != nil {
+		return errors.Errorf("failed to encode claims: %w", err)
+	}
+	sql, args, err := c.Statement()
+	if err != nil {
+		return err
+	}
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return errors.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(context.Background()); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+	if _, err := tx.Exec(ctx, SET_JWT_CLAIMS, string(encoded)); err != nil {
+		return errors.Errorf("failed to set claims: %w", err)
+	}
+	if _, err := tx.Exec(ctx, SET_LOCAL_ROLE+pgx.Identifier{c.Role()}.Sanitize()); err != nil {
+		return errors.Errorf("failed to set role: %w", err)
+	}
+	if len(c.Expect.Data) == 0 {
+		tag, err := tx.Exec(ctx, sql, args...)
+		return checkExpect(c.Expect, tag, err)
+	}
+	data, err := queryData(ctx, tx, sql, args...)
+	if err != nil {
+		return errors.Errorf("expected %d rows but got error: %s", len(c.Expect.Data), err.Error())
+	}
+	return checkData(c.Expect.Data, data)
+}
+
+func queryData(ctx context.Context, tx pgx.Tx, sql string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var data []map[string]interface{}
+	for rows.Next() {
+		var encoded string
+		if err := rows.Scan(&encoded); err != nil {
+			return nil, err
+		}
+		var row map[string]interface{}
+		if err := json.Unmarshal([]byte(encoded), &row); err != nil {
+			return nil, err
+		}
+		data = append(data, row)
+	}
+	return data, rows.Err()
+}
+
+// Each expected row must match a distinct actual row on the columns it lists.
+func checkData(expected, actual []map[string]interface{}) error {
+	if len(expected) != len(actual) {
+		return errors.Errorf("expected %d rows but got %d", len(expected), len(actual))
+	}
+	matched := make([]bool, len(actual))
+	for _, row := range expected {
+		// Encodes expected values as json so that numbers compare equal
+		encoded, err := json.Marshal(row)
+		if err != nil {
+			return errors.Errorf("failed to encode expected row: %w", err)
+		}
+		var want map[string]interface{}
+		if err := json.Unmarshal(encoded, &want); err != nil {
+			return errors.Errorf("failed to decode expected row: %w", err)
+		}
+		found := false
+		for i, got := range actual {
+			if !matched[i] && matchColumns(want, got) {
+				matched[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			return errors.Errorf("expected row not found: %s", encoded)
+		}
+	}
+	return nil
+}
+
+func matchColumns(want, got map[string]interface{}) bool {
+	for k, v := range want {
+		if actual, ok := got[k]; !ok || !reflect.DeepEqual(v, actual) {
+			return false
+		}
+	}
+	return true
+}
+
+func checkExpect(expect PolicyExpect, tag pgconn.CommandTag, err error) error {
+	if len(expect.Error) > 0 {
+		if err == nil {
+			return errors.Errorf("expected error %q but got %d rows", expect.Error, tag.RowsAffected())
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == expect.Error {
+			return nil
+		}
+		if !strings.Contains(err.Error(), expect.Error) {
+			return errors.Errorf("expected error %q but got: %s", expect.Error, err.Error())
+		}
+		return nil
+	}
+	if err != nil {
+		return errors.Errorf("expected %d rows but got error: %s", *expect.Rows, err.Error())
+	}
+	if n := tag.RowsAffected(); n != *expect.Rows {
+		return errors.Errorf("expected %d rows but got %d", *expect.Rows, n)
+	}
+	return nil
+}
+
+// Prints a table of pass or fail status for each table operation by role.
+func printMatrix(results []FileResult, w io.Writer) {
+	var roles, rows []string
+	cells := map[string]map[string]bool{}
+	for _, r := range results {
+		for _, p := range r.Policies {
+			if !utils.SliceContains(roles, p.Role) {
+				roles = append(roles, p.Role)
+			}
+			key := p.Table + " " + p.Operation
+			if _, ok := cells[key]; !ok {
+				rows = append(rows, key)
+				cells[key] = map[string]bool{}
+			}
+			// A cell passes only if all its cases pass
+			if passed, ok := cells[key][p.Role]; !ok || passed {
+				cells[key][p.Role] = p.Passed
+			}
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tOPERATION\t"+strings.Join(roles, "\t"))
+	for _, key := range rows {
+		table, op, _ := strings.Cut(key, " ")
+		line := table + "\t" + op
+		for _, role := range roles {
+			status := "-"
+			if passed, ok := cells[key][role]; ok && passed {
+				status = "pass"
+			} else if ok {
+				status = "FAIL"
+			}
+			line += "\t" + status
+		}
+		fmt.Fprintln(tw, line)
+	}
+	tw.Flush()
+}
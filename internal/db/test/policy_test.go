@@ -0,0 +1,243 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/pkg/pgtest"
+)
+
+const policySql = `table: public.todos
+cases:
+  - name: anon cannot read todos
+    operation: select
+    expect:
+      rows: 0
+  - claims:
+      role: authenticated
+      sub: user-1
+    operation: insert
+    values:
+      title: Not mine
+    expect:
+      error: "42501"
+`
+
+func TestLoadPolicySuite(t *testing.T) {
+	policyPath := filepath.Join(utils.DbPolicyTestsDir, "todos.yaml")
+
+	t.Run("inherits default table", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, policyPath, []byte(policySql), 0644))
+		// Run test
+		suite, err := LoadPolicySuite(policyPath, fsys)
+		// Check error
+		assert.NoError(t, err)
+		require.Len(t, suite.Cases, 2)
+		assert.Equal(t, "public.todos", suite.Cases[1].Table)
+		assert.Equal(t, "anon cannot read todos", suite.Cases[0].Description())
+		assert.Equal(t, "authenticated can insert public.todos", suite.Cases[1].Description())
+	})
+
+	t.Run("throws error on unknown field", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, policyPath, []byte("tables: public.todos"), 0644))
+		// Run test
+		_, err := LoadPolicySuite(policyPath, fsys)
+		// Check error
+		assert.ErrorContains(t, err, "field tables not found")
+	})
+
+	t.Run("throws error on invalid case", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, policyPath, []byte(`table: t
+cases:
+  - operation: update
+    expect:
+      rows: 1
+`), 0644))
+		// Run test
+		_, err := LoadPolicySuite(policyPath, fsys)
+		// Check error
+		assert.ErrorContains(t, err, "invalid case 1: missing values to update")
+	})
+}
+
+func TestIsPolicyFile(t *testing.T) {
+	assert.True(t, isPolicyFile(filepath.Join(utils.DbPolicyTestsDir, "todos.yaml")))
+	assert.True(t, isPolicyFile(filepath.Join(utils.DbPolicyTestsDir, "nested", "todos.yml")))
+	assert.False(t, isPolicyFile(filepath.Join(utils.DbTestsDir, "fixtures", "todos.yaml")))
+	assert.False(t, isPolicyFile(filepath.Join(utils.DbPolicyTestsDir, "todos.sql")))
+}
+
+func TestPolicyStatement(t *testing.T) {
+	values := map[string]interface{}{"title": "a", "done": true}
+
+	t.Run("builds select", func(t *testing.T) {
+		sql, args, err := PolicyCase{Table: "public.todos", Operation: OperationSelect, Where: "id = 1"}.Statement()
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT 1 FROM "public"."todos" WHERE id = 1`, sql)
+		assert.Empty(t, args)
+	})
+
+	t.Run("builds insert", func(t *testing.T) {
+		sql, args, err := PolicyCase{Table: "todos", Operation: OperationInsert, Values: values}.Statement()
+		assert.NoError(t, err)
+		assert.Equal(t, `INSERT INTO "todos" ("done", "title") SELECT "done", "title" FROM json_populate_record(NULL::"todos", $1::json)`, sql)
+		assert.Equal(t, []interface{}{`{"done":true,"title":"a"}`}, args)
+	})
+
+	t.Run("builds update", func(t *testing.T) {
+		sql, _, err := PolicyCase{Table: "todos", Operation: OperationUpdate, Values: values, Where: "true"}.Statement()
+		assert.NoError(t, err)
+		assert.Equal(t, `UPDATE "todos" SET ("done", "title") = (SELECT "done", "title" FROM json_populate_record(NULL::"todos", $1::json)) WHERE true`, sql)
+	})
+
+	t.Run("builds select returning data", func(t *testing.T) {
+		c := PolicyCase{Table: "public.todos", Operation: OperationSelect, Expect: PolicyExpect{
+			Data: []map[string]interface{}{{"id": 1}},
+		}}
+		sql, _, err := c.Statement()
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT to_jsonb("todos") FROM "public"."todos"`, sql)
+	})
+
+	t.Run("builds update returning data", func(t *testing.T) {
+		c := PolicyCase{Table: "todos", Operation: OperationUpdate, Values: values, Expect: PolicyExpect{
+			Data: []map[string]interface{}{{"id": 1}},
+		}}
+		sql, _, err := c.Statement()
+		assert.NoError(t, err)
+		assert.Equal(t, `UPDATE "todos" SET ("done", "title") = (SELECT "done", "title" FROM json_populate_record(NULL::"todos", $1::json)) RETURNING to_jsonb("todos")`, sql)
+	})
+
+	t.Run("builds delete", func(t *testing.T) {
+		sql, _, err := PolicyCase{Table: "todos", Operation: OperationDelete}.Statement()
+		assert.NoError(t, err)
+		assert.Equal(t, `DELETE FROM "todos"`, sql)
+	})
+}
+
+func TestRunPolicyFile(t *testing.T) {
+	policyPath := filepath.Join(utils.DbPolicyTestsDir, "todos.yaml")
+
+	t.Run("reports policy matrix", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, policyPath, []byte(policySql), 0644))
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query("begin").Reply("BEGIN").
+			Query(SET_JWT_CLAIMS, `{"role":"anon"}`).
+			Reply("SELECT 1", []interface{}{`{"role":"anon"}`}).
+			Query(SET_LOCAL_ROLE+`"anon"`).
+			Reply("SET").
+			Query(`SELECT 1 FROM "public"."todos"`).
+			Reply("SELECT 2", []interface{}{1}, []interface{}{1}).
+			Query("rollback").Reply("ROLLBACK").
+			Query("begin").Reply("BEGIN").
+			Query(SET_JWT_CLAIMS, `{"role":"authenticated","sub":"user-1"}`).
+			Reply("SELECT 1", []interface{}{`{"role":"authenticated","sub":"user-1"}`}).
+			Query(SET_LOCAL_ROLE+`"authenticated"`).
+			Reply("SET").
+			Query(`INSERT INTO "public"."todos" ("title") SELECT "title" FROM json_populate_record(NULL::"public"."todos", $1::json)`, `{"title":"Not mine"}`).
+			ReplyError(pgerrcode.InsufficientPrivilege, `new row violates row-level security policy for table "todos"`).
+			Query("rollback").Reply("ROLLBACK")
+		// Run test
+		result := RunFile(context.Background(), policyPath, conn.MockClient(t), fsys)
+		// Check error
+		assert.Equal(t, "Failed 1/2 subtests", result.Error)
+		require.Len(t, result.Assertions, 2)
+		assert.Equal(t, []string{"expected 0 rows but got 2"}, result.Assertions[0].Diagnostics)
+		assert.True(t, result.Assertions[1].Ok)
+		assert.Equal(t, []PolicyResult{
+			{Table: "public.todos", Operation: OperationSelect, Role: "anon"},
+			{Table: "public.todos", Operation: OperationInsert, Role: "authenticated", Passed: true},
+		}, result.Policies)
+		// Check matrix
+		var out bytes.Buffer
+		printMatrix([]FileResult{result}, &out)
+		assert.Equal(t, `
+TABLE         OPERATION  anon  authenticated
+public.todos  select     FAIL  -
+public.todos  insert     -     pass
+`, out.String())
+	})
+}
+
+func TestRunPolicyCase(t *testing.T) {
+	t.Run("checks returned data", func(t *testing.T) {
+		c := PolicyCase{Table: "todos", Operation: OperationSelect, Expect: PolicyExpect{
+			Data: []map[string]interface{}{{"id": 2, "title": "b"}, {"id": 1}},
+		}}
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query("begin").Reply("BEGIN").
+			Query(SET_JWT_CLAIMS, `{"role":"anon"}`).
+			Reply("SELECT 1", []interface{}{`{"role":"anon"}`}).
+			Query(SET_LOCAL_ROLE+`"anon"`).
+			Reply("SET").
+			Query(`SELECT to_jsonb("todos") FROM "todos"`).
+			Reply("SELECT 2", []interface{}{`{"id": 1, "title": "a"}`}, []interface{}{`{"id": 2, "title": "b"}`}).
+			Query("rollback").Reply("ROLLBACK")
+		// Run test
+		err := runPolicyCase(context.Background(), conn.MockClient(t), c)
+		// Check error
+		assert.NoError(t, err)
+	})
+}
+
+func TestCheckData(t *testing.T) {
+	actual := []map[string]interface{}{{"id": float64(1), "title": "a"}}
+
+	t.Run("matches listed columns", func(t *testing.T) {
+		err := checkData([]map[string]interface{}{{"id": 1}}, actual)
+		assert.NoError(t, err)
+	})
+
+	t.Run("throws error on mismatched row", func(t *testing.T) {
+		err := checkData([]map[string]interface{}{{"id": 1, "title": "b"}}, actual)
+		assert.ErrorContains(t, err, `expected row not found: {"id":1,"title":"b"}`)
+	})
+
+	t.Run("throws error on mismatched count", func(t *testing.T) {
+		err := checkData(nil, actual)
+		assert.ErrorContains(t, err, "expected 0 rows but got 1")
+	})
+}
+
+func TestCheckExpect(t *testing.T) {
+	rows := int64(1)
+
+	t.Run("matches error message", func(t *testing.T) {
+		err := checkExpect(PolicyExpect{Error: "general error"}, nil, assert.AnError)
+		assert.NoError(t, err)
+	})
+
+	t.Run("throws error on mismatched error", func(t *testing.T) {
+		err := checkExpect(PolicyExpect{Error: "row-level security"}, nil, assert.AnError)
+		assert.ErrorContains(t, err, `expected error "row-level security" but got:`)
+	})
+
+	t.Run("throws error on unexpected success", func(t *testing.T) {
+		err := checkExpect(PolicyExpect{Error: "42501"}, []byte("INSERT 0 1"), nil)
+		assert.ErrorContains(t, err, `expected error "42501" but got 1 rows`)
+	})
+
+	t.Run("throws error on unexpected failure", func(t *testing.T) {
+		err := checkExpect(PolicyExpect{Rows: &rows}, nil, assert.AnError)
+		assert.ErrorContains(t, err, "expected 1 rows but got error:")
+	})
+}
@@ -0,0 +1,142 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/pkg/junit"
+)
+
+const (
+	ReporterTap   = "tap"
+	ReporterJunit = "junit"
+	ReporterJson  = "json"
+)
+
+var AllowedReporters = []string{
+	ReporterTap,
+	ReporterJunit,
+	ReporterJson,
+}
+
+func Report(results []FileResult, reporter string, w io.Writer) error {
+	switch reporter {
+	case ReporterJunit:
+		return reportJunit(results, w)
+	case ReporterJson:
+		return reportJson(results, w)
+	}
+	return reportTap(results, w)
+}
+
+// Writes each test file as a TAP 14 subtest.
+func reportTap(results []FileResult, w io.Writer) error {
+	var out strings.Builder
+	out.WriteString("TAP version 14\n")
+	for i, r := range results {
+		fmt.Fprintln(&out, "# Subtest:", r.Path)
+		for _, line := range r.Output {
+			fmt.Fprintln(&out, "    "+line)
+		}
+		if r.Passed() {
+			fmt.Fprintf(&out, "ok %d - %s\n", i+1, r.Path)
+		} else {
+			// Multiline errors are printed as diagnostics
+			lines := strings.Split(r.Error, "\n")
+			fmt.Fprintf(&out, "not ok %d - %s # %s\n", i+1, r.Path, lines[0])
+			for _, line := range lines[1:] {
+				fmt.Fprintln(&out, "# "+line)
+			}
+		}
+	}
+	fmt.Fprintf(&out, "1..%d\n", len(results))
+	if _, err := io.WriteString(w, out.String()); err != nil {
+		return errors.Errorf("failed to write tap report: %w", err)
+	}
+	return nil
+}
+
+func reportJunit(results []FileResult, w io.Writer) error {
+	report := junit.TestSuites{Name: "pgTAP"}
+	for _, r := range results {
+		suite := junit.TestSuite{
+			Name: strings.TrimSuffix(filepath.Base(r.Path), filepath.Ext(r.Path)),
+			File: filepath.ToSlash(r.Path),
+		}
+		for _, a := range r.Assertions {
+			name := strconv.Itoa(a.Number)
+			if len(a.Description) > 0 {
+				name += " - " + a.Description
+			}
+			tc := junit.TestCase{
+				Name:      name,
+				ClassName: suite.Name,
+				File:      suite.File,
+				Time:      a.Duration.Seconds(),
+			}
+			if a.Directive == DirectiveSkip {
+				tc.Skipped = &junit.Message{Message: a.Reason}
+			} else if a.Failed() {
+				tc.Failure = &junit.Message{
+					Message: "not ok " + name,
+					Text:    strings.Join(a.Diagnostics, "\n"),
+				}
+			}
+			suite.Add(tc)
+		}
+		// Plan and execution errors are reported as an additional test case
+		if !r.Passed() && r.Failures() == 0 {
+			suite.Add(junit.TestCase{
+				Name:      "plan",
+				ClassName: suite.Name,
+				File:      suite.File,
+				Error:     &junit.Message{Message: r.Error},
+			})
+		}
+		// Statements that do not emit assertions also take time
+		suite.Time = r.Duration.Seconds()
+		report.Add(suite)
+	}
+	return report.Write(w)
+}
+
+func reportJson(results []FileResult, w io.Writer) error {
+	if results == nil {
+		results = []FileResult{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(results); err != nil {
+		return errors.Errorf("failed to encode json report: %w", err)
+	}
+	return nil
+}
+
+func printSummary(results []FileResult, elapsed time.Duration, w io.Writer) error {
+	var tests int
+	var failed []string
+	for _, r := range results {
+		tests += len(r.Assertions)
+		if !r.Passed() {
+			failed = append(failed, fmt.Sprintf("%s: %s", r.Path, strings.Split(r.Error, "\n")[0]))
+		}
+	}
+	fmt.Fprintln(w)
+	for _, f := range failed {
+		fmt.Fprintln(w, utils.Red(f))
+	}
+	fmt.Fprintf(w, "Files=%d, Tests=%d, %.2fs\n", len(results), tests, elapsed.Seconds())
+	if len(failed) > 0 {
+		fmt.Fprintln(w, "Result: FAIL")
+		return errors.New(ErrTestsFailed)
+	}
+	fmt.Fprintln(w, "Result: PASS")
+	return nil
+}
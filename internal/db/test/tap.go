@@ -0,0 +1,88 @@
+package test
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// Ref: https://testanything.org/tap-version-14-specification.html
+	planPattern      = regexp.MustCompile(`^1\.\.(\d+)(?:\s*#\s*(.*))?$`)
+	assertionPattern = regexp.MustCompile(`^(not )?ok\b\s*(\d*)\s*(?:-\s*)?([^#]*?)\s*(?:#\s*(?i:(skip|todo))\S*\s*(.*))?$`)
+)
+
+const (
+	DirectiveSkip = "SKIP"
+	DirectiveTodo = "TODO"
+)
+
+type Assertion struct {
+	Number      int           `json:"number"`
+	Description string        `json:"description"`
+	Ok          bool          `json:"ok"`
+	Directive   string        `json:"directive,omitempty"`
+	Reason      string        `json:"reason,omitempty"`
+	Diagnostics []string      `json:"diagnostics,omitempty"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// Returns true if the assertion should count towards a failed test file.
+func (a Assertion) Failed() bool {
+	return !a.Ok && a.Directive != DirectiveTodo
+}
+
+// Parses TAP lines emitted by pgTAP functions incrementally.
+type TapParser struct {
+	// Number of planned assertions, or -1 if no plan is found
+	Plan       int
+	Assertions []Assertion
+	Lines      []string
+}
+
+func NewTapParser() *TapParser {
+	return &TapParser{Plan: -1}
+}
+
+// Parses one or more lines of output from a single statement that took elapsed time.
+func (p *TapParser) Feed(output string, elapsed time.Duration) {
+	start := len(p.Assertions)
+	for _, line := range strings.Split(output, "\n") {
+		p.Lines = append(p.Lines, line)
+		// Nested subtest output is kept as diagnostics of the parent assertion
+		if trimmed := strings.TrimSpace(line); trimmed != line || strings.HasPrefix(trimmed, "#") {
+			if n := len(p.Assertions); n > 0 && len(trimmed) > 0 {
+				last := &p.Assertions[n-1]
+				last.Diagnostics = append(last.Diagnostics, strings.TrimSpace(strings.TrimPrefix(trimmed, "#")))
+			}
+			continue
+		}
+		if m := planPattern.FindStringSubmatch(line); m != nil {
+			p.Plan, _ = strconv.Atoi(m[1])
+			continue
+		}
+		m := assertionPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		a := Assertion{
+			Ok:          len(m[1]) == 0,
+			Description: m[3],
+			Directive:   strings.ToUpper(m[4]),
+			Reason:      m[5],
+		}
+		if n, err := strconv.Atoi(m[2]); err == nil {
+			a.Number = n
+		} else {
+			a.Number = len(p.Assertions) + 1
+		}
+		p.Assertions = append(p.Assertions, a)
+	}
+	// Attribute statement duration evenly to all assertions it emitted
+	if n := len(p.Assertions) - start; n > 0 {
+		for i := start; i < len(p.Assertions); i++ {
+			p.Assertions[i].Duration = elapsed / time.Duration(n)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTapParser(t *testing.T) {
+	t.Run("parses plan and assertions", func(t *testing.T) {
+		p := NewTapParser()
+		p.Feed("1..4", time.Millisecond)
+		p.Feed("ok 1 - has table", 2*time.Millisecond)
+		p.Feed("not ok 2 - has column\n# Failed test 2: \"has column\"\n#     Column public.t.c should exist", 4*time.Millisecond)
+		p.Feed("ok 3 # SKIP no pg_cron\nnot ok 4 - later # TODO not implemented", 6*time.Millisecond)
+		// Check result
+		assert.Equal(t, 4, p.Plan)
+		assert.Equal(t, []Assertion{{
+			Number:      1,
+			Description: "has table",
+			Ok:          true,
+			Duration:    2 * time.Millisecond,
+		}, {
+			Number:      2,
+			Description: "has column",
+			Diagnostics: []string{`Failed test 2: "has column"`, "Column public.t.c should exist"},
+			Duration:    4 * time.Millisecond,
+		}, {
+			Number:    3,
+			Ok:        true,
+			Directive: DirectiveSkip,
+			Reason:    "no pg_cron",
+			Duration:  3 * time.Millisecond,
+		}, {
+			Number:      4,
+			Description: "later",
+			Directive:   DirectiveTodo,
+			Reason:      "not implemented",
+			Duration:    3 * time.Millisecond,
+		}}, p.Assertions)
+		assert.True(t, p.Assertions[1].Failed())
+		assert.False(t, p.Assertions[3].Failed())
+	})
+
+	t.Run("ignores unknown lines", func(t *testing.T) {
+		p := NewTapParser()
+		p.Feed("hello world", time.Millisecond)
+		assert.Equal(t, -1, p.Plan)
+		assert.Empty(t, p.Assertions)
+		assert.Equal(t, []string{"hello world"}, p.Lines)
+	})
+}
@@ -0,0 +1,8 @@
+-- Ref: https://github.com/okbob/plpgsql_check#profiler
+SELECT n.nspname || '.' || p.proname, p.oid::regprocedure::text, t.lineno, coalesce(t.exec_cmds, 0)
+FROM pg_catalog.pg_proc p
+JOIN pg_catalog.pg_namespace n ON p.pronamespace = n.oid
+JOIN pg_catalog.pg_language l ON p.prolang = l.oid
+CROSS JOIN LATERAL plpgsql_profiler_function_tb(p.oid) t
+WHERE l.lanname = 'plpgsql' AND t.cmds_on_row > 0 AND NOT n.nspname LIKE ANY($1::text[])
+ORDER BY 2, 3;
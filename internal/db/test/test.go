@@ -4,19 +4,26 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/network"
 	"github.com/go-errors/errors"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v4"
 	"github.com/spf13/afero"
-	"github.com/spf13/viper"
+	"github.com/supabase/cli/internal/db/lint"
+	"github.com/supabase/cli/internal/migration/up"
 	"github.com/supabase/cli/internal/utils"
-	cliConfig "github.com/supabase/cli/pkg/config"
+	"github.com/supabase/cli/pkg/migration"
+	"github.com/supabase/cli/pkg/parser"
 )
 
 const (
@@ -24,76 +31,357 @@ const (
 	DISABLE_PGTAP = "drop extension if exists pgtap"
 )
 
-func Run(ctx context.Context, testFiles []string, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
-	// Build test command
-	cmd := []string{"pg_prove", "--ext", ".pg", "--ext", ".sql", "-r"}
-	for _, fp := range testFiles {
-		relPath, err := filepath.Rel(utils.DbTestsDir, fp)
-		if err != nil {
-			return errors.Errorf("failed to resolve relative path: %w", err)
-		}
-		cmd = append(cmd, relPath)
+var (
+	// Test files run in their own transaction so top level transaction control is skipped
+	txControlPattern = regexp.MustCompile(`(?i)^(begin|start\s+transaction|commit|end|rollback|abort)(\s+(work|transaction))?\s*;?$`)
+
+	ErrTestsFailed = errors.New("Some tests failed.")
+)
+
+func Run(ctx context.Context, testFiles []string, jobs uint, reporter string, isolate, coverage bool, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
+	files, err := ListTestFiles(testFiles, fsys)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return errors.New("No test files found in " + utils.Bold(utils.DbTestsDir))
+	}
+	var profiler *Profiler
+	if coverage {
+		profiler = NewProfiler()
+	}
+	start := time.Now()
+	var results []FileResult
+	if isolate {
+		results, err = RunIsolated(ctx, files, jobs, profiler, fsys, options...)
+	} else {
+		results, err = runShared(ctx, files, jobs, profiler, config, fsys, options...)
+	}
+	if err != nil {
+		return err
+	}
+	if err := Report(results, reporter, os.Stdout); err != nil {
+		return err
 	}
-	if viper.GetBool("DEBUG") {
-		cmd = append(cmd, "--verbose")
+	if profiler != nil {
+		if err := profiler.WriteReports(fsys); err != nil {
+			return err
+		}
 	}
-	// Mount tests directory into container as working directory
-	srcPath, err := filepath.Abs(utils.DbTestsDir)
+	printMatrix(results, os.Stderr)
+	return printSummary(results, time.Since(start), os.Stderr)
+}
+
+// Runs all test files on the same database, using one connection per job.
+func runShared(ctx context.Context, files []string, jobs uint, profiler *Profiler, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) ([]FileResult, error) {
+	s, err := openShared(ctx, min(int(jobs), len(files)), config, fsys, options...)
 	if err != nil {
-		return errors.Errorf("failed to resolve absolute path: %w", err)
+		return nil, err
+	}
+	defer s.Close()
+	if profiler != nil {
+		created, err := setupProfiler(ctx, s.conns)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			defer func() {
+				if _, err := s.conns[0].Exec(context.Background(), DISABLE_PLPGSQL); err != nil {
+					fmt.Fprintln(os.Stderr, "failed to disable plpgsql_check:", err)
+				}
+			}()
+		}
 	}
-	dstPath := "/tmp"
-	binds := []string{fmt.Sprintf("%s:%s:ro", srcPath, dstPath)}
+	results := RunTests(ctx, files, s.Runners())
+	if profiler != nil {
+		// Profiles in shared memory are visible to all connections so they are not summed
+		for _, c := range s.conns {
+			if err := profiler.Collect(ctx, c, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return results, nil
+}
+
+// Connections to a shared database with pgTAP enabled.
+type sharedSession struct {
+	conns []*pgx.Conn
+	// Drops pgTAP on close if it did not exist before
+	dropPgtap bool
+	fsys      afero.Fs
+}
+
+func openShared(ctx context.Context, jobs int, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) (*sharedSession, error) {
 	// Enable pgTAP if not already exists
 	alreadyExists := false
-	options = append(options, func(cc *pgx.ConnConfig) {
+	opts := append([]func(*pgx.ConnConfig){func(cc *pgx.ConnConfig) {
 		cc.OnNotice = func(pc *pgconn.PgConn, n *pgconn.Notice) {
 			alreadyExists = n.Code == pgerrcode.DuplicateObject
 		}
-	})
-	conn, err := utils.ConnectByConfig(ctx, config, options...)
+	}}, options...)
+	conn, err := utils.ConnectByConfig(ctx, config, opts...)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer conn.Close(context.Background())
+	s := &sharedSession{conns: []*pgx.Conn{conn}, fsys: fsys}
 	if _, err := conn.Exec(ctx, ENABLE_PGTAP); err != nil {
-		return errors.Errorf("failed to enable pgTAP: %w", err)
+		s.Close()
+		return nil, errors.Errorf("failed to enable pgTAP: %w", err)
+	}
+	s.dropPgtap = !alreadyExists
+	for i := 1; i < jobs; i++ {
+		worker, err := utils.ConnectByConfigStream(ctx, config, io.Discard, options...)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.conns = append(s.conns, worker)
+	}
+	return s, nil
+}
+
+func (s *sharedSession) Runners() []Runner {
+	runners := make([]Runner, len(s.conns))
+	for i, c := range s.conns {
+		runners[i] = newRunner(c, s.fsys)
+	}
+	return runners
+}
+
+// Applies new local migrations. Edited migrations cannot be reverted on a shared database.
+func (s *sharedSession) Migrate(ctx context.Context, edited bool) error {
+	if edited {
+		return errors.Errorf("Edited migrations are not applied. Run %s or use %s to reset the database.", utils.Aqua("supabase db reset"), utils.Aqua("--isolate"))
+	}
+	return applyPending(ctx, s.conns[0], s.fsys)
+}
+
+func (s *sharedSession) Close() {
+	if s.dropPgtap {
+		if _, err := s.conns[0].Exec(context.Background(), DISABLE_PGTAP); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to disable pgTAP:", err)
+		}
+	}
+	for _, c := range s.conns {
+		c.Close(context.Background())
+	}
+}
+
+func applyPending(ctx context.Context, conn *pgx.Conn, fsys afero.Fs) error {
+	pending, err := up.GetPendingMigrations(ctx, false, conn, fsys)
+	if err != nil {
+		return err
+	}
+	return migration.ApplyMigrations(ctx, pending, conn, afero.NewIOFS(fsys))
+}
+
+// Enables plpgsql_check profiler on all connections. Returns true if the extension is
+// created by this function.
+func setupProfiler(ctx context.Context, conns []*pgx.Conn) (bool, error) {
+	conn := conns[0]
+	var exists bool
+	if err := conn.QueryRow(ctx, CHECK_PLPGSQL).Scan(&exists); err != nil {
+		return false, errors.Errorf("failed to check plpgsql_check: %w", err)
+	}
+	if !exists {
+		if _, err := conn.Exec(ctx, lint.ENABLE_PGSQL_CHECK); err != nil {
+			return false, errors.Errorf("failed to enable plpgsql_check: %w", err)
+		}
+	}
+	// Discard profiles from previous runs
+	if _, err := conn.Exec(ctx, RESET_PROFILER); err != nil {
+		return !exists, errors.Errorf("failed to reset profiler: %w", err)
+	}
+	for _, c := range conns {
+		if err := EnableProfiler(ctx, c); err != nil {
+			return !exists, err
+		}
+	}
+	return !exists, nil
+}
+
+// Resolves paths to a sorted list of test files. Directories are searched recursively.
+func ListTestFiles(paths []string, fsys afero.Fs) ([]string, error) {
+	if len(paths) == 0 {
+		paths = []string{utils.DbTestsDir}
+	}
+	var result []string
+	for _, p := range paths {
+		if err := afero.Walk(fsys, p, func(path string, info fs.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			// Explicitly specified files are always included
+			if ext := filepath.Ext(path); path == p || ext == ".sql" || ext == ".pg" || isPolicyFile(path) {
+				result = append(result, path)
+			}
+			return nil
+		}); err != nil {
+			return nil, errors.Errorf("failed to list test files: %w", err)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// Runs a test file, where index is the position of path in the list of test files.
+type Runner func(ctx context.Context, index int, path string) FileResult
+
+func newRunner(conn *pgx.Conn, fsys afero.Fs) Runner {
+	return func(ctx context.Context, _ int, path string) FileResult {
+		return RunFile(ctx, path, conn, fsys)
+	}
+}
+
+// Runs each test file on the next available runner.
+func RunTests(ctx context.Context, files []string, runners []Runner) []FileResult {
+	results := make([]FileResult, len(files))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for _, r := range runners {
+		wg.Add(1)
+		go func(run Runner) {
+			defer wg.Done()
+			for i := range queue {
+				results[i] = run(ctx, i, files[i])
+			}
+		}(r)
+	}
+	for i := range files {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	return results
+}
+
+type FileResult struct {
+	Path       string      `json:"path"`
+	Plan       int         `json:"plan"`
+	Assertions []Assertion `json:"assertions"`
+	// Outcome of each case in a policy file
+	Policies []PolicyResult `json:"policies,omitempty"`
+	Output   []string       `json:"-"`
+	Duration time.Duration  `json:"duration"`
+	// Reason for failing the test file, empty if passed
+	Error string `json:"error,omitempty"`
+}
+
+func (r FileResult) Passed() bool {
+	return len(r.Error) == 0
+}
+
+func (r FileResult) Failures() (count int) {
+	for _, a := range r.Assertions {
+		if a.Failed() {
+			count++
+		}
+	}
+	return count
+}
+
+// Executes all statements in a test file within a transaction that is always rolled back.
+func RunFile(ctx context.Context, path string, conn *pgx.Conn, fsys afero.Fs) FileResult {
+	return runFile(ctx, path, conn, true, fsys)
+}
+
+func runFile(ctx context.Context, path string, conn *pgx.Conn, rollback bool, fsys afero.Fs) FileResult {
+	start := time.Now()
+	p := NewTapParser()
+	var policies []PolicyResult
+	var err error
+	if isPolicyFile(path) {
+		// Policy cases are always rolled back
+		policies, err = execPolicyFile(ctx, path, conn, p, fsys)
+	} else {
+		err = execFile(ctx, path, conn, rollback, p, fsys)
 	}
-	if !alreadyExists {
+	result := FileResult{
+		Path:       path,
+		Plan:       p.Plan,
+		Assertions: p.Assertions,
+		Policies:   policies,
+		Output:     p.Lines,
+		Duration:   time.Since(start),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	} else if result.Plan < 0 {
+		result.Error = "No plan found in TAP output"
+	} else if result.Plan != len(result.Assertions) {
+		result.Error = fmt.Sprintf("Bad plan. You planned %d tests but ran %d.", result.Plan, len(result.Assertions))
+	} else if n := result.Failures(); n > 0 {
+		result.Error = fmt.Sprintf("Failed %d/%d subtests", n, len(result.Assertions))
+	}
+	return result
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+func execFile(ctx context.Context, path string, conn *pgx.Conn, rollback bool, p *TapParser, fsys afero.Fs) error {
+	sql, err := fsys.Open(path)
+	if err != nil {
+		return errors.Errorf("failed to open test file: %w", err)
+	}
+	defer sql.Close()
+	lines, err := parser.SplitAndTrim(sql)
+	if err != nil {
+		return err
+	}
+	var q querier = conn
+	if rollback {
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return errors.Errorf("failed to begin transaction: %w", err)
+		}
+		// Always rollback since tests should not have side effects
 		defer func() {
-			if _, err := conn.Exec(ctx, DISABLE_PGTAP); err != nil {
-				fmt.Fprintln(os.Stderr, "failed to disable pgTAP:", err)
+			if err := tx.Rollback(context.Background()); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				fmt.Fprintln(os.Stderr, err)
 			}
 		}()
+		q = tx
 	}
-	// Use custom network when connecting to local database
-	// disable selinux via security-opt to allow pg-tap to work properly
-	hostConfig := container.HostConfig{Binds: binds, SecurityOpt: []string{"label:disable"}}
-	if utils.IsLocalDatabase(config) {
-		config.Host = utils.DbAliases[0]
-		config.Port = 5432
-	} else {
-		hostConfig.NetworkMode = network.NetworkHost
-	}
-	// Run pg_prove on volume mount
-	return utils.DockerRunOnceWithConfig(
-		ctx,
-		container.Config{
-			Image: cliConfig.PgProveImage,
-			Env: []string{
-				"PGHOST=" + config.Host,
-				fmt.Sprintf("PGPORT=%d", config.Port),
-				"PGUSER=" + config.User,
-				"PGPASSWORD=" + config.Password,
-				"PGDATABASE=" + config.Database,
-			},
-			Cmd:        cmd,
-			WorkingDir: dstPath,
-		},
-		hostConfig,
-		network.NetworkingConfig{},
-		"",
-		os.Stdout,
-		os.Stderr,
-	)
+	for i, line := range lines {
+		if rollback && txControlPattern.MatchString(line) {
+			continue
+		}
+		start := time.Now()
+		output, err := queryOutput(ctx, q, line)
+		if err != nil {
+			return errors.Errorf("%w\nAt statement %d: %s", err, i, line)
+		}
+		if len(output) > 0 {
+			p.Feed(strings.Join(output, "\n"), time.Since(start))
+		}
+	}
+	return nil
+}
+
+// Collects all text values returned by a statement, ie. TAP output of pgTAP functions.
+func queryOutput(ctx context.Context, q querier, sql string) ([]string, error) {
+	rows, err := q.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var output []string
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range values {
+			if s, ok := v.(string); ok {
+				output = append(output, s)
+			}
+		}
+	}
+	return output, rows.Err()
 }
@@ -1,19 +1,17 @@
 package test
 
 import (
+	"bytes"
 	"context"
-	"errors"
+	"path/filepath"
 	"testing"
 
-	"github.com/h2non/gock"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgerrcode"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"github.com/supabase/cli/internal/testing/apitest"
 	"github.com/supabase/cli/internal/utils"
-	"github.com/supabase/cli/pkg/config"
 	"github.com/supabase/cli/pkg/pgtest"
 )
 
@@ -25,36 +23,117 @@ var dbConfig = pgconn.Config{
 	Database: "postgres",
 }
 
+const testSql = `begin;
+select plan(1);
+select ok(true, 'works');
+select * from finish();
+rollback;`
+
 func TestRunCommand(t *testing.T) {
-	t.Run("runs tests with pg_prove", func(t *testing.T) {
+	testPath := filepath.Join(utils.DbTestsDir, "nested", "a_test.sql")
+
+	t.Run("runs tests with pgTAP", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, testPath, []byte(testSql), 0644))
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query(ENABLE_PGTAP).
+			Reply("CREATE EXTENSION").
+			Query("begin").Reply("BEGIN").
+			Query("select plan(1)").
+			Reply("SELECT 1", []interface{}{"1..1"}).
+			Query("select ok(true, 'works')").
+			Reply("SELECT 1", []interface{}{"ok 1 - works"}).
+			Query("select * from finish()").
+			Reply("SELECT 0").
+			Query("rollback").Reply("ROLLBACK").
+			Query(DISABLE_PGTAP).
+			Reply("DROP EXTENSION")
+		// Run test
+		err := Run(context.Background(), nil, 1, ReporterTap, false, false, dbConfig, fsys, conn.Intercept)
+		// Check error
+		assert.NoError(t, err)
+	})
+
+	t.Run("runs tests with coverage", func(t *testing.T) {
 		// Setup in-memory fs
 		fsys := afero.NewMemMapFs()
-		require.NoError(t, utils.WriteConfig(fsys, false))
+		require.NoError(t, afero.WriteFile(fsys, testPath, []byte(testSql), 0644))
 		// Setup mock postgres
 		conn := pgtest.NewConn()
 		defer conn.Close(t)
 		conn.Query(ENABLE_PGTAP).
 			Reply("CREATE EXTENSION").
+			Query(CHECK_PLPGSQL).
+			Reply("SELECT 1", []interface{}{true}).
+			Query(RESET_PROFILER).
+			Reply("SELECT 1", []interface{}{""}).
+			Query(ENABLE_PROFILER).
+			Reply("SET").
+			Query("begin").Reply("BEGIN").
+			Query("select plan(1)").
+			Reply("SELECT 1", []interface{}{"1..1"}).
+			Query("select ok(true, 'works')").
+			Reply("SELECT 1", []interface{}{"ok 1 - works"}).
+			Query("select * from finish()").
+			Reply("SELECT 0").
+			Query("rollback").Reply("ROLLBACK").
+			Query(profileScript, profileExcludes).
+			Reply("SELECT 0").
 			Query(DISABLE_PGTAP).
 			Reply("DROP EXTENSION")
-		// Setup mock docker
-		require.NoError(t, apitest.MockDocker(utils.Docker))
-		defer gock.OffAll()
-		containerId := "test-pg-prove"
-		apitest.MockDockerStart(utils.Docker, utils.GetRegistryImageUrl(config.PgProveImage), containerId)
-		require.NoError(t, apitest.MockDockerLogs(utils.Docker, containerId, "Result: SUCCESS"))
 		// Run test
-		err := Run(context.Background(), []string{"nested"}, dbConfig, fsys, conn.Intercept)
+		err := Run(context.Background(), nil, 1, ReporterTap, false, true, dbConfig, fsys, conn.Intercept)
 		// Check error
 		assert.NoError(t, err)
+		exists, err := afero.Exists(fsys, filepath.Join(utils.DbCoverageDir, LCOV_FILENAME))
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("throws error on failed assertion", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, testPath, []byte(testSql), 0644))
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query(ENABLE_PGTAP).
+			Reply("CREATE EXTENSION").
+			Query("begin").Reply("BEGIN").
+			Query("select plan(1)").
+			Reply("SELECT 1", []interface{}{"1..1"}).
+			Query("select ok(true, 'works')").
+			Reply("SELECT 1", []interface{}{"not ok 1 - works\n# Failed test 1: \"works\""}).
+			Query("select * from finish()").
+			Reply("SELECT 1", []interface{}{"# Looks like you failed 1 test of 1"}).
+			Query("rollback").Reply("ROLLBACK").
+			Query(DISABLE_PGTAP).
+			Reply("DROP EXTENSION")
+		// Run test
+		err := Run(context.Background(), []string{testPath}, 1, ReporterTap, false, false, dbConfig, fsys, conn.Intercept)
+		// Check error
+		assert.ErrorIs(t, err, ErrTestsFailed)
+	})
+
+	t.Run("throws error on missing tests", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, fsys.MkdirAll(utils.DbTestsDir, 0755))
+		// Run test
+		err := Run(context.Background(), nil, 1, ReporterTap, false, false, dbConfig, fsys)
+		// Check error
+		assert.ErrorContains(t, err, "No test files found")
 	})
 
 	t.Run("throws error on connect failure", func(t *testing.T) {
 		// Setup in-memory fs
 		fsys := afero.NewMemMapFs()
-		require.NoError(t, utils.WriteConfig(fsys, false))
+		require.NoError(t, afero.WriteFile(fsys, testPath, []byte(testSql), 0644))
 		// Run test
-		err := Run(context.Background(), nil, dbConfig, fsys)
+		err := Run(context.Background(), nil, 1, ReporterTap, false, false, dbConfig, fsys)
 		// Check error
 		assert.ErrorContains(t, err, "failed to connect to postgres")
 	})
@@ -62,40 +141,111 @@ func TestRunCommand(t *testing.T) {
 	t.Run("throws error on pgtap failure", func(t *testing.T) {
 		// Setup in-memory fs
 		fsys := afero.NewMemMapFs()
-		require.NoError(t, utils.WriteConfig(fsys, false))
+		require.NoError(t, afero.WriteFile(fsys, testPath, []byte(testSql), 0644))
 		// Setup mock postgres
 		conn := pgtest.NewConn()
 		defer conn.Close(t)
 		conn.Query(ENABLE_PGTAP).
 			ReplyError(pgerrcode.DuplicateObject, `extension "pgtap" already exists, skipping`)
 		// Run test
-		err := Run(context.Background(), nil, dbConfig, fsys, conn.Intercept)
+		err := Run(context.Background(), nil, 1, ReporterTap, false, false, dbConfig, fsys, conn.Intercept)
 		// Check error
 		assert.ErrorContains(t, err, "failed to enable pgTAP")
 	})
+}
 
-	t.Run("throws error on network failure", func(t *testing.T) {
-		errNetwork := errors.New("network error")
+func TestRunFile(t *testing.T) {
+	t.Run("stops on statement error", func(t *testing.T) {
 		// Setup in-memory fs
 		fsys := afero.NewMemMapFs()
-		require.NoError(t, utils.WriteConfig(fsys, false))
+		require.NoError(t, afero.WriteFile(fsys, "a_test.sql", []byte("select plan(1);\nselect missing();"), 0644))
 		// Setup mock postgres
 		conn := pgtest.NewConn()
 		defer conn.Close(t)
-		conn.Query(ENABLE_PGTAP).
-			Reply("CREATE EXTENSION").
-			Query(DISABLE_PGTAP).
-			Reply("DROP EXTENSION")
-		// Setup mock docker
-		require.NoError(t, apitest.MockDocker(utils.Docker))
-		defer gock.OffAll()
-		gock.New(utils.Docker.DaemonHost()).
-			Get("/v" + utils.Docker.ClientVersion() + "/images/" + utils.GetRegistryImageUrl(config.PgProveImage) + "/json").
-			ReplyError(errNetwork)
+		conn.Query("begin").Reply("BEGIN").
+			Query("select plan(1)").
+			Reply("SELECT 1", []interface{}{"1..1"}).
+			Query("select missing()").
+			ReplyError(pgerrcode.UndefinedFunction, "function missing() does not exist").
+			Query("rollback").Reply("ROLLBACK")
+		// Run test
+		result := RunFile(context.Background(), "a_test.sql", conn.MockClient(t), fsys)
+		// Check error
+		assert.False(t, result.Passed())
+		assert.Contains(t, result.Error, "function missing() does not exist")
+		assert.Contains(t, result.Error, "At statement 1: select missing()")
+	})
+
+	t.Run("throws error on bad plan", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, "a_test.sql", []byte("select plan(2);\nselect pass();"), 0644))
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query("begin").Reply("BEGIN").
+			Query("select plan(2)").
+			Reply("SELECT 1", []interface{}{"1..2"}).
+			Query("select pass()").
+			Reply("SELECT 1", []interface{}{"ok 1"}).
+			Query("rollback").Reply("ROLLBACK")
 		// Run test
-		err := Run(context.Background(), nil, dbConfig, fsys, conn.Intercept)
+		result := RunFile(context.Background(), "a_test.sql", conn.MockClient(t), fsys)
 		// Check error
-		assert.ErrorIs(t, err, errNetwork)
-		assert.Empty(t, apitest.ListUnmatchedRequests())
+		assert.Equal(t, "Bad plan. You planned 2 tests but ran 1.", result.Error)
+	})
+}
+
+func TestListTestFiles(t *testing.T) {
+	// Setup in-memory fs
+	fsys := afero.NewMemMapFs()
+	for _, name := range []string{"b_test.sql", "nested/a_test.pg", "README.md"} {
+		require.NoError(t, afero.WriteFile(fsys, filepath.Join(utils.DbTestsDir, name), nil, 0644))
+	}
+	// Run test
+	files, err := ListTestFiles(nil, fsys)
+	// Check error
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(utils.DbTestsDir, "b_test.sql"),
+		filepath.Join(utils.DbTestsDir, "nested", "a_test.pg"),
+	}, files)
+}
+
+func TestReport(t *testing.T) {
+	results := []FileResult{{
+		Path:       "a_test.sql",
+		Plan:       2,
+		Assertions: []Assertion{{Number: 1, Description: "works", Ok: true}, {Number: 2, Ok: false, Diagnostics: []string{"have: 1", "want: 2"}}},
+		Output:     []string{"1..2", "ok 1 - works", "not ok 2", "# have: 1", "# want: 2"},
+		Error:      "Failed 1/2 subtests",
+	}}
+
+	t.Run("reports tap", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, Report(results, ReporterTap, &out))
+		assert.Equal(t, `TAP version 14
+# Subtest: a_test.sql
+    1..2
+    ok 1 - works
+    not ok 2
+    # have: 1
+    # want: 2
+not ok 1 - a_test.sql # Failed 1/2 subtests
+1..1
+`, out.String())
+	})
+
+	t.Run("reports junit", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, Report(results, ReporterJunit, &out))
+		assert.Contains(t, out.String(), `<testsuite name="a_test" tests="2" failures="1" errors="0" skipped="0" time="0" file="a_test.sql">`)
+		assert.Contains(t, out.String(), `<failure message="not ok 2">have: 1&#xA;want: 2</failure>`)
+	})
+
+	t.Run("reports json", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, Report(results, ReporterJson, &out))
+		assert.Contains(t, out.String(), `"error": "Failed 1/2 subtests"`)
 	})
 }
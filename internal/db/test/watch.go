@@ -0,0 +1,333 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/go-errors/errors"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/muesli/reflow/wrap"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/utils"
+)
+
+// Interval between scans of the watched directories
+var pollInterval = 500 * time.Millisecond
+
+// A long lived database session that test files can be run on repeatedly.
+type session interface {
+	Runners() []Runner
+	// Applies changes to local migrations, where edited is true if any existing migration changed
+	Migrate(ctx context.Context, edited bool) error
+	Close()
+}
+
+// Re-runs changed test files, or the whole suite when local migrations change, until
+// the context is cancelled.
+func Watch(ctx context.Context, testFiles []string, jobs uint, isolate bool, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
+	var s session
+	var err error
+	if isolate {
+		s, err = openIsolated(ctx, jobs, nil, fsys, options...)
+	} else {
+		s, err = openShared(ctx, max(int(jobs), 1), config, fsys, options...)
+	}
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	ctx, cancel := context.WithCancel(ctx)
+	p := utils.NewProgram(newWatchModel(cancel))
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- watchTests(ctx, p, testFiles, s, fsys)
+		p.Quit()
+	}()
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return <-errCh
+}
+
+func watchTests(ctx context.Context, p utils.Program, testFiles []string, s session, fsys afero.Fs) error {
+	w := newWatcher(fsys, utils.DbTestsDir, utils.MigrationsDir)
+	if _, _, err := w.Changes(); err != nil {
+		return err
+	}
+	files, err := ListTestFiles(testFiles, fsys)
+	if err != nil {
+		return err
+	}
+	runFiles(ctx, p, files, s)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		changed, edited, err := w.Changes()
+		if err != nil {
+			return err
+		}
+		if len(changed) == 0 {
+			continue
+		}
+		if files, err = ListTestFiles(testFiles, fsys); err != nil {
+			return err
+		}
+		p.Send(listMsg(files))
+		var migrations, tests []string
+		for _, path := range changed {
+			if isMigration(path) {
+				migrations = append(migrations, path)
+			} else if utils.SliceContains(files, path) {
+				tests = append(tests, path)
+			}
+		}
+		if len(migrations) > 0 {
+			p.Send(utils.StatusMsg("Applying migrations..."))
+			if err := s.Migrate(ctx, edited); err != nil {
+				p.Send(warningMsg(err.Error()))
+			}
+			// Schema changes may affect any test file
+			tests = files
+		}
+		runFiles(ctx, p, tests, s)
+	}
+}
+
+// Runs test files on the session, sending each result to the live view as it completes.
+func runFiles(ctx context.Context, p utils.Program, files []string, s session) {
+	if len(files) == 0 {
+		return
+	}
+	p.Send(runStartMsg(files))
+	// Fake programs update the model synchronously so sends must not be concurrent
+	var mu sync.Mutex
+	runners := s.Runners()
+	for i, run := range runners {
+		run := run
+		runners[i] = func(ctx context.Context, index int, path string) FileResult {
+			result := run(ctx, index, path)
+			mu.Lock()
+			defer mu.Unlock()
+			p.Send(fileResultMsg(result))
+			return result
+		}
+	}
+	start := time.Now()
+	RunTests(ctx, files, runners)
+	p.Send(runDoneMsg(time.Since(start)))
+}
+
+// Detects changes to files by polling their modification time.
+type watcher struct {
+	fsys     afero.Fs
+	dirs     []string
+	modified map[string]time.Time
+}
+
+func newWatcher(fsys afero.Fs, dirs ...string) *watcher {
+	return &watcher{fsys: fsys, dirs: dirs}
+}
+
+// Returns the sorted paths of files added or modified since the last call. Edited is
+// true if any previously seen migration is modified or removed.
+func (w *watcher) Changes() (changed []string, edited bool, err error) {
+	current := map[string]time.Time{}
+	for _, dir := range w.dirs {
+		if err := afero.Walk(w.fsys, dir, func(path string, info fs.FileInfo, err error) error {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				current[path] = info.ModTime()
+			}
+			return nil
+		}); err != nil {
+			return nil, false, errors.Errorf("failed to watch directory: %w", err)
+		}
+	}
+	// The first scan only records the initial state
+	if w.modified != nil {
+		for path, mtime := range current {
+			prev, ok := w.modified[path]
+			if !ok || !prev.Equal(mtime) {
+				changed = append(changed, path)
+			}
+			if ok && !prev.Equal(mtime) && isMigration(path) {
+				edited = true
+			}
+		}
+		for path := range w.modified {
+			if _, ok := current[path]; !ok && isMigration(path) {
+				changed = append(changed, path)
+				edited = true
+			}
+		}
+	}
+	w.modified = current
+	sort.Strings(changed)
+	return changed, edited, nil
+}
+
+func isMigration(path string) bool {
+	return strings.HasPrefix(path, utils.MigrationsDir+string(filepath.Separator))
+}
+
+type (
+	// Test files that currently exist
+	listMsg []string
+	// Test files that started running
+	runStartMsg   []string
+	fileResultMsg FileResult
+	runDoneMsg    time.Duration
+	// Shown until the next change is detected
+	warningMsg string
+)
+
+func (m warningMsg) String() string {
+	return string(m)
+}
+
+// Prints results as plain text when stdout is not a terminal.
+func (m fileResultMsg) String() string {
+	return formatResult(FileResult(m))
+}
+
+func (m runDoneMsg) String() string {
+	return fmt.Sprintf("Finished in %.2fs. Watching for changes...", time.Duration(m).Seconds())
+}
+
+var (
+	passStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#3ECF8E"))
+	failStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	dimStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+)
+
+type watchModel struct {
+	cancel  context.CancelFunc
+	spinner spinner.Model
+	status  string
+	warning string
+	// Latest result of each test file, or nil if it is pending
+	results map[string]*FileResult
+	running int
+	width   int
+}
+
+func newWatchModel(cancel context.CancelFunc) watchModel {
+	return watchModel{
+		cancel: cancel,
+		spinner: spinner.New(
+			spinner.WithSpinner(spinner.Dot),
+			spinner.WithStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("205"))),
+		),
+		results: map[string]*FileResult{},
+	}
+}
+
+func (m watchModel) Init() tea.Cmd {
+	return m.spinner.Tick
+}
+
+func (m watchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q":
+			if m.cancel != nil {
+				m.cancel()
+			}
+			return m, tea.Quit
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	case utils.StatusMsg:
+		m.status = string(msg)
+	case listMsg:
+		results := make(map[string]*FileResult, len(msg))
+		for _, path := range msg {
+			results[path] = m.results[path]
+		}
+		m.results = results
+		m.warning = ""
+	case warningMsg:
+		m.warning = string(msg)
+	case runStartMsg:
+		for _, path := range msg {
+			m.results[path] = nil
+		}
+		m.running = len(msg)
+		m.status = fmt.Sprintf("Running %d test files...", len(msg))
+	case fileResultMsg:
+		result := FileResult(msg)
+		m.results[result.Path] = &result
+		m.running--
+	case runDoneMsg:
+		m.running = 0
+		m.status = msg.String()
+	}
+	return m, nil
+}
+
+func (m watchModel) View() string {
+	var out strings.Builder
+	if m.running > 0 {
+		out.WriteString(m.spinner.View())
+	}
+	out.WriteString(m.status + "\n")
+	if len(m.warning) > 0 {
+		out.WriteString(utils.Yellow(m.warning) + "\n")
+	}
+	out.WriteString("\n")
+	paths := make([]string, 0, len(m.results))
+	for path := range m.results {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var tests, failed int
+	for _, path := range paths {
+		r := m.results[path]
+		if r == nil {
+			out.WriteString(dimStyle.Render("… "+path) + "\n")
+			continue
+		}
+		tests += len(r.Assertions)
+		if !r.Passed() {
+			failed++
+		}
+		out.WriteString(formatResult(*r) + "\n")
+	}
+	fmt.Fprintf(&out, "\nFiles=%d, Tests=%d, Failed=%d\n", len(paths), tests, failed)
+	out.WriteString(dimStyle.Render("Press q to quit."))
+	return wrap.String(out.String(), m.width)
+}
+
+func formatResult(r FileResult) string {
+	summary := fmt.Sprintf("%d/%d", len(r.Assertions)-r.Failures(), len(r.Assertions))
+	if r.Passed() {
+		return passStyle.Render("✔ "+r.Path) + " " + dimStyle.Render(fmt.Sprintf("%s %dms", summary, r.Duration.Milliseconds()))
+	}
+	reason, _, _ := strings.Cut(r.Error, "\n")
+	return failStyle.Render("✘ "+r.Path) + " " + dimStyle.Render(summary) + " " + reason
+}
@@ -0,0 +1,147 @@
+package test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/internal/utils"
+)
+
+func TestWatcher(t *testing.T) {
+	testPath := filepath.Join(utils.DbTestsDir, "a_test.sql")
+	migrationPath := filepath.Join(utils.MigrationsDir, "0_init.sql")
+	// Setup in-memory fs
+	fsys := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fsys, testPath, []byte{}, 0644))
+	require.NoError(t, afero.WriteFile(fsys, migrationPath, []byte{}, 0644))
+	w := newWatcher(fsys, utils.DbTestsDir, utils.MigrationsDir)
+	// Initial scan
+	changed, edited, err := w.Changes()
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+	assert.False(t, edited)
+
+	t.Run("detects modified test", func(t *testing.T) {
+		require.NoError(t, fsys.Chtimes(testPath, time.Now(), time.Now().Add(time.Second)))
+		// Run test
+		changed, edited, err := w.Changes()
+		// Check error
+		assert.NoError(t, err)
+		assert.Equal(t, []string{testPath}, changed)
+		assert.False(t, edited)
+	})
+
+	t.Run("detects new migration", func(t *testing.T) {
+		newPath := filepath.Join(utils.MigrationsDir, "1_table.sql")
+		require.NoError(t, afero.WriteFile(fsys, newPath, []byte{}, 0644))
+		// Run test
+		changed, edited, err := w.Changes()
+		// Check error
+		assert.NoError(t, err)
+		assert.Equal(t, []string{newPath}, changed)
+		assert.False(t, edited)
+	})
+
+	t.Run("detects edited migration", func(t *testing.T) {
+		require.NoError(t, fsys.Remove(migrationPath))
+		// Run test
+		changed, edited, err := w.Changes()
+		// Check error
+		assert.NoError(t, err)
+		assert.Equal(t, []string{migrationPath}, changed)
+		assert.True(t, edited)
+	})
+}
+
+type mockSession struct {
+	ran      chan []string
+	migrated chan bool
+	mu       sync.Mutex
+	batch    []string
+}
+
+func (s *mockSession) Runners() []Runner {
+	return []Runner{func(ctx context.Context, index int, path string) FileResult {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.batch = append(s.batch, path)
+		return FileResult{Path: path, Plan: 0}
+	}}
+}
+
+func (s *mockSession) Migrate(ctx context.Context, edited bool) error {
+	s.migrated <- edited
+	return nil
+}
+
+func (s *mockSession) Close() {}
+
+type mockProgram struct {
+	session *mockSession
+}
+
+func (p mockProgram) Start() error { return nil }
+func (p mockProgram) Quit()        {}
+
+func (p mockProgram) Send(msg tea.Msg) {
+	if _, ok := msg.(runDoneMsg); ok {
+		p.session.mu.Lock()
+		batch := p.session.batch
+		p.session.batch = nil
+		p.session.mu.Unlock()
+		p.session.ran <- batch
+	}
+}
+
+func TestWatchTests(t *testing.T) {
+	pollInterval = time.Millisecond
+	testPath := filepath.Join(utils.DbTestsDir, "a_test.sql")
+	otherPath := filepath.Join(utils.DbTestsDir, "b_test.sql")
+	migrationPath := filepath.Join(utils.MigrationsDir, "0_init.sql")
+	// Setup in-memory fs
+	fsys := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fsys, testPath, []byte{}, 0644))
+	require.NoError(t, afero.WriteFile(fsys, otherPath, []byte{}, 0644))
+	require.NoError(t, afero.WriteFile(fsys, migrationPath, []byte{}, 0644))
+	s := &mockSession{ran: make(chan []string), migrated: make(chan bool, 1)}
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- watchTests(ctx, mockProgram{session: s}, nil, s, fsys)
+	}()
+	// Runs all tests initially
+	assert.Equal(t, []string{testPath, otherPath}, <-s.ran)
+	// Re-runs changed test file only
+	require.NoError(t, fsys.Chtimes(otherPath, time.Now(), time.Now().Add(time.Second)))
+	assert.Equal(t, []string{otherPath}, <-s.ran)
+	// Re-runs all tests on migration change
+	require.NoError(t, fsys.Chtimes(migrationPath, time.Now(), time.Now().Add(time.Second)))
+	assert.True(t, <-s.migrated)
+	assert.Equal(t, []string{testPath, otherPath}, <-s.ran)
+	cancel()
+	assert.NoError(t, <-errCh)
+}
+
+func TestWatchModel(t *testing.T) {
+	testPath := filepath.Join(utils.DbTestsDir, "a_test.sql")
+	var m tea.Model = newWatchModel(nil)
+	m, _ = m.Update(runStartMsg{testPath})
+	assert.Contains(t, m.View(), "Running 1 test files...")
+	assert.Contains(t, m.View(), "… "+testPath)
+	m, _ = m.Update(fileResultMsg{Path: testPath, Assertions: []Assertion{{Ok: true}}, Error: ""})
+	m, _ = m.Update(runDoneMsg(time.Second))
+	assert.Contains(t, m.View(), "Finished in 1.00s")
+	assert.Contains(t, m.View(), "Files=1, Tests=1, Failed=0")
+	m, _ = m.Update(warningMsg("migration failed"))
+	assert.Contains(t, m.View(), "migration failed")
+	m, _ = m.Update(listMsg{})
+	assert.NotContains(t, m.View(), "migration failed")
+	assert.Contains(t, m.View(), "Files=0, Tests=0, Failed=0")
+}
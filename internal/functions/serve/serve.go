@@ -96,27 +96,26 @@ func Run(ctx context.Context, envFilePath string, noVerifyJWT *bool, importMapPa
 }
 
 func ServeFunctions(ctx context.Context, envFilePath string, noVerifyJWT *bool, importMapPath string, dbUrl string, runtimeOption RuntimeOption, fsys afero.Fs) error {
-	// 1. Load default values
-	if envFilePath == "" {
-		if f, err := fsys.Stat(utils.FallbackEnvFilePath); err == nil && !f.IsDir() {
-			envFilePath = utils.FallbackEnvFilePath
-		}
-	} else if !filepath.IsAbs(envFilePath) {
-		envFilePath = filepath.Join(utils.CurrentDirAbs, envFilePath)
+	config, hostConfig, networkingConfig, err := NewContainerConfig(envFilePath, noVerifyJWT, importMapPath, dbUrl, runtimeOption, fsys)
+	if err != nil {
+		return err
 	}
+	_, err = utils.DockerStart(ctx, config, hostConfig, networkingConfig, utils.EdgeRuntimeId)
+	return err
+}
+
+// Returns the configs used by ServeFunctions to create the edge runtime container.
+func NewContainerConfig(envFilePath string, noVerifyJWT *bool, importMapPath string, dbUrl string, runtimeOption RuntimeOption, fsys afero.Fs) (container.Config, container.HostConfig, network.NetworkingConfig, error) {
+	var config container.Config
+	var hostConfig container.HostConfig
+	var networkingConfig network.NetworkingConfig
+	// 1. Load default values
+	envFilePath = ResolveEnvFile(envFilePath, fsys)
 	// 2. Parse user defined env
-	env, err := parseEnvFile(envFilePath, fsys)
+	env, err := ParseEnv(envFilePath, dbUrl, fsys)
 	if err != nil {
-		return err
+		return config, hostConfig, networkingConfig, err
 	}
-	env = append(env,
-		fmt.Sprintf("SUPABASE_URL=http://%s:8000", utils.KongAliases[0]),
-		"SUPABASE_ANON_KEY="+utils.Config.Auth.AnonKey,
-		"SUPABASE_SERVICE_ROLE_KEY="+utils.Config.Auth.ServiceRoleKey,
-		"SUPABASE_DB_URL="+dbUrl,
-		"SUPABASE_INTERNAL_JWT_SECRET="+utils.Config.Auth.JwtSecret,
-		fmt.Sprintf("SUPABASE_INTERNAL_HOST_PORT=%d", utils.Config.Api.Port),
-	)
 	if viper.GetBool("DEBUG") {
 		env = append(env, "SUPABASE_INTERNAL_DEBUG=true")
 	}
@@ -126,11 +125,11 @@ func ServeFunctions(ctx context.Context, envFilePath string, noVerifyJWT *bool,
 	// 3. Parse custom import map
 	cwd, err := os.Getwd()
 	if err != nil {
-		return errors.Errorf("failed to get working directory: %w", err)
+		return config, hostConfig, networkingConfig, errors.Errorf("failed to get working directory: %w", err)
 	}
 	binds, functionsConfigString, err := populatePerFunctionConfigs(cwd, importMapPath, noVerifyJWT, fsys)
 	if err != nil {
-		return err
+		return config, hostConfig, networkingConfig, err
 	}
 	env = append(env, "SUPABASE_INTERNAL_FUNCTIONS_CONFIG="+functionsConfigString)
 	// 4. Parse entrypoint script
@@ -160,34 +159,58 @@ EOF
 			HostPort: strconv.FormatUint(uint64(utils.Config.EdgeRuntime.InspectorPort), 10),
 		}}
 	}
-	// 6. Start container
-	_, err = utils.DockerStart(
-		ctx,
-		container.Config{
-			Image:        utils.Config.EdgeRuntime.Image,
-			Env:          env,
-			Entrypoint:   entrypoint,
-			ExposedPorts: exposedPorts,
-			WorkingDir:   utils.ToDockerPath(cwd),
-			// No tcp health check because edge runtime logs them as client connection error
-		},
-		container.HostConfig{
-			Binds:        binds,
-			PortBindings: portBindings,
-		},
-		network.NetworkingConfig{
-			EndpointsConfig: map[string]*network.EndpointSettings{
-				utils.NetId: {
-					Aliases: utils.EdgeRuntimeAliases,
-				},
+	// 6. Create container config
+	config = container.Config{
+		Image:        utils.Config.EdgeRuntime.Image,
+		Env:          env,
+		Entrypoint:   entrypoint,
+		ExposedPorts: exposedPorts,
+		WorkingDir:   utils.ToDockerPath(cwd),
+		// No tcp health check because edge runtime logs them as client connection error
+	}
+	hostConfig = container.HostConfig{
+		Binds:        binds,
+		PortBindings: portBindings,
+	}
+	networkingConfig = network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			utils.NetId: {
+				Aliases: utils.EdgeRuntimeAliases,
 			},
 		},
-		utils.EdgeRuntimeId,
-	)
-	return err
+	}
+	return config, hostConfig, networkingConfig, nil
+}
+
+// Returns the absolute path to env file, falling back to supabase/functions/.env if unspecified.
+func ResolveEnvFile(envFilePath string, fsys afero.Fs) string {
+	if envFilePath == "" {
+		if f, err := fsys.Stat(utils.FallbackEnvFilePath); err == nil && !f.IsDir() {
+			return utils.FallbackEnvFilePath
+		}
+	} else if !filepath.IsAbs(envFilePath) {
+		return filepath.Join(utils.CurrentDirAbs, envFilePath)
+	}
+	return envFilePath
+}
+
+// Combines user defined env with the env injected by local stack, ie. SUPABASE_URL and api keys.
+func ParseEnv(envFilePath, dbUrl string, fsys afero.Fs) ([]string, error) {
+	env, err := ParseEnvFile(envFilePath, fsys)
+	if err != nil {
+		return nil, err
+	}
+	return append(env,
+		fmt.Sprintf("SUPABASE_URL=http://%s:8000", utils.KongAliases[0]),
+		"SUPABASE_ANON_KEY="+utils.Config.Auth.AnonKey,
+		"SUPABASE_SERVICE_ROLE_KEY="+utils.Config.Auth.ServiceRoleKey,
+		"SUPABASE_DB_URL="+dbUrl,
+		"SUPABASE_INTERNAL_JWT_SECRET="+utils.Config.Auth.JwtSecret,
+		fmt.Sprintf("SUPABASE_INTERNAL_HOST_PORT=%d", utils.Config.Api.Port),
+	), nil
 }
 
-func parseEnvFile(envFilePath string, fsys afero.Fs) ([]string, error) {
+func ParseEnvFile(envFilePath string, fsys afero.Fs) ([]string, error) {
 	env := []string{}
 	if len(envFilePath) == 0 {
 		return env, nil
@@ -0,0 +1,242 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/go-errors/errors"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/functions/deploy"
+	"github.com/supabase/cli/internal/functions/serve"
+	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/pkg/junit"
+)
+
+const (
+	ReporterPretty = "pretty"
+	ReporterJunit  = "junit"
+
+	dockerJunitPath    = "/tmp/junit.xml"
+	dockerCoverageDir  = "/tmp/coverage"
+	lcovBoundary       = "--- supabase-test-lcov ---"
+	testFileSuffix     = "_test.ts"
+	functionEnvFile    = ".env"
+	coverageReportFile = "lcov.info"
+)
+
+var AllowedReporters = []string{
+	ReporterPretty,
+	ReporterJunit,
+}
+
+var ErrTestsFailed = errors.New("Some tests failed.")
+
+func Run(ctx context.Context, slugs []string, envFilePath, reporter string, coverage bool, fsys afero.Fs) error {
+	if err := utils.LoadConfigFS(fsys); err != nil {
+		return err
+	}
+	if err := utils.AssertSupabaseDbIsRunning(); err != nil {
+		return err
+	}
+	files, err := ListTestFiles(slugs, fsys)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return errors.New("No test files found in " + utils.Bold(utils.FunctionsDir))
+	}
+	envFilePath = serve.ResolveEnvFile(envFilePath, fsys)
+	// Use network alias because Deno cannot resolve `_` in hostname
+	dbUrl := fmt.Sprintf("postgresql://postgres:postgres@%s:5432/postgres", utils.DbAliases[0])
+	env, err := serve.ParseEnv(envFilePath, dbUrl, fsys)
+	if err != nil {
+		return err
+	}
+	report := junit.TestSuites{Name: "deno test"}
+	var lcov bytes.Buffer
+	var failed []string
+	for _, slug := range sortedKeys(files) {
+		fmt.Fprintln(os.Stderr, "Testing Function:", utils.Bold(slug))
+		result, err := runSlug(ctx, slug, files[slug], env, reporter == ReporterJunit, coverage, fsys)
+		if errors.Is(err, ErrTestsFailed) {
+			failed = append(failed, slug)
+		} else if err != nil {
+			return err
+		}
+		for _, s := range result.Junit.Suites {
+			report.Add(s)
+		}
+		lcov.WriteString(result.Lcov)
+	}
+	if reporter == ReporterJunit {
+		if err := report.Write(os.Stdout); err != nil {
+			return err
+		}
+	}
+	if coverage {
+		lcovPath := filepath.Join(utils.FunctionsCoverageDir, coverageReportFile)
+		if err := utils.WriteFile(lcovPath, lcov.Bytes(), fsys); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "Wrote coverage report to", utils.Bold(lcovPath))
+	}
+	if len(failed) > 0 {
+		fmt.Fprintln(os.Stderr, "Failed Functions:", strings.Join(failed, ", "))
+		return errors.New(ErrTestsFailed)
+	}
+	return nil
+}
+
+// Groups test files under supabase/functions by their top level directory, ie. slug.
+func ListTestFiles(slugs []string, fsys afero.Fs) (map[string][]string, error) {
+	result := map[string][]string{}
+	if err := afero.Walk(fsys, utils.FunctionsDir, func(path string, info fs.FileInfo, err error) error {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, testFileSuffix) {
+			return nil
+		}
+		rel, err := filepath.Rel(utils.FunctionsDir, path)
+		if err != nil {
+			return errors.Errorf("failed to resolve relative path: %w", err)
+		}
+		// Tests directly under functions directory are not associated with any slug
+		slug, _, found := strings.Cut(filepath.ToSlash(rel), "/")
+		if found && (len(slugs) == 0 || utils.SliceContains(slugs, slug)) {
+			result[slug] = append(result[slug], path)
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.Errorf("failed to list test files: %w", err)
+	}
+	for _, slug := range slugs {
+		if _, ok := result[slug]; !ok {
+			fmt.Fprintln(os.Stderr, "No test files found for Function:", slug)
+		}
+	}
+	return result, nil
+}
+
+type slugResult struct {
+	Junit junit.TestSuites
+	Lcov  string
+}
+
+// Runs deno test in the edge runtime image with per function env loaded from
+// supabase/functions/<slug>/.env
+func runSlug(ctx context.Context, slug string, files, env []string, withJunit, coverage bool, fsys afero.Fs) (slugResult, error) {
+	var result slugResult
+	functionDir := filepath.Join(utils.FunctionsDir, slug)
+	envPath := filepath.Join(functionDir, functionEnvFile)
+	if f, err := fsys.Stat(envPath); err == nil && !f.IsDir() {
+		funcEnv, err := serve.ParseEnvFile(envPath, fsys)
+		if err != nil {
+			return result, err
+		}
+		// Docker uses the last value of duplicate keys
+		env = append(env, funcEnv...)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return result, errors.Errorf("failed to get working directory: %w", err)
+	}
+	config, err := deploy.GetFunctionConfig([]string{slug}, "", nil, fsys)
+	if err != nil {
+		return result, err
+	}
+	importMap := config[slug].ImportMap
+	binds, err := deploy.GetBindMounts(cwd, utils.FunctionsDir, "", files[0], importMap, fsys)
+	if err != nil {
+		return result, err
+	}
+	args := []string{"deno", "test", "--allow-all"}
+	if ext := filepath.Ext(importMap); ext == ".json" || ext == ".jsonc" {
+		flag := "--import-map="
+		if base := filepath.Base(importMap); strings.HasPrefix(base, "deno.") {
+			flag = "--config="
+		}
+		args = append(args, flag+utils.ToDockerPath(absPath(cwd, importMap)))
+	}
+	if withJunit {
+		args = append(args, "--junit-path="+dockerJunitPath)
+	}
+	if coverage {
+		args = append(args, "--coverage="+dockerCoverageDir)
+	}
+	for _, f := range files {
+		args = append(args, utils.ToDockerPath(absPath(cwd, f)))
+	}
+	// Test output is redirected to stderr so that exit code and reports can be parsed from stdout
+	script := `"$@" >&2; echo $?`
+	if withJunit {
+		script += "; cat " + dockerJunitPath
+	}
+	if coverage {
+		script += fmt.Sprintf("; echo '%s'; deno coverage --lcov %s", lcovBoundary, dockerCoverageDir)
+	}
+	// Test failures are reported via stdout instead of exit code
+	script += "; exit 0"
+	var stdout bytes.Buffer
+	if err := utils.DockerRunOnceWithConfig(
+		ctx,
+		container.Config{
+			Image:      utils.Config.EdgeRuntime.Image,
+			Env:        env,
+			Entrypoint: []string{"sh", "-c", script, "sh"},
+			Cmd:        args,
+			WorkingDir: utils.ToDockerPath(absPath(cwd, functionDir)),
+		},
+		container.HostConfig{
+			Binds: binds,
+		},
+		network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				utils.NetId: {},
+			},
+		},
+		"",
+		&stdout,
+		os.Stderr,
+	); err != nil {
+		return result, err
+	}
+	status, output, _ := strings.Cut(stdout.String(), "\n")
+	output, result.Lcov, _ = strings.Cut(output, lcovBoundary+"\n")
+	if withJunit && len(strings.TrimSpace(output)) > 0 {
+		if result.Junit, err = junit.Parse(strings.NewReader(output)); err != nil {
+			return result, err
+		}
+	}
+	if code := strings.TrimSpace(status); code != "0" {
+		fmt.Fprintln(os.Stderr, "deno test exited with code:", code)
+		return result, errors.New(ErrTestsFailed)
+	}
+	return result, nil
+}
+
+func absPath(cwd, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(cwd, path)
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/h2non/gock"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/internal/testing/apitest"
+	"github.com/supabase/cli/internal/utils"
+)
+
+const junitXml = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="deno test" tests="1" failures="0" errors="0" time="0.01">
+    <testsuite name="./index_test.ts" tests="1" disabled="0" errors="0" failures="0">
+        <testcase name="returns greeting" classname="./index_test.ts" time="0.01" line="3" col="6">
+        </testcase>
+    </testsuite>
+</testsuites>
+`
+
+func TestListTestFiles(t *testing.T) {
+	// Setup in-memory fs
+	fsys := afero.NewMemMapFs()
+	for _, path := range []string{
+		filepath.Join(utils.FunctionsDir, "hello", "index.ts"),
+		filepath.Join(utils.FunctionsDir, "hello", "index_test.ts"),
+		filepath.Join(utils.FunctionsDir, "hello", "nested", "util_test.ts"),
+		filepath.Join(utils.FunctionsDir, "world", "index_test.ts"),
+		filepath.Join(utils.FunctionsDir, "root_test.ts"),
+	} {
+		require.NoError(t, afero.WriteFile(fsys, path, []byte{}, 0644))
+	}
+
+	t.Run("groups test files by slug", func(t *testing.T) {
+		files, err := ListTestFiles(nil, fsys)
+		// Check error
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]string{
+			"hello": {
+				filepath.Join(utils.FunctionsDir, "hello", "index_test.ts"),
+				filepath.Join(utils.FunctionsDir, "hello", "nested", "util_test.ts"),
+			},
+			"world": {
+				filepath.Join(utils.FunctionsDir, "world", "index_test.ts"),
+			},
+		}, files)
+	})
+
+	t.Run("filters by slug", func(t *testing.T) {
+		files, err := ListTestFiles([]string{"world", "missing"}, fsys)
+		// Check error
+		assert.NoError(t, err)
+		assert.Len(t, files, 1)
+		assert.Contains(t, files, "world")
+	})
+
+	t.Run("ignores missing functions dir", func(t *testing.T) {
+		files, err := ListTestFiles(nil, afero.NewMemMapFs())
+		// Check error
+		assert.NoError(t, err)
+		assert.Empty(t, files)
+	})
+}
+
+func TestRunCommand(t *testing.T) {
+	testPath := filepath.Join(utils.FunctionsDir, "hello", "index_test.ts")
+
+	setup := func(t *testing.T, stdout string) afero.Fs {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, utils.InitConfig(utils.InitParams{ProjectId: "test"}, fsys))
+		require.NoError(t, afero.WriteFile(fsys, testPath, []byte{}, 0644))
+		require.NoError(t, afero.WriteFile(fsys, filepath.Join(utils.FunctionsDir, "hello", ".env"), []byte("GREETING=hi"), 0644))
+		// Setup mock docker
+		require.NoError(t, apitest.MockDocker(utils.Docker))
+		gock.New(utils.Docker.DaemonHost()).
+			Get("/v" + utils.Docker.ClientVersion() + "/containers/supabase_db_test/json").
+			Reply(http.StatusOK).
+			JSON(types.ContainerJSON{})
+		containerId := "test-functions"
+		apitest.MockDockerStart(utils.Docker, utils.GetRegistryImageUrl(utils.Config.EdgeRuntime.Image), containerId)
+		require.NoError(t, apitest.MockDockerLogs(utils.Docker, containerId, stdout))
+		return fsys
+	}
+
+	t.Run("runs deno test with reports", func(t *testing.T) {
+		fsys := setup(t, "0\n"+junitXml+lcovBoundary+"\nTN:\nend_of_record\n")
+		defer gock.OffAll()
+		// Run test
+		err := Run(context.Background(), nil, "", ReporterJunit, true, fsys)
+		// Check error
+		assert.NoError(t, err)
+		assert.Empty(t, apitest.ListUnmatchedRequests())
+		lcov, err := afero.ReadFile(fsys, filepath.Join(utils.FunctionsCoverageDir, coverageReportFile))
+		assert.NoError(t, err)
+		assert.Equal(t, "TN:\nend_of_record\n", string(lcov))
+	})
+
+	t.Run("throws error on failed tests", func(t *testing.T) {
+		fsys := setup(t, "1\n")
+		defer gock.OffAll()
+		// Run test
+		err := Run(context.Background(), nil, "", ReporterPretty, false, fsys)
+		// Check error
+		assert.ErrorIs(t, err, ErrTestsFailed)
+		assert.Empty(t, apitest.ListUnmatchedRequests())
+	})
+
+	t.Run("throws error on missing tests", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, utils.InitConfig(utils.InitParams{ProjectId: "test"}, fsys))
+		// Setup mock docker
+		require.NoError(t, apitest.MockDocker(utils.Docker))
+		defer gock.OffAll()
+		gock.New(utils.Docker.DaemonHost()).
+			Get("/v" + utils.Docker.ClientVersion() + "/containers/supabase_db_test/json").
+			Reply(http.StatusOK).
+			JSON(types.ContainerJSON{})
+		// Run test
+		err := Run(context.Background(), nil, "", ReporterPretty, false, fsys)
+		// Check error
+		assert.ErrorContains(t, err, "No test files found in")
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"os"
 
+	"github.com/docker/go-units"
 	"github.com/go-errors/errors"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
@@ -19,11 +21,12 @@ import (
 var BloatQuery string
 
 type Result struct {
-	Type        string
-	Schemaname  string
-	Object_name string
-	Bloat       string
-	Waste       string
+	Type        string  `json:"type"`
+	Schemaname  string  `json:"schemaname"`
+	Object_name string  `json:"object_name"`
+	Bloat       float64 `json:"bloat"`
+	// Estimated wasted space in bytes
+	Waste int64 `json:"waste"`
 }
 
 func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
@@ -40,10 +43,13 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 	if err != nil {
 		return err
 	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result)
+	}
 
 	table := "|Type|Schema name|Object name|Bloat|Waste\n|-|-|-|-|-|\n"
 	for _, r := range result {
-		table += fmt.Sprintf("|`%s`|`%s`|`%s`|`%s`|`%s`|\n", r.Type, r.Schemaname, r.Object_name, r.Bloat, r.Waste)
+		table += fmt.Sprintf("|`%s`|`%s`|`%s`|`%.1f`|`%s`|\n", r.Type, r.Schemaname, r.Object_name, r.Bloat, units.BytesSize(float64(r.Waste)))
 	}
 	return list.RenderTable(table)
 }
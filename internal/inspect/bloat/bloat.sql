@@ -38,7 +38,7 @@ WITH constants AS (
   JOIN pg_class c2 ON c2.oid = i.indexrelid
 )
 SELECT
-  type, schemaname, object_name, bloat, pg_size_pretty(raw_waste) as waste
+  type, schemaname, object_name, bloat::float8 AS bloat, raw_waste AS waste
 FROM
 (SELECT
   'table' as type,
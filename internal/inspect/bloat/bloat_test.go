@@ -32,8 +32,8 @@ func TestBloat(t *testing.T) {
 				Type:        "index hit rate",
 				Schemaname:  "public",
 				Object_name: "table",
-				Bloat:       "0.9",
-				Waste:       "0.1",
+				Bloat:       0.9,
+				Waste:       1024,
 			})
 		// Run test
 		err := Run(context.Background(), dbConfig, fsys, conn.Intercept)
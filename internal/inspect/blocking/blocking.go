@@ -4,6 +4,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"os"
 	"regexp"
 
 	"github.com/go-errors/errors"
@@ -19,12 +20,14 @@ import (
 var BlockingQuery string
 
 type Result struct {
-	Blocked_pid        int
-	Blocking_statement string
-	Blocking_duration  string
-	Blocking_pid       int
-	Blocked_statement  string
-	Blocked_duration   string
+	Blocked_pid        int    `json:"blocked_pid"`
+	Blocking_statement string `json:"blocking_statement"`
+	// Duration in seconds
+	Blocking_duration float64 `json:"blocking_duration"`
+	Blocking_pid      int     `json:"blocking_pid"`
+	Blocked_statement string  `json:"blocked_statement"`
+	// Duration in seconds
+	Blocked_duration float64 `json:"blocked_duration"`
 }
 
 func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
@@ -42,6 +45,9 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 	if err != nil {
 		return err
 	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result)
+	}
 
 	table := "|blocked pid|blocking statement|blocking duration|blocking pid|blocked statement|blocked duration|\n|-|-|-|-|-|-|\n"
 	for _, r := range result {
@@ -54,7 +60,7 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 		re = regexp.MustCompile(`\|`)
 		blocking_statement = re.ReplaceAllString(blocking_statement, `\|`)
 		blocked_statement = re.ReplaceAllString(blocked_statement, `\|`)
-		table += fmt.Sprintf("|`%d`|`%s`|`%s`|`%d`|%s|`%s`|\n", r.Blocked_pid, blocking_statement, r.Blocking_duration, r.Blocking_pid, blocked_statement, r.Blocked_duration)
+		table += fmt.Sprintf("|`%d`|`%s`|`%s`|`%d`|%s|`%s`|\n", r.Blocked_pid, blocking_statement, utils.FormatDuration(r.Blocking_duration), r.Blocking_pid, blocked_statement, utils.FormatDuration(r.Blocked_duration))
 	}
 	return list.RenderTable(table)
 }
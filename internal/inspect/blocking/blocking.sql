@@ -1,10 +1,10 @@
 SELECT
   bl.pid AS blocked_pid,
   ka.query AS blocking_statement,
-  age(now(), ka.query_start)::text AS blocking_duration,
+  extract(epoch FROM age(now(), ka.query_start))::float8 AS blocking_duration,
   kl.pid AS blocking_pid,
   a.query AS blocked_statement,
-  age(now(), a.query_start)::text AS blocked_duration
+  extract(epoch FROM age(now(), a.query_start))::float8 AS blocked_duration
 FROM pg_catalog.pg_locks bl
 JOIN pg_catalog.pg_stat_activity a
   ON bl.pid = a.pid
@@ -29,10 +29,10 @@ func TestBloatCommand(t *testing.T) {
 			Reply("SELECT 1", Result{
 				Blocked_pid:        1,
 				Blocking_statement: "select 1",
-				Blocking_duration:  "2s",
+				Blocking_duration:  2,
 				Blocking_pid:       1,
 				Blocked_statement:  "select 1",
-				Blocked_duration:   "2s",
+				Blocked_duration:   2,
 			})
 		// Run test
 		err := Run(context.Background(), dbConfig, fsys, conn.Intercept)
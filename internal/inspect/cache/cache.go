@@ -4,6 +4,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"os"
 
 	"github.com/go-errors/errors"
 	"github.com/jackc/pgconn"
@@ -18,8 +19,9 @@ import (
 var CacheQuery string
 
 type Result struct {
-	Name  string
-	Ratio float64
+	Name string `json:"name"`
+	// Null when no blocks have been read since stats were reset
+	Ratio *float64 `json:"ratio"`
 }
 
 func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
@@ -37,12 +39,18 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 	if err != nil {
 		return err
 	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result)
+	}
 	// TODO: implement a markdown table marshaller
 	table := "|Name|Ratio|OK?|Explanation|\n|-|-|-|-|\n"
 	for _, r := range result {
-		ok := "Yup!"
-		if r.Ratio < 0.94 {
-			ok = "Maybe not..."
+		ratio, ok := "-", "No activity"
+		if r.Ratio != nil {
+			ratio, ok = fmt.Sprintf("%.6f", *r.Ratio), "Yup!"
+			if *r.Ratio < 0.94 {
+				ok = "Maybe not..."
+			}
 		}
 		var explanation string
 		if r.Name == "index hit rate" {
@@ -50,7 +58,7 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 		} else if r.Name == "table hit rate" {
 			explanation = "This is the ratio of table hits to table scans. If this ratio is low, it means that your queries are not finding the data effectively. Check your query performance and it might be worth increasing your compute."
 		}
-		table += fmt.Sprintf("|`%s`|`%.6f`|`%s`|`%s`|\n", r.Name, r.Ratio, ok, explanation)
+		table += fmt.Sprintf("|`%s`|`%s`|`%s`|`%s`|\n", r.Name, ratio, ok, explanation)
 	}
 	return list.RenderTable(table)
 }
@@ -1,9 +1,9 @@
 SELECT
   'index hit rate' AS name,
-  (sum(idx_blks_hit)) / nullif(sum(idx_blks_hit + idx_blks_read),0) AS ratio
+  ((sum(idx_blks_hit)) / nullif(sum(idx_blks_hit + idx_blks_read),0))::float8 AS ratio
 FROM pg_statio_user_indexes
 UNION ALL
 SELECT
   'table hit rate' AS name,
-  sum(heap_blks_hit) / nullif(sum(heap_blks_hit) + sum(heap_blks_read),0) AS ratio
+  (sum(heap_blks_hit) / nullif(sum(heap_blks_hit) + sum(heap_blks_read),0))::float8 AS ratio
 FROM pg_statio_user_tables
@@ -7,6 +7,7 @@ import (
 	"github.com/jackc/pgconn"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"github.com/supabase/cli/pkg/cast"
 	"github.com/supabase/cli/pkg/pgtest"
 )
 
@@ -28,7 +29,7 @@ func TestCacheCommand(t *testing.T) {
 		conn.Query(CacheQuery).
 			Reply("SELECT 1", Result{
 				Name:  "index hit rate",
-				Ratio: 0.9,
+				Ratio: cast.Ptr(0.9),
 			})
 		// Run test
 		err := Run(context.Background(), dbConfig, fsys, conn.Intercept)
@@ -36,6 +37,20 @@ func TestCacheCommand(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("inspects idle cache", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query(CacheQuery).
+			Reply("SELECT 1", Result{Name: "index hit rate"})
+		// Run test
+		err := Run(context.Background(), dbConfig, fsys, conn.Intercept)
+		// Check error
+		assert.NoError(t, err)
+	})
+
 	t.Run("throws error on empty result", func(t *testing.T) {
 		// Setup in-memory fs
 		fsys := afero.NewMemMapFs()
@@ -4,6 +4,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"os"
 	"regexp"
 
 	"github.com/go-errors/errors"
@@ -19,11 +20,15 @@ import (
 var CallsQuery string
 
 type Result struct {
-	Total_exec_time string
-	Prop_exec_time  string
-	Ncalls          string
-	Sync_io_time    string
-	Query           string
+	Queryid int64 `json:"queryid"`
+	// Time in seconds
+	Total_exec_time float64 `json:"total_exec_time"`
+	// Ratio of total exec time of all queries
+	Prop_exec_time float64 `json:"prop_exec_time"`
+	Ncalls         int64   `json:"ncalls"`
+	// Time in seconds
+	Sync_io_time float64 `json:"sync_io_time"`
+	Query        string  `json:"query"`
 }
 
 func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
@@ -40,6 +45,9 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 	if err != nil {
 		return err
 	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result)
+	}
 	// TODO: implement a markdown table marshaller
 	table := "|Query|Total Execution Time|Proportion of total exec time|Number Calls|Sync IO time|\n|-|-|-|-|-|\n"
 	for _, r := range result {
@@ -50,7 +58,7 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 		// escape pipes in query
 		re = regexp.MustCompile(`\|`)
 		query = re.ReplaceAllString(query, `\|`)
-		table += fmt.Sprintf("|`%s`|`%s`|`%s`|`%s`|`%s`|\n", query, r.Total_exec_time, r.Prop_exec_time, r.Ncalls, r.Sync_io_time)
+		table += fmt.Sprintf("|`%s`|`%s`|`%.1f%%`|`%d`|`%s`|\n", query, utils.FormatDuration(r.Total_exec_time), r.Prop_exec_time*100, r.Ncalls, utils.FormatDuration(r.Sync_io_time))
 	}
 	return list.RenderTable(table)
 }
@@ -1,9 +1,10 @@
 SELECT
+  queryid,
   query,
-  (interval '1 millisecond' * total_exec_time)::text AS total_exec_time,
-  to_char((total_exec_time/sum(total_exec_time) OVER()) * 100, 'FM90D0') || '%'  AS prop_exec_time,
-  to_char(calls, 'FM999G999G990') AS ncalls,
-  (interval '1 millisecond' * (blk_read_time + blk_write_time))::text AS sync_io_time
+  (total_exec_time / 1000)::float8 AS total_exec_time,
+  coalesce(total_exec_time / nullif(sum(total_exec_time) OVER(), 0), 0)::float8 AS prop_exec_time,
+  calls AS ncalls,
+  ((blk_read_time + blk_write_time) / 1000)::float8 AS sync_io_time
 FROM pg_stat_statements
 ORDER BY calls DESC
 LIMIT 10
@@ -0,0 +1,249 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/docker/go-units"
+	"github.com/go-errors/errors"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/inspect"
+	"github.com/supabase/cli/internal/migration/list"
+	"github.com/supabase/cli/internal/utils"
+)
+
+// A numeric threshold that also accepts human readable sizes, such as "100MB".
+type Threshold float64
+
+func (t *Threshold) UnmarshalTOML(value any) error {
+	switch v := value.(type) {
+	case int64:
+		*t = Threshold(v)
+	case float64:
+		*t = Threshold(v)
+	case string:
+		size, err := units.RAMInBytes(v)
+		if err != nil {
+			return errors.Errorf("invalid threshold: %w", err)
+		}
+		*t = Threshold(size)
+	default:
+		return errors.Errorf("invalid threshold: %v", value)
+	}
+	return nil
+}
+
+type Rule struct {
+	Name string `toml:"name" json:"name"`
+	// Name of the inspect query, such as cache or unused_indexes
+	Query  string `toml:"query" json:"query"`
+	Column string `toml:"column" json:"column"`
+	// Divides the column by another column of the same row, such as a limit
+	RelativeTo string     `toml:"relative_to" json:"relative_to,omitempty"`
+	Min        *Threshold `toml:"min" json:"min,omitempty"`
+	Max        *Threshold `toml:"max" json:"max,omitempty"`
+	// Only rows with matching column values are checked
+	Where map[string]any `toml:"where" json:"where,omitempty"`
+}
+
+type Rules struct {
+	Rules []Rule `toml:"rule"`
+}
+
+func threshold(v float64) *Threshold {
+	t := Threshold(v)
+	return &t
+}
+
+var DefaultRules = []Rule{{
+	Name:   "cache hit ratio is above 0.99",
+	Query:  "cache",
+	Column: "ratio",
+	Min:    threshold(0.99),
+}, {
+	Name:   "no unused index is over 100MB",
+	Query:  "unused_indexes",
+	Column: "index_size",
+	Max:    threshold(100 << 20),
+}, {
+	Name:   "no replication slot lags over 1GB",
+	Query:  "replication_slots",
+	Column: "replication_lag",
+	Max:    threshold(1 << 30),
+}, {
+	Name:   "no table bloat is above 2x",
+	Query:  "bloat",
+	Column: "bloat",
+	Max:    threshold(2),
+	Where:  map[string]any{"type": "table"},
+}, {
+	Name:       "no role is above 90% of its connection limit",
+	Query:      "role_connections",
+	Column:     "active_connections",
+	RelativeTo: "connection_limit",
+	Max:        threshold(0.9),
+}}
+
+type Result struct {
+	Rule   string `json:"rule"`
+	Passed bool   `json:"passed"`
+	// Describes each row that violates the rule
+	Failures []string `json:"failures"`
+}
+
+func Run(ctx context.Context, rulesPath string, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
+	rules := DefaultRules
+	if len(rulesPath) > 0 {
+		var err error
+		if rules, err = LoadRules(rulesPath, fsys); err != nil {
+			return err
+		}
+	}
+	conn, err := utils.ConnectByConfig(ctx, config, options...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+	var names []string
+	for _, r := range rules {
+		if !utils.SliceContains(names, r.Query) {
+			names = append(names, r.Query)
+		}
+	}
+	rows, err := inspect.QueryRows(ctx, names, conn)
+	if err != nil {
+		return err
+	}
+	result, err := Evaluate(rules, rows)
+	if err != nil {
+		return err
+	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		if err := utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result); err != nil {
+			return err
+		}
+	} else if err := list.RenderTable(toMarkdown(result)); err != nil {
+		return err
+	}
+	var failed int
+	for _, r := range result {
+		if !r.Passed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf("%d of %d rules failed", failed, len(result))
+	}
+	return nil
+}
+
+func LoadRules(rulesPath string, fsys afero.Fs) ([]Rule, error) {
+	contents, err := afero.ReadFile(fsys, rulesPath)
+	if err != nil {
+		return nil, errors.Errorf("failed to read rules: %w", err)
+	}
+	var rules Rules
+	if _, err := toml.Decode(string(contents), &rules); err != nil {
+		return nil, errors.Errorf("failed to parse rules: %w", err)
+	}
+	for i, r := range rules.Rules {
+		if len(r.Query) == 0 || len(r.Column) == 0 {
+			return nil, errors.Errorf("rule %d must specify query and column", i+1)
+		} else if r.Min == nil && r.Max == nil {
+			return nil, errors.Errorf("rule %d must specify min or max", i+1)
+		}
+		if len(r.Name) == 0 {
+			rules.Rules[i].Name = fmt.Sprintf("%s.%s", r.Query, r.Column)
+		}
+	}
+	return rules.Rules, nil
+}
+
+// Checks every matching row of each rule's query against its thresholds.
+func Evaluate(rules []Rule, rows map[string][]map[string]any) ([]Result, error) {
+	result := make([]Result, len(rules))
+	for i, r := range rules {
+		result[i] = Result{Rule: r.Name, Passed: true, Failures: []string{}}
+		for _, row := range rows[r.Query] {
+			if !matches(row, r.Where) {
+				continue
+			}
+			// Null values have nothing to check, such as the hit ratio of an idle cache
+			if v, ok := row[r.Column]; ok && v == nil {
+				continue
+			}
+			value, err := toFloat(row, r.Column)
+			if err != nil {
+				return nil, errors.Errorf("rule %q: %w", r.Name, err)
+			}
+			if len(r.RelativeTo) > 0 {
+				total, err := toFloat(row, r.RelativeTo)
+				if err != nil {
+					return nil, errors.Errorf("rule %q: %w", r.Name, err)
+				} else if total == 0 {
+					continue
+				}
+				value /= total
+			}
+			if r.Min != nil && value < float64(*r.Min) {
+				result[i].Failures = append(result[i].Failures, fmt.Sprintf("%s: %s %v is below %v", rowLabel(row), r.Column, value, float64(*r.Min)))
+			}
+			if r.Max != nil && value > float64(*r.Max) {
+				result[i].Failures = append(result[i].Failures, fmt.Sprintf("%s: %s %v is above %v", rowLabel(row), r.Column, value, float64(*r.Max)))
+			}
+		}
+		result[i].Passed = len(result[i].Failures) == 0
+	}
+	return result, nil
+}
+
+func matches(row map[string]any, where map[string]any) bool {
+	for k, v := range where {
+		if fmt.Sprint(row[k]) != fmt.Sprint(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func toFloat(row map[string]any, column string) (float64, error) {
+	switch v := row[column].(type) {
+	case float64:
+		return v, nil
+	case nil:
+		return 0, errors.Errorf("column not found: %s", column)
+	default:
+		return 0, errors.Errorf("column is not numeric: %s", column)
+	}
+}
+
+// Columns that identify a row of an inspect query, in display order.
+var labelColumns = []string{"type", "schema", "schemaname", "table", "name", "object_name", "index", "rolname", "slot_name"}
+
+func rowLabel(row map[string]any) string {
+	var parts []string
+	for _, k := range labelColumns {
+		if s, ok := row[k].(string); ok && len(s) > 0 {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func toMarkdown(result []Result) string {
+	table := "|Rule|Status|Failures|\n|-|-|-|\n"
+	for _, r := range result {
+		status := "PASS"
+		if !r.Passed {
+			status = "FAIL"
+		}
+		failures := strings.ReplaceAll(strings.Join(r.Failures, "; "), "|", `\|`)
+		table += fmt.Sprintf("|%s|`%s`|%s|\n", r.Rule, status, failures)
+	}
+	return table
+}
@@ -0,0 +1,152 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/internal/inspect/cache"
+	"github.com/supabase/cli/pkg/pgtest"
+)
+
+var dbConfig = pgconn.Config{
+	Host:     "127.0.0.1",
+	Port:     5432,
+	User:     "admin",
+	Password: "password",
+	Database: "postgres",
+}
+
+func TestLoadRules(t *testing.T) {
+	t.Run("parses rules with human readable sizes", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, "inspect-rules.toml", []byte(`
+[[rule]]
+query = "unused_indexes"
+column = "index_size"
+max = "100MB"
+
+[[rule]]
+name = "bloat"
+query = "bloat"
+column = "bloat"
+max = 2
+where = { type = "table" }
+`), 0644))
+		// Run test
+		rules, err := LoadRules("inspect-rules.toml", fsys)
+		// Check error
+		assert.NoError(t, err)
+		require.Len(t, rules, 2)
+		assert.Equal(t, "unused_indexes.index_size", rules[0].Name)
+		assert.Equal(t, Threshold(100<<20), *rules[0].Max)
+		assert.Equal(t, Threshold(2), *rules[1].Max)
+		assert.Equal(t, map[string]any{"type": "table"}, rules[1].Where)
+	})
+
+	t.Run("throws error on missing threshold", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, "inspect-rules.toml", []byte(`
+[[rule]]
+query = "cache"
+column = "ratio"
+`), 0644))
+		// Run test
+		_, err := LoadRules("inspect-rules.toml", fsys)
+		// Check error
+		assert.ErrorContains(t, err, "rule 1 must specify min or max")
+	})
+}
+
+func TestEvaluate(t *testing.T) {
+	rows := map[string][]map[string]any{
+		"cache": {
+			{"name": "index hit rate", "ratio": 0.995},
+			{"name": "table hit rate", "ratio": 0.95},
+			{"name": "idle hit rate", "ratio": nil},
+		},
+		"bloat": {
+			{"type": "table", "schemaname": "public", "object_name": "users", "bloat": 3.5},
+			{"type": "index", "schemaname": "public", "object_name": "users_pkey", "bloat": 9.0},
+		},
+		"role_connections": {
+			{"rolname": "authenticator", "active_connections": 95.0, "connection_limit": 100.0},
+			{"rolname": "postgres", "active_connections": 1.0, "connection_limit": 100.0},
+		},
+	}
+
+	t.Run("reports failing rows", func(t *testing.T) {
+		// Run test
+		result, err := Evaluate([]Rule{DefaultRules[0], DefaultRules[3], DefaultRules[4], DefaultRules[2]}, rows)
+		// Check error
+		assert.NoError(t, err)
+		assert.Equal(t, []Result{{
+			Rule:     "cache hit ratio is above 0.99",
+			Failures: []string{"table hit rate: ratio 0.95 is below 0.99"},
+		}, {
+			Rule:     "no table bloat is above 2x",
+			Failures: []string{"table public users: bloat 3.5 is above 2"},
+		}, {
+			Rule:     "no role is above 90% of its connection limit",
+			Failures: []string{"authenticator: active_connections 0.95 is above 0.9"},
+		}, {
+			Rule:     "no replication slot lags over 1GB",
+			Passed:   true,
+			Failures: []string{},
+		}}, result)
+	})
+
+	t.Run("throws error on unknown column", func(t *testing.T) {
+		// Run test
+		_, err := Evaluate([]Rule{{Name: "test", Query: "cache", Column: "missing", Min: threshold(1)}}, rows)
+		// Check error
+		assert.ErrorContains(t, err, `rule "test": column not found: missing`)
+	})
+}
+
+func TestCheckCommand(t *testing.T) {
+	t.Run("throws error on failed rules", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, "inspect-rules.toml", []byte(`
+[[rule]]
+query = "cache"
+column = "ratio"
+min = 0.99
+`), 0644))
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query(fmt.Sprintf("SELECT (jsonb_build_object('cache', (SELECT coalesce(json_agg(t), '[]') FROM (%s) t)))::text", strings.TrimSpace(cache.CacheQuery))).
+			Reply("SELECT 1", []interface{}{`{"cache":[{"name":"table hit rate","ratio":0.5}]}`})
+		// Run test
+		err := Run(context.Background(), "inspect-rules.toml", dbConfig, fsys, conn.Intercept)
+		// Check error
+		assert.ErrorContains(t, err, "1 of 1 rules failed")
+	})
+
+	t.Run("throws error on unknown query", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, "inspect-rules.toml", []byte(`
+[[rule]]
+query = "missing"
+column = "ratio"
+min = 0.99
+`), 0644))
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		// Run test
+		err := Run(context.Background(), "inspect-rules.toml", dbConfig, fsys, conn.Intercept)
+		// Check error
+		assert.ErrorContains(t, err, "unknown inspect query: missing")
+	})
+}
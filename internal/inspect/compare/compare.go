@@ -0,0 +1,395 @@
+package compare
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/go-errors/errors"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/inspect/bloat"
+	"github.com/supabase/cli/internal/inspect/cache"
+	"github.com/supabase/cli/internal/inspect/outliers"
+	"github.com/supabase/cli/internal/inspect/seq_scans"
+	"github.com/supabase/cli/internal/inspect/total_table_sizes"
+	"github.com/supabase/cli/internal/inspect/unused_indexes"
+	"github.com/supabase/cli/internal/migration/list"
+	"github.com/supabase/cli/internal/utils"
+)
+
+type Delta[T int64 | float64] struct {
+	Key   string `json:"key"`
+	Old   T      `json:"old"`
+	New   T      `json:"new"`
+	Delta T      `json:"delta"`
+	// True if the key is absent from the old report
+	Added bool `json:"added"`
+}
+
+type Result struct {
+	TableGrowth      []Delta[int64]          `json:"table_growth"`
+	BloatChange      []Delta[float64]        `json:"bloat_change"`
+	CacheHitDrift    []Delta[float64]        `json:"cache_hit_drift"`
+	SeqScanHotspots  []Delta[int64]          `json:"seq_scan_hotspots"`
+	NewUnusedIndexes []unused_indexes.Result `json:"new_unused_indexes"`
+	QueryTime        []Delta[float64]        `json:"query_time"`
+}
+
+func Run(ctx context.Context, oldDir, newDir string, fsys afero.Fs) error {
+	before, err := LoadReport(oldDir, fsys)
+	if err != nil {
+		return err
+	}
+	after, err := LoadReport(newDir, fsys)
+	if err != nil {
+		return err
+	}
+	result, err := Compare(before, after)
+	if err != nil {
+		return err
+	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result)
+	}
+	return list.RenderTable(toMarkdown(result))
+}
+
+// Report maps each inspect query name to its rows encoded as a json array.
+type Report map[string]json.RawMessage
+
+// Loads the combined json report from a directory created by `inspect report`,
+// falling back to the per-query csv files when no json report is found.
+func LoadReport(dir string, fsys afero.Fs) (Report, error) {
+	if ok, err := afero.DirExists(fsys, dir); err != nil {
+		return nil, errors.Errorf("failed to stat report dir: %w", err)
+	} else if !ok {
+		return nil, errors.Errorf("report dir not found: %s", dir)
+	}
+	matches, err := afero.Glob(fsys, filepath.Join(dir, "report_*.json"))
+	if err != nil {
+		return nil, errors.Errorf("failed to glob report: %w", err)
+	}
+	if len(matches) > 0 {
+		// Dated file names sort chronologically
+		sort.Strings(matches)
+		contents, err := afero.ReadFile(fsys, matches[len(matches)-1])
+		if err != nil {
+			return nil, errors.Errorf("failed to read report: %w", err)
+		}
+		var result Report
+		if err := json.Unmarshal(contents, &result); err != nil {
+			return nil, errors.Errorf("failed to parse report: %w", err)
+		}
+		return result, nil
+	}
+	if matches, err = afero.Glob(fsys, filepath.Join(dir, "*.csv")); err != nil {
+		return nil, errors.Errorf("failed to glob report: %w", err)
+	} else if len(matches) == 0 {
+		return nil, errors.Errorf("no report files found in %s", dir)
+	}
+	sort.Strings(matches)
+	result := Report{}
+	for _, fp := range matches {
+		base := strings.TrimSuffix(filepath.Base(fp), ".csv")
+		name := base
+		if i := strings.LastIndex(base, "_"); i > 0 {
+			name = base[:i]
+		}
+		rows, err := readCSV(fp, fsys)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = rows
+	}
+	return result, nil
+}
+
+// Converts csv rows to a json array of objects, inferring numbers and booleans.
+func readCSV(fp string, fsys afero.Fs) (json.RawMessage, error) {
+	f, err := fsys.Open(fp)
+	if err != nil {
+		return nil, errors.Errorf("failed to open csv: %w", err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, errors.Errorf("failed to read csv: %w", err)
+	}
+	rows := []map[string]any{}
+	for i := 1; i < len(records); i++ {
+		row := map[string]any{}
+		for j, column := range records[0] {
+			if j < len(records[i]) {
+				row[column] = parseValue(records[i][j])
+			}
+		}
+		rows = append(rows, row)
+	}
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return nil, errors.Errorf("failed to encode csv: %w", err)
+	}
+	return data, nil
+}
+
+func parseValue(value string) any {
+	switch value {
+	case "t", "true":
+		return true
+	case "f", "false":
+		return false
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return json.Number(value)
+	}
+	if size, ok := parseSize(value); ok {
+		return size
+	}
+	// Older versions of inspect report formatted numbers for display
+	if groupedPattern.MatchString(value) {
+		return json.Number(strings.ReplaceAll(value, ",", ""))
+	}
+	if percent, ok := strings.CutSuffix(value, "%"); ok {
+		if ratio, err := strconv.ParseFloat(percent, 64); err == nil {
+			return ratio / 100
+		}
+	}
+	if seconds, ok := parseInterval(value); ok {
+		return seconds
+	}
+	return value
+}
+
+var (
+	groupedPattern  = regexp.MustCompile(`^-?[0-9]{1,3}(,[0-9]{3})+$`)
+	intervalPattern = regexp.MustCompile(`^(?:([0-9]+) days? )?(-?)([0-9]+):([0-9]{2}):([0-9]{2}(?:\.[0-9]+)?)$`)
+)
+
+// Parses interval text, ie. 00:00:01.5 or 1 day 02:00:00, to seconds.
+func parseInterval(value string) (float64, bool) {
+	matches := intervalPattern.FindStringSubmatch(value)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	var days, hours, minutes float64
+	if len(matches[1]) > 0 {
+		days, _ = strconv.ParseFloat(matches[1], 64)
+	}
+	hours, _ = strconv.ParseFloat(matches[3], 64)
+	minutes, _ = strconv.ParseFloat(matches[4], 64)
+	seconds, _ := strconv.ParseFloat(matches[5], 64)
+	seconds += minutes*60 + hours*3600
+	if matches[2] == "-" {
+		seconds = -seconds
+	}
+	return days*86400 + seconds, true
+}
+
+// Parses human readable sizes from pg_size_pretty, ie. 8192 bytes or 16 kB, which
+// older versions of inspect report wrote to csv files.
+func parseSize(value string) (int64, bool) {
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return 0, false
+	}
+	switch fields[1] {
+	case "bytes":
+		size, err := strconv.ParseInt(fields[0], 10, 64)
+		return size, err == nil
+	case "kB", "MB", "GB", "TB", "PB":
+		size, err := units.RAMInBytes(value)
+		return size, err == nil
+	}
+	return 0, false
+}
+
+var errOutdated = errors.New("report predates machine-readable output")
+
+func decode[T any](r Report, name string) ([]T, error) {
+	var result []T
+	data, ok := r[name]
+	if !ok {
+		return result, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(&result); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, errors.Errorf("failed to decode %s: %w: %v", name, errOutdated, err)
+		}
+		return nil, errors.Errorf("failed to decode %s: %w", name, err)
+	}
+	return result, nil
+}
+
+func Compare(before, after Report) (result Result, err error) {
+	if result.TableGrowth, err = compareRows(before, after, "total_table_sizes", func(r total_table_sizes.Result) (string, int64, bool) {
+		return r.Schema + "." + r.Name, r.Size, true
+	}); err != nil {
+		return result, err
+	}
+	if result.BloatChange, err = compareRows(before, after, "bloat", func(r bloat.Result) (string, float64, bool) {
+		return fmt.Sprintf("%s %s.%s", r.Type, r.Schemaname, r.Object_name), r.Bloat, true
+	}); err != nil {
+		return result, err
+	}
+	if result.CacheHitDrift, err = compareRows(before, after, "cache", func(r cache.Result) (string, float64, bool) {
+		// Skips ratios without any activity to compare
+		if r.Ratio == nil {
+			return r.Name, 0, false
+		}
+		return r.Name, *r.Ratio, true
+	}); err != nil {
+		return result, err
+	}
+	scans, err := compareRows(before, after, "seq_scans", func(r seq_scans.Result) (string, int64, bool) {
+		return r.Name, r.Count, true
+	})
+	if err != nil {
+		return result, err
+	}
+	// Only tables with new sequential scans are hotspots
+	result.SeqScanHotspots = []Delta[int64]{}
+	for _, d := range scans {
+		if d.Delta > 0 {
+			result.SeqScanHotspots = append(result.SeqScanHotspots, d)
+		}
+	}
+	if result.QueryTime, err = compareRows(before, after, "outliers", func(r outliers.Result) (string, float64, bool) {
+		// Older reports have no query id, so match on the normalised query text
+		return r.Query, r.Total_exec_time, true
+	}); err != nil {
+		return result, err
+	}
+	if result.NewUnusedIndexes, err = newUnusedIndexes(before, after); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// Lines up rows from both reports by key and sorts them by the largest absolute delta.
+// Rows for which key returns false have no value and are skipped.
+func compareRows[R any, T int64 | float64](before, after Report, name string, key func(R) (string, T, bool)) ([]Delta[T], error) {
+	oldRows, newRows, err := decodeBoth[R](before, after, name)
+	if err != nil {
+		return nil, err
+	}
+	oldValues := make(map[string]T, len(oldRows))
+	for _, r := range oldRows {
+		if k, v, ok := key(r); ok {
+			oldValues[k] = v
+		}
+	}
+	result := []Delta[T]{}
+	for _, r := range newRows {
+		k, v, ok := key(r)
+		if !ok {
+			continue
+		}
+		old, ok := oldValues[k]
+		result = append(result, Delta[T]{
+			Key:   k,
+			Old:   old,
+			New:   v,
+			Delta: v - old,
+			Added: !ok,
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return abs(result[i].Delta) > abs(result[j].Delta)
+	})
+	return result, nil
+}
+
+// Decodes a section from both reports. Sections that cannot be decoded, such as columns
+// that older versions formatted as text, are skipped with a warning.
+func decodeBoth[T any](before, after Report, name string) ([]T, []T, error) {
+	oldRows, err := decode[T](before, name)
+	if err != nil {
+		return skipOutdated[T](err)
+	}
+	newRows, err := decode[T](after, name)
+	if err != nil {
+		return skipOutdated[T](err)
+	}
+	return oldRows, newRows, nil
+}
+
+func skipOutdated[T any](err error) ([]T, []T, error) {
+	if !errors.Is(err, errOutdated) {
+		return nil, nil, err
+	}
+	fmt.Fprintln(os.Stderr, utils.Yellow("WARNING:"), "skipping", err)
+	return nil, nil, nil
+}
+
+func abs[T int64 | float64](v T) T {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func newUnusedIndexes(before, after Report) ([]unused_indexes.Result, error) {
+	oldRows, newRows, err := decodeBoth[unused_indexes.Result](before, after, "unused_indexes")
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{}, len(oldRows))
+	for _, r := range oldRows {
+		seen[r.Table+"."+r.Index] = struct{}{}
+	}
+	result := []unused_indexes.Result{}
+	for _, r := range newRows {
+		if _, ok := seen[r.Table+"."+r.Index]; !ok {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func toMarkdown(result Result) string {
+	var md strings.Builder
+	md.WriteString("## Table growth\n\n|Table|Old size|New size|Delta|\n|-|-|-|-|\n")
+	for _, d := range result.TableGrowth {
+		fmt.Fprintf(&md, "|`%s`|`%s`|`%s`|`%s`|\n", d.Key, units.BytesSize(float64(d.Old)), units.BytesSize(float64(d.New)), formatBytes(d.Delta))
+	}
+	md.WriteString("\n## Bloat change\n\n|Object|Old bloat|New bloat|Delta|\n|-|-|-|-|\n")
+	for _, d := range result.BloatChange {
+		fmt.Fprintf(&md, "|`%s`|`%.1f`|`%.1f`|`%+.1f`|\n", d.Key, d.Old, d.New, d.Delta)
+	}
+	md.WriteString("\n## Cache hit drift\n\n|Name|Old ratio|New ratio|Delta|\n|-|-|-|-|\n")
+	for _, d := range result.CacheHitDrift {
+		fmt.Fprintf(&md, "|`%s`|`%.4f`|`%.4f`|`%+.4f`|\n", d.Key, d.Old, d.New, d.Delta)
+	}
+	md.WriteString("\n## Sequential scan hotspots\n\n|Table|Old count|New count|Delta|\n|-|-|-|-|\n")
+	for _, d := range result.SeqScanHotspots {
+		fmt.Fprintf(&md, "|`%s`|`%d`|`%d`|`%+d`|\n", d.Key, d.Old, d.New, d.Delta)
+	}
+	md.WriteString("\n## Newly unused indexes\n\n|Table|Index|Index size|Index scans|\n|-|-|-|-|\n")
+	for _, r := range result.NewUnusedIndexes {
+		fmt.Fprintf(&md, "|`%s`|`%s`|`%s`|`%d`|\n", r.Table, r.Index, units.BytesSize(float64(r.Index_size)), r.Index_scans)
+	}
+	md.WriteString("\n## Query time\n\n|Query|Old exec time|New exec time|Delta|\n|-|-|-|-|\n")
+	for _, d := range result.QueryTime {
+		key := strings.ReplaceAll(strings.Join(strings.Fields(d.Key), " "), "|", `\|`)
+		fmt.Fprintf(&md, "|`%s`|`%.3fs`|`%.3fs`|`%+.3fs`|\n", key, d.Old, d.New, d.Delta)
+	}
+	return md.String()
+}
+
+func formatBytes(delta int64) string {
+	if delta < 0 {
+		return "-" + units.BytesSize(float64(-delta))
+	}
+	return "+" + units.BytesSize(float64(delta))
+}
@@ -0,0 +1,168 @@
+package compare
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/internal/inspect/unused_indexes"
+)
+
+func TestLoadReport(t *testing.T) {
+	t.Run("loads latest json report", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, "old/report_2024-01-01.json", []byte(`{"cache":[]}`), 0644))
+		require.NoError(t, afero.WriteFile(fsys, "old/report_2024-01-08.json", []byte(`{"bloat":[]}`), 0644))
+		// Run test
+		report, err := LoadReport("old", fsys)
+		// Check error
+		assert.NoError(t, err)
+		assert.Contains(t, report, "bloat")
+		assert.NotContains(t, report, "cache")
+	})
+
+	t.Run("falls back to csv files", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, "old/seq_scans_2024-01-01.csv", []byte("name,count\npublic.users,10\n"), 0644))
+		// Run test
+		report, err := LoadReport("old", fsys)
+		// Check error
+		assert.NoError(t, err)
+		assert.JSONEq(t, `[{"name":"public.users","count":10}]`, string(report["seq_scans"]))
+	})
+
+	t.Run("parses human readable sizes from csv", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, "old/total_table_sizes_2024-01-01.csv", []byte("schema,name,size\npublic,users,16 kB\npublic,posts,8192 bytes\n"), 0644))
+		// Run test
+		report, err := LoadReport("old", fsys)
+		// Check error
+		assert.NoError(t, err)
+		assert.JSONEq(t, `[
+			{"schema":"public","name":"users","size":16384},
+			{"schema":"public","name":"posts","size":8192}
+		]`, string(report["total_table_sizes"]))
+	})
+
+	t.Run("parses formatted outliers from csv", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		csv := `total_exec_time,prop_exec_time,ncalls,sync_io_time,query
+00:00:01.5,12.5%,"1,234",00:00:00,SELECT 1
+1 day 02:00:00,87.5%,7,00:01:00.25,SELECT 2
+`
+		require.NoError(t, afero.WriteFile(fsys, "old/outliers_2024-01-01.csv", []byte(csv), 0644))
+		// Run test
+		report, err := LoadReport("old", fsys)
+		// Check error
+		assert.NoError(t, err)
+		assert.JSONEq(t, `[
+			{"total_exec_time":1.5,"prop_exec_time":0.125,"ncalls":1234,"sync_io_time":0,"query":"SELECT 1"},
+			{"total_exec_time":93600,"prop_exec_time":0.875,"ncalls":7,"sync_io_time":60.25,"query":"SELECT 2"}
+		]`, string(report["outliers"]))
+		// Compares against a machine-readable report
+		result, err := Compare(report, Report{
+			"outliers": []byte(`[{"queryid":1,"total_exec_time":2,"query":"SELECT 1"}]`),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []Delta[float64]{
+			{Key: "SELECT 1", Old: 1.5, New: 2, Delta: 0.5},
+		}, result.QueryTime)
+	})
+
+	t.Run("throws error on missing dir", func(t *testing.T) {
+		// Run test
+		_, err := LoadReport("old", afero.NewMemMapFs())
+		// Check error
+		assert.ErrorContains(t, err, "report dir not found: old")
+	})
+
+	t.Run("throws error on empty dir", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, fsys.Mkdir("old", 0755))
+		// Run test
+		_, err := LoadReport("old", fsys)
+		// Check error
+		assert.ErrorContains(t, err, "no report files found in old")
+	})
+}
+
+func TestCompareReports(t *testing.T) {
+	before := Report{
+		"total_table_sizes": []byte(`[{"schema":"public","name":"users","size":1024}]`),
+		"cache":             []byte(`[{"name":"table hit rate","ratio":0.99}]`),
+		"seq_scans":         []byte(`[{"name":"public.users","count":10},{"name":"public.posts","count":5}]`),
+		"unused_indexes":    []byte(`[{"table":"public.users","index":"users_idx","index_size":8192,"index_scans":0}]`),
+		"outliers":          []byte(`[{"queryid":1,"total_exec_time":1.5,"query":"SELECT 1"}]`),
+	}
+	after := Report{
+		"total_table_sizes": []byte(`[{"schema":"public","name":"users","size":4096},{"schema":"public","name":"posts","size":512}]`),
+		"cache":             []byte(`[{"name":"table hit rate","ratio":0.95},{"name":"index hit rate","ratio":null}]`),
+		"seq_scans":         []byte(`[{"name":"public.users","count":10},{"name":"public.posts","count":50}]`),
+		"unused_indexes":    []byte(`[{"table":"public.users","index":"users_idx","index_size":8192,"index_scans":0},{"table":"public.posts","index":"posts_idx","index_size":16384,"index_scans":1}]`),
+		"outliers":          []byte(`[{"queryid":1,"total_exec_time":2,"query":"SELECT 1"}]`),
+	}
+
+	t.Run("computes deltas by key", func(t *testing.T) {
+		// Run test
+		result, err := Compare(before, after)
+		// Check error
+		assert.NoError(t, err)
+		assert.Equal(t, []Delta[int64]{
+			{Key: "public.users", Old: 1024, New: 4096, Delta: 3072},
+			{Key: "public.posts", New: 512, Delta: 512, Added: true},
+		}, result.TableGrowth)
+		require.Len(t, result.CacheHitDrift, 1)
+		assert.InDelta(t, -0.04, result.CacheHitDrift[0].Delta, 1e-9)
+		assert.Equal(t, []Delta[int64]{
+			{Key: "public.posts", Old: 5, New: 50, Delta: 45},
+		}, result.SeqScanHotspots)
+		assert.Equal(t, []unused_indexes.Result{
+			{Table: "public.posts", Index: "posts_idx", Index_size: 16384, Index_scans: 1},
+		}, result.NewUnusedIndexes)
+		assert.Equal(t, []Delta[float64]{
+			{Key: "SELECT 1", Old: 1.5, New: 2, Delta: 0.5},
+		}, result.QueryTime)
+		assert.Empty(t, result.BloatChange)
+	})
+
+	t.Run("skips sections predating machine-readable output", func(t *testing.T) {
+		// Run test
+		result, err := Compare(before, Report{
+			"total_table_sizes": []byte(`[{"size":"3 GB"}]`),
+			"seq_scans":         []byte(`[{"name":"public.posts","count":50}]`),
+		})
+		// Check error
+		assert.NoError(t, err)
+		assert.Empty(t, result.TableGrowth)
+		assert.Equal(t, []Delta[int64]{
+			{Key: "public.posts", Old: 5, New: 50, Delta: 45},
+		}, result.SeqScanHotspots)
+	})
+
+	t.Run("throws error on malformed json", func(t *testing.T) {
+		// Run test
+		_, err := Compare(before, Report{"total_table_sizes": []byte(`[{`)})
+		// Check error
+		assert.ErrorContains(t, err, "failed to decode total_table_sizes:")
+	})
+}
+
+func TestCompareCommand(t *testing.T) {
+	t.Run("renders comparison", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, "old/report_2024-01-01.json", []byte(`{"cache":[{"name":"index hit rate","ratio":0.9}]}`), 0644))
+		require.NoError(t, afero.WriteFile(fsys, "new/report_2024-01-08.json", []byte(`{"cache":[{"name":"index hit rate","ratio":0.8}]}`), 0644))
+		// Run test
+		err := Run(context.Background(), "old", "new", fsys)
+		// Check error
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,254 @@
+package inspect
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/migration/list"
+	"github.com/supabase/cli/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+type Parameter struct {
+	Name        string  `yaml:"name"`
+	Description string  `yaml:"description"`
+	Default     *string `yaml:"default"`
+}
+
+// A project query loaded from supabase/inspect/<name>.sql.
+type CustomQuery struct {
+	Name        string      `yaml:"-"`
+	Description string      `yaml:"description"`
+	Parameters  []Parameter `yaml:"parameters"`
+	// Columns to output in display order, defaults to all columns sorted by name
+	Columns []string `yaml:"columns"`
+	SQL     string   `yaml:"-"`
+}
+
+const frontMatterDelimiter = "-- ---"
+
+var (
+	namePattern  = regexp.MustCompile(`^[a-z0-9_]+$`)
+	paramPattern = regexp.MustCompile(`:'([a-z0-9_]+)'`)
+)
+
+// Loads custom queries sorted by name, returning nil if the directory does not exist.
+// Invalid query files are skipped with a warning so that other queries remain usable.
+func LoadCustomQueries(fsys afero.Fs) ([]CustomQuery, error) {
+	paths, err := afero.Glob(fsys, filepath.Join(utils.InspectDir, "*.sql"))
+	if err != nil {
+		return nil, errors.Errorf("failed to glob custom queries: %w", err)
+	}
+	builtin, err := builtinNames()
+	if err != nil {
+		return nil, err
+	}
+	var result []CustomQuery
+	for _, fp := range paths {
+		name := strings.TrimSuffix(filepath.Base(fp), ".sql")
+		if utils.SliceContains(builtin, name) {
+			fmt.Fprintln(os.Stderr, "Skipping custom query that conflicts with a built-in query:", fp)
+			continue
+		}
+		contents, err := afero.ReadFile(fsys, fp)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping custom query %s: failed to read file: %v\n", fp, err)
+			continue
+		}
+		query, err := ParseCustomQuery(name, contents)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping custom query %s: %v\n", fp, err)
+			continue
+		}
+		result = append(result, query)
+	}
+	return result, nil
+}
+
+func builtinNames() ([]string, error) {
+	var names []string
+	err := fs.WalkDir(queries, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return errors.Errorf("failed to walk queries: %w", err)
+		}
+		if !d.IsDir() {
+			names = append(names, strings.Split(d.Name(), ".")[0])
+		}
+		return nil
+	})
+	return names, err
+}
+
+// Parses the optional front-matter of a custom query, written as yaml in sql comments:
+//
+//	-- ---
+//	-- description: Tables without a primary key
+//	-- parameters:
+//	--   - name: schema
+//	--     default: public
+//	-- columns: [schema, table]
+//	-- ---
+func ParseCustomQuery(name string, contents []byte) (CustomQuery, error) {
+	result := CustomQuery{Name: name}
+	if !namePattern.MatchString(name) {
+		return result, errors.Errorf("invalid query name: %s", name)
+	}
+	var header, body []string
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	inHeader := false
+	for i := 0; scanner.Scan(); i++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if i == 0 && trimmed == frontMatterDelimiter {
+			inHeader = true
+		} else if inHeader && trimmed == frontMatterDelimiter {
+			inHeader = false
+		} else if inHeader {
+			if !strings.HasPrefix(trimmed, "--") {
+				return result, errors.New("front-matter must be closed with: " + frontMatterDelimiter)
+			}
+			line = strings.TrimPrefix(strings.TrimLeft(line, " \t"), "--")
+			header = append(header, strings.TrimPrefix(line, " "))
+		} else {
+			body = append(body, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return result, errors.Errorf("failed to read query: %w", err)
+	} else if inHeader {
+		return result, errors.New("front-matter must be closed with: " + frontMatterDelimiter)
+	}
+	if len(header) > 0 {
+		dec := yaml.NewDecoder(strings.NewReader(strings.Join(header, "\n")))
+		dec.KnownFields(true)
+		if err := dec.Decode(&result); err != nil {
+			return result, errors.Errorf("failed to parse front-matter: %w", err)
+		}
+	}
+	for _, p := range result.Parameters {
+		if !namePattern.MatchString(p.Name) {
+			return result, errors.Errorf("invalid parameter name: %s", p.Name)
+		}
+	}
+	result.SQL = strings.TrimSpace(strings.Join(body, "\n"))
+	if len(result.SQL) == 0 {
+		return result, errors.New("query must not be empty")
+	}
+	return result, nil
+}
+
+// Substitutes :'name' placeholders with quoted parameter values, falling back to defaults.
+func (q CustomQuery) Render(values map[string]string) (string, error) {
+	literals := make(map[string]string, len(q.Parameters))
+	for _, p := range q.Parameters {
+		value, ok := values[p.Name]
+		if !ok && p.Default != nil {
+			value, ok = *p.Default, true
+		}
+		if !ok {
+			return "", errors.Errorf("missing value for parameter: %s", p.Name)
+		}
+		literals[p.Name] = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	var err error
+	sql := paramPattern.ReplaceAllStringFunc(q.SQL, func(match string) string {
+		name := paramPattern.FindStringSubmatch(match)[1]
+		if literal, ok := literals[name]; ok {
+			return literal
+		}
+		err = errors.Errorf("undeclared parameter: %s", name)
+		return match
+	})
+	return sql, err
+}
+
+func RunCustom(ctx context.Context, query CustomQuery, values map[string]string, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
+	sql, err := query.Render(values)
+	if err != nil {
+		return err
+	}
+	conn, err := utils.ConnectByConfig(ctx, config, options...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+	var report string
+	if err := conn.QueryRow(ctx, wrapJSON([]string{query.Name}, []string{sql})).Scan(&report); err != nil {
+		return errors.Errorf("failed to query rows: %w", err)
+	}
+	var result map[string][]map[string]any
+	if err := json.Unmarshal([]byte(report), &result); err != nil {
+		return errors.Errorf("failed to parse rows: %w", err)
+	}
+	rows := result[query.Name]
+	columns := query.Columns
+	if len(columns) == 0 && len(rows) > 0 {
+		for k := range rows[0] {
+			columns = append(columns, k)
+		}
+		sort.Strings(columns)
+	}
+	switch utils.OutputFormat.Value {
+	case utils.OutputPretty:
+		return list.RenderTable(toMarkdown(columns, rows))
+	case utils.OutputCsv:
+		w := csv.NewWriter(os.Stdout)
+		records := [][]string{columns}
+		for _, r := range rows {
+			records = append(records, formatRow(columns, r))
+		}
+		if err := w.WriteAll(records); err != nil {
+			return errors.Errorf("failed to write csv: %w", err)
+		}
+		return nil
+	}
+	return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, rows)
+}
+
+func formatRow(columns []string, row map[string]any) []string {
+	result := make([]string, len(columns))
+	for i, c := range columns {
+		switch v := row[c].(type) {
+		case nil:
+		case string:
+			result[i] = v
+		case float64:
+			result[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case map[string]any, []any:
+			data, _ := json.Marshal(v)
+			result[i] = string(data)
+		default:
+			result[i] = fmt.Sprint(v)
+		}
+	}
+	return result
+}
+
+func toMarkdown(columns []string, rows []map[string]any) string {
+	var table strings.Builder
+	table.WriteString("|" + strings.Join(columns, "|") + "|\n")
+	table.WriteString(strings.Repeat("|-", len(columns)) + "|\n")
+	for _, r := range rows {
+		values := formatRow(columns, r)
+		for i, v := range values {
+			values[i] = "`" + strings.ReplaceAll(v, "|", `\|`) + "`"
+		}
+		table.WriteString("|" + strings.Join(values, "|") + "|\n")
+	}
+	return table.String()
+}
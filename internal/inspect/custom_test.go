@@ -0,0 +1,161 @@
+package inspect
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/pkg/pgtest"
+)
+
+const missingPks = `-- ---
+-- description: Tables without a primary key
+-- parameters:
+--   - name: schema
+--     description: Schema to inspect
+--     default: public
+-- columns: [schema, table]
+-- ---
+SELECT n.nspname AS schema, c.relname AS table
+FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind = 'r' AND n.nspname = :'schema'
+`
+
+func TestParseCustomQuery(t *testing.T) {
+	t.Run("parses front-matter", func(t *testing.T) {
+		query, err := ParseCustomQuery("missing_pks", []byte(missingPks))
+		assert.NoError(t, err)
+		assert.Equal(t, "Tables without a primary key", query.Description)
+		require.Len(t, query.Parameters, 1)
+		assert.Equal(t, "schema", query.Parameters[0].Name)
+		assert.Equal(t, "public", *query.Parameters[0].Default)
+		assert.Equal(t, []string{"schema", "table"}, query.Columns)
+		assert.Equal(t, "SELECT n.nspname AS schema", query.SQL[:26])
+	})
+
+	t.Run("parses query without front-matter", func(t *testing.T) {
+		query, err := ParseCustomQuery("one", []byte("-- comment\nSELECT 1"))
+		assert.NoError(t, err)
+		assert.Empty(t, query.Description)
+		assert.Equal(t, "-- comment\nSELECT 1", query.SQL)
+	})
+
+	t.Run("throws error on unclosed front-matter", func(t *testing.T) {
+		_, err := ParseCustomQuery("one", []byte("-- ---\n-- description: test\nSELECT 1"))
+		assert.ErrorContains(t, err, "front-matter must be closed with: -- ---")
+	})
+
+	t.Run("throws error on unknown field", func(t *testing.T) {
+		_, err := ParseCustomQuery("one", []byte("-- ---\n-- title: test\n-- ---\nSELECT 1"))
+		assert.ErrorContains(t, err, "field title not found")
+	})
+
+	t.Run("throws error on invalid name", func(t *testing.T) {
+		_, err := ParseCustomQuery("missing-pks", []byte("SELECT 1"))
+		assert.ErrorContains(t, err, "invalid query name: missing-pks")
+	})
+}
+
+func TestRenderCustomQuery(t *testing.T) {
+	query, err := ParseCustomQuery("missing_pks", []byte(missingPks))
+	require.NoError(t, err)
+
+	t.Run("uses default value", func(t *testing.T) {
+		sql, err := query.Render(nil)
+		assert.NoError(t, err)
+		assert.Contains(t, sql, "n.nspname = 'public'")
+	})
+
+	t.Run("quotes parameter value", func(t *testing.T) {
+		sql, err := query.Render(map[string]string{"schema": "o'brien"})
+		assert.NoError(t, err)
+		assert.Contains(t, sql, "n.nspname = 'o''brien'")
+	})
+
+	t.Run("throws error on missing value", func(t *testing.T) {
+		query := CustomQuery{Parameters: []Parameter{{Name: "schema"}}, SQL: "SELECT :'schema'"}
+		_, err := query.Render(nil)
+		assert.ErrorContains(t, err, "missing value for parameter: schema")
+	})
+
+	t.Run("throws error on undeclared parameter", func(t *testing.T) {
+		query := CustomQuery{SQL: "SELECT :'schema'"}
+		_, err := query.Render(nil)
+		assert.ErrorContains(t, err, "undeclared parameter: schema")
+	})
+}
+
+func TestLoadCustomQueries(t *testing.T) {
+	t.Run("loads queries from project", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, filepath.Join(utils.InspectDir, "missing_pks.sql"), []byte(missingPks), 0644))
+		require.NoError(t, afero.WriteFile(fsys, filepath.Join(utils.InspectDir, "README.md"), []byte("# Queries"), 0644))
+		// Run test
+		queries, err := LoadCustomQueries(fsys)
+		// Check error
+		assert.NoError(t, err)
+		require.Len(t, queries, 1)
+		assert.Equal(t, "missing_pks", queries[0].Name)
+	})
+
+	t.Run("ignores missing directory", func(t *testing.T) {
+		queries, err := LoadCustomQueries(afero.NewMemMapFs())
+		assert.NoError(t, err)
+		assert.Empty(t, queries)
+	})
+
+	t.Run("skips built-in name", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, filepath.Join(utils.InspectDir, "cache.sql"), []byte("SELECT 1"), 0644))
+		// Run test
+		queries, err := LoadCustomQueries(fsys)
+		// Check error
+		assert.NoError(t, err)
+		assert.Empty(t, queries)
+	})
+
+	t.Run("skips invalid query files", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fsys, filepath.Join(utils.InspectDir, "broken.sql"), []byte("-- ---\n-- unknown: true\n-- ---\nSELECT 1"), 0644))
+		require.NoError(t, afero.WriteFile(fsys, filepath.Join(utils.InspectDir, "Invalid-Name.sql"), []byte("SELECT 1"), 0644))
+		require.NoError(t, afero.WriteFile(fsys, filepath.Join(utils.InspectDir, "missing_pks.sql"), []byte(missingPks), 0644))
+		// Run test
+		queries, err := LoadCustomQueries(fsys)
+		// Check error
+		assert.NoError(t, err)
+		require.Len(t, queries, 1)
+		assert.Equal(t, "missing_pks", queries[0].Name)
+	})
+}
+
+func TestRunCustomQuery(t *testing.T) {
+	query, err := ParseCustomQuery("missing_pks", []byte(missingPks))
+	require.NoError(t, err)
+
+	t.Run("runs query with parameters", func(t *testing.T) {
+		sql, err := query.Render(map[string]string{"schema": "private"})
+		require.NoError(t, err)
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query(wrapJSON([]string{"missing_pks"}, []string{sql})).
+			Reply("SELECT 1", []interface{}{`{"missing_pks":[{"schema":"private","table":"logs"}]}`})
+		// Run test
+		err = RunCustom(context.Background(), query, map[string]string{"schema": "private"}, dbConfig, afero.NewMemMapFs(), conn.Intercept)
+		// Check error
+		assert.NoError(t, err)
+	})
+}
+
+func TestFormatCustomRows(t *testing.T) {
+	rows := []map[string]any{{"name": "users", "size": 1048576.0, "options": []any{"fillfactor=70"}, "owner": nil}}
+	assert.Equal(t, "|name|size|options|owner|\n|-|-|-|-|\n|`users`|`1048576`|`[\"fillfactor=70\"]`|``|\n",
+		toMarkdown([]string{"name", "size", "options", "owner"}, rows))
+}
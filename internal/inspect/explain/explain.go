@@ -0,0 +1,296 @@
+package explain
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/migration/list"
+	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/pkg/pgxv5"
+)
+
+//go:embed queries/statements.sql
+var StatementsQuery string
+
+const (
+	// Sequential scans on tables with more estimated rows are flagged
+	LargeTableRows = 10000
+	// Row estimates off by more than this factor are flagged
+	MisestimateFactor = 10
+)
+
+// Implemented by both pgx.Conn and pgx.Tx so statements can be explained inside a savepoint.
+type Querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+type Statement struct {
+	Queryid int64  `json:"queryid"`
+	Query   string `json:"query"`
+}
+
+type Plan struct {
+	NodeType      string   `json:"Node Type"`
+	RelationName  string   `json:"Relation Name,omitempty"`
+	Schema        string   `json:"Schema,omitempty"`
+	Alias         string   `json:"Alias,omitempty"`
+	IndexName     string   `json:"Index Name,omitempty"`
+	Filter        string   `json:"Filter,omitempty"`
+	IndexCond     string   `json:"Index Cond,omitempty"`
+	RecheckCond   string   `json:"Recheck Cond,omitempty"`
+	JoinFilter    string   `json:"Join Filter,omitempty"`
+	HashCond      string   `json:"Hash Cond,omitempty"`
+	MergeCond     string   `json:"Merge Cond,omitempty"`
+	SortKey       []string `json:"Sort Key,omitempty"`
+	StartupCost   float64  `json:"Startup Cost"`
+	TotalCost     float64  `json:"Total Cost"`
+	PlanRows      float64  `json:"Plan Rows"`
+	ActualRows    *float64 `json:"Actual Rows,omitempty"`
+	ActualLoops   *float64 `json:"Actual Loops,omitempty"`
+	SortMethod    string   `json:"Sort Method,omitempty"`
+	SortSpaceUsed int64    `json:"Sort Space Used,omitempty"`
+	SortSpaceType string   `json:"Sort Space Type,omitempty"`
+	Plans         []Plan   `json:"Plans,omitempty"`
+}
+
+type Result struct {
+	Statement
+	// True if the plan was generated without parameter values
+	Generic  bool     `json:"generic"`
+	Analyzed bool     `json:"analyzed"`
+	Plan     *Plan    `json:"plan,omitempty"`
+	Findings []string `json:"findings"`
+	Error    string   `json:"error,omitempty"`
+}
+
+type Options struct {
+	Top     uint
+	QueryId int64
+	// Executes statements in a rolled back transaction
+	Analyze bool
+}
+
+func Run(ctx context.Context, opts Options, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
+	conn, err := utils.ConnectByConfig(ctx, config, options...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+	limit := opts.Top
+	if opts.QueryId != 0 {
+		limit = 1
+	}
+	rows, err := conn.Query(ctx, StatementsQuery, opts.QueryId, limit)
+	if err != nil {
+		return errors.Errorf("failed to query rows: %w", err)
+	}
+	stmts, err := pgxv5.CollectRows[Statement](rows)
+	if err != nil {
+		return err
+	}
+	if len(stmts) == 0 && opts.QueryId != 0 {
+		return errors.Errorf("query id not found in pg_stat_statements: %d", opts.QueryId)
+	}
+	result := make([]Result, len(stmts))
+	for i, s := range stmts {
+		result[i] = ExplainStatement(ctx, s, opts.Analyze, conn)
+	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result)
+	}
+	return list.RenderTable(toMarkdown(result))
+}
+
+var paramPattern = regexp.MustCompile(`\$(\d+)`)
+
+// Counts the parameters of a normalised statement by its highest placeholder.
+func countParams(query string) int {
+	var count int
+	for _, m := range paramPattern.FindAllStringSubmatch(query, -1) {
+		var n int
+		fmt.Sscanf(m[1], "%d", &n)
+		count = max(count, n)
+	}
+	return count
+}
+
+// Explains a single statement, recording any failure in the result instead of aborting.
+func ExplainStatement(ctx context.Context, s Statement, analyze bool, conn Querier) Result {
+	result := Result{Statement: s, Findings: []string{}}
+	plan, err := explainPlan(ctx, &result, analyze, conn)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Plan = plan
+	result.Findings = findIssues(ctx, plan, conn)
+	return result
+}
+
+// Explains a single statement without analyzing it or checking for issues.
+func ExplainPlan(ctx context.Context, s Statement, conn Querier) (*Plan, error) {
+	result := Result{Statement: s}
+	return explainPlan(ctx, &result, false, conn)
+}
+
+func explainPlan(ctx context.Context, result *Result, analyze bool, conn Querier) (*Plan, error) {
+	// Prepared statements outlive the transaction so they are deallocated after rollback
+	var prepared string
+	defer func() {
+		if len(prepared) == 0 {
+			return
+		}
+		if _, err := conn.Exec(context.Background(), "DEALLOCATE "+prepared); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, errors.Errorf("failed to begin transaction: %w", err)
+	}
+	// Always rollback since analyze executes the statement
+	defer func() {
+		if err := tx.Rollback(context.Background()); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+	query := strings.TrimRight(strings.TrimSpace(result.Query), ";")
+	var explain string
+	if n := countParams(query); n > 0 {
+		// Placeholder values are irrelevant when the generic plan is forced
+		result.Generic = true
+		if _, err := tx.Exec(ctx, "SET LOCAL plan_cache_mode = force_generic_plan"); err != nil {
+			return nil, errors.Errorf("failed to set plan cache mode: %w", err)
+		}
+		// Query ids may be negative, which are not valid in identifiers
+		name := fmt.Sprintf("supabase_explain_%x", uint64(result.Queryid))
+		if _, err := tx.Exec(ctx, fmt.Sprintf("PREPARE %s AS %s", name, query)); err != nil {
+			return nil, errors.Errorf("failed to prepare statement: %w", err)
+		}
+		prepared = name
+		args := strings.TrimSuffix(strings.Repeat("NULL, ", n), ", ")
+		explain = fmt.Sprintf("EXPLAIN (VERBOSE, FORMAT JSON) EXECUTE %s(%s)", name, args)
+	} else if analyze {
+		result.Analyzed = true
+		explain = "EXPLAIN (ANALYZE, VERBOSE, FORMAT JSON) " + query
+	} else {
+		explain = "EXPLAIN (VERBOSE, FORMAT JSON) " + query
+	}
+	var output string
+	if err := tx.QueryRow(ctx, explain).Scan(&output); err != nil {
+		return nil, errors.Errorf("failed to explain statement: %w", err)
+	}
+	var plans []struct {
+		Plan Plan `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(output), &plans); err != nil {
+		return nil, errors.Errorf("failed to parse plan: %w", err)
+	} else if len(plans) == 0 {
+		return nil, errors.New("empty plan")
+	}
+	return &plans[0].Plan, nil
+}
+
+const estimateRowsQuery = `SELECT c.reltuples::float8
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = $1 AND c.relname = $2`
+
+func findIssues(ctx context.Context, root *Plan, conn Querier) []string {
+	findings := []string{}
+	var walk func(p *Plan)
+	walk = func(p *Plan) {
+		if p.NodeType == "Seq Scan" && len(p.RelationName) > 0 {
+			var rows float64
+			if err := conn.QueryRow(ctx, estimateRowsQuery, p.Schema, p.RelationName).Scan(&rows); err != nil {
+				fmt.Fprintln(os.Stderr, "failed to estimate table rows:", err)
+			} else if rows >= LargeTableRows {
+				findings = append(findings, fmt.Sprintf("Sequential scan on large table %s (%.0f rows)", qualifiedName(p), rows))
+			}
+		}
+		if p.ActualRows != nil {
+			actual, planned := max(*p.ActualRows, 1), max(p.PlanRows, 1)
+			if actual >= planned*MisestimateFactor || planned >= actual*MisestimateFactor {
+				findings = append(findings, fmt.Sprintf("Row misestimate on %s: planned %.0f, actual %.0f", describe(p), p.PlanRows, *p.ActualRows))
+			}
+		}
+		if p.SortSpaceType == "Disk" {
+			findings = append(findings, fmt.Sprintf("Sort spilled to disk using %d kB (%s)", p.SortSpaceUsed, p.SortMethod))
+		}
+		for i := range p.Plans {
+			walk(&p.Plans[i])
+		}
+	}
+	walk(root)
+	return findings
+}
+
+func qualifiedName(p *Plan) string {
+	if len(p.Schema) > 0 {
+		return p.Schema + "." + p.RelationName
+	}
+	return p.RelationName
+}
+
+func describe(p *Plan) string {
+	desc := p.NodeType
+	if len(p.IndexName) > 0 {
+		desc += " using " + p.IndexName
+	}
+	if len(p.RelationName) > 0 {
+		desc += " on " + qualifiedName(p)
+	}
+	return desc
+}
+
+// Renders a compact plan tree with one node per line.
+func FormatTree(p *Plan) string {
+	var sb strings.Builder
+	var walk func(p *Plan, depth int)
+	walk = func(p *Plan, depth int) {
+		if depth > 0 {
+			sb.WriteString(strings.Repeat("   ", depth-1) + "-> ")
+		}
+		fmt.Fprintf(&sb, "%s (cost=%.2f..%.2f rows=%.0f)", describe(p), p.StartupCost, p.TotalCost, p.PlanRows)
+		if p.ActualRows != nil && p.ActualLoops != nil {
+			fmt.Fprintf(&sb, " (actual rows=%.0f loops=%.0f)", *p.ActualRows, *p.ActualLoops)
+		}
+		sb.WriteString("\n")
+		for i := range p.Plans {
+			walk(&p.Plans[i], depth+1)
+		}
+	}
+	walk(p, 0)
+	return sb.String()
+}
+
+func toMarkdown(result []Result) string {
+	var md strings.Builder
+	for _, r := range result {
+		fmt.Fprintf(&md, "## Query %d\n\n```sql\n%s\n```\n\n", r.Queryid, strings.TrimSpace(r.Query))
+		if len(r.Error) > 0 {
+			fmt.Fprintf(&md, "Failed to explain: %s\n\n", r.Error)
+			continue
+		}
+		if r.Generic {
+			md.WriteString("Generic plan without parameter values:\n\n")
+		}
+		fmt.Fprintf(&md, "```\n%s```\n\n", FormatTree(r.Plan))
+		for _, f := range r.Findings {
+			fmt.Fprintf(&md, "- %s\n", f)
+		}
+		md.WriteString("\n")
+	}
+	return md.String()
+}
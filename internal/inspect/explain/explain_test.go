@@ -0,0 +1,165 @@
+package explain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/pkg/pgtest"
+)
+
+var dbConfig = pgconn.Config{
+	Host:     "127.0.0.1",
+	Port:     5432,
+	User:     "admin",
+	Password: "password",
+	Database: "postgres",
+}
+
+const seqScanPlan = `[{"Plan": {"Node Type": "Sort", "Startup Cost": 10, "Total Cost": 12.5, "Plan Rows": 100, "Actual Rows": 5000, "Actual Loops": 1, "Sort Method": "external merge", "Sort Space Used": 2048, "Sort Space Type": "Disk", "Plans": [
+	{"Node Type": "Seq Scan", "Relation Name": "users", "Schema": "public", "Startup Cost": 0, "Total Cost": 8, "Plan Rows": 100, "Actual Rows": 5000, "Actual Loops": 1}
+]}}]`
+
+func TestExplainCommand(t *testing.T) {
+	t.Run("explains generic plan", func(t *testing.T) {
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query(StatementsQuery, 0, 5).
+			Reply("SELECT 1", Statement{Queryid: 7882209385937582106, Query: "SELECT * FROM users WHERE id = $1"}).
+			Query("begin").
+			Reply("BEGIN").
+			Query("SET LOCAL plan_cache_mode = force_generic_plan").
+			Reply("SET").
+			Query("PREPARE supabase_explain_6d633bacbe0f381a AS SELECT * FROM users WHERE id = $1").
+			Reply("PREPARE").
+			Query("EXPLAIN (VERBOSE, FORMAT JSON) EXECUTE supabase_explain_6d633bacbe0f381a(NULL)").
+			Reply("EXPLAIN", []interface{}{`[{"Plan": {"Node Type": "Index Scan", "Index Name": "users_pkey", "Relation Name": "users", "Schema": "public", "Total Cost": 8.2, "Plan Rows": 1}}]`}).
+			Query("rollback").
+			Reply("ROLLBACK").
+			Query("DEALLOCATE supabase_explain_6d633bacbe0f381a").
+			Reply("DEALLOCATE")
+		// Run test
+		err := Run(context.Background(), Options{Top: 5}, dbConfig, afero.NewMemMapFs(), conn.Intercept)
+		// Check error
+		assert.NoError(t, err)
+	})
+
+	t.Run("explains statement by negative query id", func(t *testing.T) {
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query(StatementsQuery, int64(-3263195466395374838), 1).
+			Reply("SELECT 1", Statement{Queryid: -3263195466395374838, Query: "SELECT * FROM users WHERE id = $1"}).
+			Query("begin").
+			Reply("BEGIN").
+			Query("SET LOCAL plan_cache_mode = force_generic_plan").
+			Reply("SET").
+			Query("PREPARE supabase_explain_d2b6cd0330d6ab0a AS SELECT * FROM users WHERE id = $1").
+			Reply("PREPARE").
+			Query("EXPLAIN (VERBOSE, FORMAT JSON) EXECUTE supabase_explain_d2b6cd0330d6ab0a(NULL)").
+			Reply("EXPLAIN", []interface{}{`[{"Plan": {"Node Type": "Index Scan", "Index Name": "users_pkey", "Relation Name": "users", "Schema": "public", "Total Cost": 8.2, "Plan Rows": 1}}]`}).
+			Query("rollback").
+			Reply("ROLLBACK").
+			Query("DEALLOCATE supabase_explain_d2b6cd0330d6ab0a").
+			Reply("DEALLOCATE")
+		// Run test
+		err := Run(context.Background(), Options{QueryId: -3263195466395374838}, dbConfig, afero.NewMemMapFs(), conn.Intercept)
+		// Check error
+		assert.NoError(t, err)
+	})
+
+	t.Run("throws error on unknown query id", func(t *testing.T) {
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query(StatementsQuery, int64(7882209385937582106), 1).
+			Reply("SELECT 0")
+		// Run test
+		err := Run(context.Background(), Options{QueryId: 7882209385937582106}, dbConfig, afero.NewMemMapFs(), conn.Intercept)
+		// Check error
+		assert.ErrorContains(t, err, "query id not found in pg_stat_statements: 7882209385937582106")
+	})
+}
+
+func TestExplainStatement(t *testing.T) {
+	t.Run("analyzes statement and reports issues", func(t *testing.T) {
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query("begin").
+			Reply("BEGIN").
+			Query("EXPLAIN (ANALYZE, VERBOSE, FORMAT JSON) SELECT * FROM users ORDER BY name").
+			Reply("EXPLAIN", []interface{}{seqScanPlan}).
+			Query("rollback").
+			Reply("ROLLBACK").
+			Query(estimateRowsQuery, "public", "users").
+			Reply("SELECT 1", []interface{}{float64(50000)})
+		// Run test
+		result := ExplainStatement(context.Background(), Statement{Queryid: 7, Query: "SELECT * FROM users ORDER BY name;"}, true, conn.MockClient(t))
+		// Check result
+		assert.Empty(t, result.Error)
+		assert.True(t, result.Analyzed)
+		assert.False(t, result.Generic)
+		assert.Equal(t, []string{
+			"Row misestimate on Sort: planned 100, actual 5000",
+			"Sort spilled to disk using 2048 kB (external merge)",
+			"Sequential scan on large table public.users (50000 rows)",
+			"Row misestimate on Seq Scan on public.users: planned 100, actual 5000",
+		}, result.Findings)
+		require.NotNil(t, result.Plan)
+		assert.Equal(t, "Sort (cost=10.00..12.50 rows=100) (actual rows=5000 loops=1)\n"+
+			"-> Seq Scan on public.users (cost=0.00..8.00 rows=100) (actual rows=5000 loops=1)\n",
+			FormatTree(result.Plan))
+	})
+
+	t.Run("prepares statement with negative query id", func(t *testing.T) {
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query("begin").
+			Reply("BEGIN").
+			Query("SET LOCAL plan_cache_mode = force_generic_plan").
+			Reply("SET").
+			Query("PREPARE supabase_explain_ffffffffffffffd6 AS SELECT * FROM users WHERE id = $1").
+			Reply("PREPARE").
+			Query("EXPLAIN (VERBOSE, FORMAT JSON) EXECUTE supabase_explain_ffffffffffffffd6(NULL)").
+			Reply("EXPLAIN", []interface{}{`[{"Plan": {"Node Type": "Index Scan", "Index Name": "users_pkey", "Relation Name": "users", "Schema": "public", "Total Cost": 8.2, "Plan Rows": 1}}]`}).
+			Query("rollback").
+			Reply("ROLLBACK").
+			Query("DEALLOCATE supabase_explain_ffffffffffffffd6").
+			Reply("DEALLOCATE")
+		// Run test
+		result := ExplainStatement(context.Background(), Statement{Queryid: -42, Query: "SELECT * FROM users WHERE id = $1"}, false, conn.MockClient(t))
+		// Check result
+		assert.Empty(t, result.Error)
+		assert.True(t, result.Generic)
+		require.NotNil(t, result.Plan)
+	})
+
+	t.Run("records explain error", func(t *testing.T) {
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query("begin").
+			Reply("BEGIN").
+			Query("EXPLAIN (VERBOSE, FORMAT JSON) SELECT * FROM missing").
+			ReplyError(pgerrcode.UndefinedTable, `relation "missing" does not exist`).
+			Query("rollback").
+			Reply("ROLLBACK")
+		// Run test
+		result := ExplainStatement(context.Background(), Statement{Queryid: 7, Query: "SELECT * FROM missing"}, false, conn.MockClient(t))
+		// Check result
+		assert.Contains(t, result.Error, `relation "missing" does not exist`)
+		assert.Nil(t, result.Plan)
+	})
+}
+
+func TestCountParams(t *testing.T) {
+	assert.Equal(t, 0, countParams("SELECT 1"))
+	assert.Equal(t, 3, countParams("SELECT $1, $3 WHERE a = $2"))
+}
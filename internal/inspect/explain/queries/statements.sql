@@ -0,0 +1,9 @@
+SELECT
+  queryid,
+  query
+FROM pg_stat_statements
+WHERE userid = (SELECT usesysid FROM pg_user WHERE usename = current_user LIMIT 1)
+  AND query ~* '^\s*(select|insert|update|delete|with)\s'
+  AND ($1::bigint = 0 OR queryid = $1::bigint)
+ORDER BY total_exec_time DESC
+LIMIT $2
@@ -0,0 +1,323 @@
+package index_advisor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/inspect/explain"
+	"github.com/supabase/cli/internal/migration/list"
+	"github.com/supabase/cli/internal/migration/new"
+	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/pkg/pgxv5"
+)
+
+const (
+	ENABLE_HYPOPG    = "CREATE EXTENSION IF NOT EXISTS hypopg"
+	RESET_HYPOPG     = "SELECT hypopg_reset()"
+	CREATE_HYPOINDEX = "SELECT indexrelid FROM hypopg_create_index($1)"
+)
+
+type Options struct {
+	Top uint
+	// Minimum ratio of plan cost saved for an index to be recommended
+	MinImprovement float64
+	// Name of the migration to save chosen indexes to
+	Migration string
+}
+
+type Result struct {
+	Statement string `json:"statement"`
+	Queryid   int64  `json:"queryid"`
+	// Planner cost before and after adding the hypothetical index
+	CostBefore float64 `json:"cost_before"`
+	CostAfter  float64 `json:"cost_after"`
+	// Ratio of plan cost saved by the index
+	Improvement float64 `json:"improvement"`
+}
+
+func Run(ctx context.Context, opts Options, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
+	conn, err := utils.ConnectByConfig(ctx, config, options...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+	result, err := AdviseIndexes(ctx, opts, conn)
+	if err != nil {
+		return err
+	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		if err := utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result); err != nil {
+			return err
+		}
+	} else if len(result) == 0 {
+		fmt.Fprintln(os.Stderr, "No index recommendations found.")
+	} else {
+		table := "|Rank|Statement|Cost before|Cost after|Improvement|Query ID|\n|-|-|-|-|-|-|\n"
+		for i, r := range result {
+			table += fmt.Sprintf("|`%d`|`%s`|`%.2f`|`%.2f`|`%.1f%%`|`%d`|\n", i+1, r.Statement, r.CostBefore, r.CostAfter, r.Improvement*100, r.Queryid)
+		}
+		if err := list.RenderTable(table); err != nil {
+			return err
+		}
+	}
+	if len(opts.Migration) > 0 {
+		return saveMigration(ctx, opts.Migration, result, fsys)
+	}
+	return nil
+}
+
+// Tests candidate indexes for the top statements in a rolled back transaction,
+// returning the recommended ones ordered by their estimated improvement.
+func AdviseIndexes(ctx context.Context, opts Options, conn *pgx.Conn) ([]Result, error) {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, errors.Errorf("failed to begin transaction: %w", err)
+	}
+	// Always rollback so the hypopg extension is only enabled temporarily
+	defer func() {
+		if err := tx.Rollback(context.Background()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+	if _, err := tx.Exec(ctx, ENABLE_HYPOPG); err != nil {
+		return nil, errors.Errorf("failed to enable hypopg: %w", err)
+	}
+	rows, err := tx.Query(ctx, explain.StatementsQuery, 0, opts.Top)
+	if err != nil {
+		return nil, errors.Errorf("failed to query rows: %w", err)
+	}
+	stmts, err := pgxv5.CollectRows[explain.Statement](rows)
+	if err != nil {
+		return nil, err
+	}
+	best := map[string]Result{}
+	for _, s := range stmts {
+		base, err := explain.ExplainPlan(ctx, s, tx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping query %d: %v\n", s.Queryid, err)
+			continue
+		}
+		for _, c := range Candidates(base) {
+			cost, err := testCandidate(ctx, s, c, tx)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Skipping candidate %s: %v\n", c, err)
+				continue
+			} else if cost < 0 || base.TotalCost <= 0 {
+				continue
+			}
+			r := Result{
+				Statement:   c.String(),
+				Queryid:     s.Queryid,
+				CostBefore:  base.TotalCost,
+				CostAfter:   cost,
+				Improvement: (base.TotalCost - cost) / base.TotalCost,
+			}
+			if r.Improvement < opts.MinImprovement {
+				continue
+			}
+			if prev, ok := best[r.Statement]; !ok || r.Improvement > prev.Improvement {
+				best[r.Statement] = r
+			}
+		}
+	}
+	if _, err := tx.Exec(ctx, RESET_HYPOPG); err != nil {
+		return nil, errors.Errorf("failed to reset hypopg: %w", err)
+	}
+	result := make([]Result, 0, len(best))
+	for _, r := range best {
+		result = append(result, r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Improvement == result[j].Improvement {
+			return result[i].Statement < result[j].Statement
+		}
+		return result[i].Improvement > result[j].Improvement
+	})
+	return result, nil
+}
+
+// Returns the plan cost with a hypothetical candidate index, or -1 if the index is unused.
+func testCandidate(ctx context.Context, s explain.Statement, c Candidate, tx pgx.Tx) (float64, error) {
+	// Savepoint isolates errors from the outer transaction
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return 0, errors.Errorf("failed to create savepoint: %w", err)
+	}
+	defer func() {
+		if err := sp.Rollback(context.Background()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+	// Hypothetical indexes are not transactional so they must be reset explicitly
+	if _, err := sp.Exec(ctx, RESET_HYPOPG); err != nil {
+		return 0, errors.Errorf("failed to reset hypopg: %w", err)
+	}
+	var oid uint32
+	if err := sp.QueryRow(ctx, CREATE_HYPOINDEX, c.String()).Scan(&oid); err != nil {
+		return 0, errors.Errorf("failed to create hypothetical index: %w", err)
+	}
+	plan, err := explain.ExplainPlan(ctx, s, sp)
+	if err != nil {
+		return 0, err
+	}
+	if !usesIndex(plan, fmt.Sprintf("<%d>", oid)) {
+		return -1, nil
+	}
+	return plan.TotalCost, nil
+}
+
+func usesIndex(p *explain.Plan, prefix string) bool {
+	if strings.HasPrefix(p.IndexName, prefix) {
+		return true
+	}
+	for i := range p.Plans {
+		if usesIndex(&p.Plans[i], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type Candidate struct {
+	Schema  string
+	Table   string
+	Columns []string
+}
+
+func (c Candidate) String() string {
+	table := pgx.Identifier{c.Schema, c.Table}.Sanitize()
+	return fmt.Sprintf("CREATE INDEX ON %s USING btree (%s)", table, strings.Join(c.Columns, ", "))
+}
+
+// Matches qualified column references, such as users.email, in verbose plan output.
+var columnPattern = regexp.MustCompile(`("(?:[^"]|"")+"|[A-Za-z_][\w$]*)\.("(?:[^"]|"")+"|[A-Za-z_][\w$]*)`)
+
+type relation struct {
+	schema, table        string
+	filter, join, orders []string
+}
+
+// Proposes candidate indexes from the filter, join and sort columns of a plan.
+func Candidates(root *explain.Plan) []Candidate {
+	aliases := map[string]*relation{}
+	var names []string
+	var collect func(p *explain.Plan)
+	collect = func(p *explain.Plan) {
+		if len(p.RelationName) > 0 && len(p.Alias) > 0 && !isInternal(p.Schema) {
+			if _, ok := aliases[p.Alias]; !ok {
+				aliases[p.Alias] = &relation{schema: p.Schema, table: p.RelationName}
+				names = append(names, p.Alias)
+			}
+		}
+		for i := range p.Plans {
+			collect(&p.Plans[i])
+		}
+	}
+	collect(root)
+	var walk func(p *explain.Plan)
+	walk = func(p *explain.Plan) {
+		for _, expr := range []string{p.Filter, p.IndexCond, p.RecheckCond} {
+			addColumns(aliases, expr, func(r *relation) *[]string { return &r.filter })
+		}
+		for _, expr := range []string{p.JoinFilter, p.HashCond, p.MergeCond} {
+			addColumns(aliases, expr, func(r *relation) *[]string { return &r.join })
+		}
+		for _, expr := range p.SortKey {
+			addColumns(aliases, expr, func(r *relation) *[]string { return &r.orders })
+		}
+		for i := range p.Plans {
+			walk(&p.Plans[i])
+		}
+	}
+	walk(root)
+	var result []Candidate
+	seen := map[string]bool{}
+	add := func(r *relation, columns []string) {
+		c := Candidate{Schema: r.schema, Table: r.table, Columns: columns}
+		if key := c.String(); len(columns) > 0 && !seen[key] {
+			seen[key] = true
+			result = append(result, c)
+		}
+	}
+	for _, name := range names {
+		r := aliases[name]
+		for _, group := range [][]string{r.filter, r.join, r.orders} {
+			for _, col := range group {
+				add(r, []string{col})
+			}
+		}
+		if len(r.filter) > 1 {
+			add(r, r.filter)
+		}
+		if len(r.filter) > 0 && len(r.orders) > 0 {
+			add(r, appendUnique(append([]string{}, r.filter...), r.orders...))
+		}
+	}
+	return result
+}
+
+func addColumns(aliases map[string]*relation, expr string, target func(*relation) *[]string) {
+	for _, m := range columnPattern.FindAllStringSubmatch(expr, -1) {
+		if r, ok := aliases[strings.Trim(m[1], `"`)]; ok {
+			cols := target(r)
+			*cols = appendUnique(*cols, m[2])
+		}
+	}
+}
+
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		found := false
+		for _, v := range list {
+			if v == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func isInternal(schema string) bool {
+	for _, pattern := range utils.InternalSchemas {
+		if ok, _ := filepath.Match(pattern, schema); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func saveMigration(ctx context.Context, name string, result []Result, fsys afero.Fs) error {
+	console := utils.NewConsole()
+	var chosen []string
+	for _, r := range result {
+		title := fmt.Sprintf("Add %s to migration?", utils.Aqua(r.Statement))
+		if ok, err := console.PromptYesNo(ctx, title, true); err != nil {
+			return err
+		} else if ok {
+			chosen = append(chosen, r.Statement+";\n")
+		}
+	}
+	if len(chosen) == 0 {
+		fmt.Fprintln(os.Stderr, "No indexes chosen.")
+		return nil
+	}
+	path := new.GetMigrationPath(utils.GetCurrentTimestamp(), name)
+	if err := utils.WriteFile(path, []byte(strings.Join(chosen, "")), fsys); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Created new migration at "+utils.Bold(path))
+	return nil
+}
@@ -0,0 +1,162 @@
+package index_advisor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/internal/inspect/explain"
+	"github.com/supabase/cli/pkg/pgtest"
+)
+
+var dbConfig = pgconn.Config{
+	Host:     "127.0.0.1",
+	Port:     5432,
+	User:     "admin",
+	Password: "password",
+	Database: "postgres",
+}
+
+func TestCandidates(t *testing.T) {
+	t.Run("proposes filter, join and sort columns", func(t *testing.T) {
+		plan := explain.Plan{
+			NodeType: "Sort",
+			SortKey:  []string{"p.created_at DESC"},
+			Plans: []explain.Plan{{
+				NodeType: "Hash Join",
+				HashCond: "(p.author_id = u.id)",
+				Plans: []explain.Plan{
+					{NodeType: "Seq Scan", Schema: "public", RelationName: "posts", Alias: "p", Filter: "((p.status)::text = $1)"},
+					{NodeType: "Seq Scan", Schema: "public", RelationName: "users", Alias: "u"},
+					{NodeType: "Seq Scan", Schema: "auth", RelationName: "users", Alias: "au", Filter: "(au.id = $2)"},
+				},
+			}},
+		}
+		// Run test
+		candidates := Candidates(&plan)
+		// Check result
+		var statements []string
+		for _, c := range candidates {
+			statements = append(statements, c.String())
+		}
+		assert.Equal(t, []string{
+			`CREATE INDEX ON "public"."posts" USING btree (status)`,
+			`CREATE INDEX ON "public"."posts" USING btree (author_id)`,
+			`CREATE INDEX ON "public"."posts" USING btree (created_at)`,
+			`CREATE INDEX ON "public"."posts" USING btree (status, created_at)`,
+			`CREATE INDEX ON "public"."users" USING btree (id)`,
+		}, statements)
+	})
+
+	t.Run("ignores unknown aliases", func(t *testing.T) {
+		plan := explain.Plan{NodeType: "Result", Filter: "(public.now() > x.y)"}
+		assert.Empty(t, Candidates(&plan))
+	})
+}
+
+func TestIndexAdvisor(t *testing.T) {
+	const query = "SELECT * FROM users u WHERE u.email = $1"
+	stmt := explain.Statement{Queryid: 42, Query: query}
+	basePlan := `[{"Plan": {"Node Type": "Seq Scan", "Schema": "public", "Relation Name": "users", "Alias": "u", "Filter": "(u.email = $1)", "Total Cost": 100}}]`
+	indexPlan := `[{"Plan": {"Node Type": "Index Scan", "Index Name": "<13543>btree_users_email", "Schema": "public", "Relation Name": "users", "Alias": "u", "Total Cost": 8}}]`
+
+	// Mocks advising a single statement whose prepared name is derived from its query id
+	mockAdvise := func(conn *pgtest.MockConn, stmt explain.Statement, name string) {
+		conn.Query("begin").
+			Reply("BEGIN").
+			Query(ENABLE_HYPOPG).
+			Reply("CREATE EXTENSION").
+			Query(explain.StatementsQuery, 0, 10).
+			Reply("SELECT 1", stmt).
+			// Explain base plan
+			Query("savepoint sp_1").
+			Reply("SAVEPOINT").
+			Query("SET LOCAL plan_cache_mode = force_generic_plan").
+			Reply("SET").
+			Query("PREPARE "+name+" AS "+query).
+			Reply("PREPARE").
+			Query("EXPLAIN (VERBOSE, FORMAT JSON) EXECUTE "+name+"(NULL)").
+			Reply("EXPLAIN", []interface{}{basePlan}).
+			Query("rollback to savepoint sp_1").
+			Reply("ROLLBACK").
+			Query("DEALLOCATE "+name).
+			Reply("DEALLOCATE").
+			// Test candidate
+			Query("savepoint sp_2").
+			Reply("SAVEPOINT").
+			Query(RESET_HYPOPG).
+			Reply("SELECT 1", []interface{}{true}).
+			Query(CREATE_HYPOINDEX, `CREATE INDEX ON "public"."users" USING btree (email)`).
+			Reply("SELECT 1", []interface{}{uint32(13543)}).
+			Query("savepoint sp_3").
+			Reply("SAVEPOINT").
+			Query("SET LOCAL plan_cache_mode = force_generic_plan").
+			Reply("SET").
+			Query("PREPARE "+name+" AS "+query).
+			Reply("PREPARE").
+			Query("EXPLAIN (VERBOSE, FORMAT JSON) EXECUTE "+name+"(NULL)").
+			Reply("EXPLAIN", []interface{}{indexPlan}).
+			Query("rollback to savepoint sp_3").
+			Reply("ROLLBACK").
+			Query("DEALLOCATE "+name).
+			Reply("DEALLOCATE").
+			Query("rollback to savepoint sp_2").
+			Reply("ROLLBACK").
+			Query(RESET_HYPOPG).
+			Reply("SELECT 1", []interface{}{true}).
+			Query("rollback").
+			Reply("ROLLBACK")
+	}
+
+	t.Run("recommends hypothetical index", func(t *testing.T) {
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		mockAdvise(conn, stmt, "supabase_explain_2a")
+		// Run test
+		result, err := AdviseIndexes(context.Background(), Options{Top: 10, MinImprovement: 0.1}, conn.MockClient(t))
+		// Check error
+		assert.NoError(t, err)
+		assert.Equal(t, []Result{{
+			Statement:   `CREATE INDEX ON "public"."users" USING btree (email)`,
+			Queryid:     42,
+			CostBefore:  100,
+			CostAfter:   8,
+			Improvement: 0.92,
+		}}, result)
+	})
+
+	t.Run("prepares statement with negative query id", func(t *testing.T) {
+		negative := explain.Statement{Queryid: -42, Query: query}
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		mockAdvise(conn, negative, "supabase_explain_ffffffffffffffd6")
+		// Run test
+		result, err := AdviseIndexes(context.Background(), Options{Top: 10, MinImprovement: 0.1}, conn.MockClient(t))
+		// Check error
+		assert.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, int64(-42), result[0].Queryid)
+	})
+
+	t.Run("throws error on missing hypopg", func(t *testing.T) {
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query("begin").
+			Reply("BEGIN").
+			Query(ENABLE_HYPOPG).
+			ReplyError(pgerrcode.UndefinedFile, `extension "hypopg" is not available`).
+			Query("rollback").
+			Reply("ROLLBACK")
+		// Run test
+		err := Run(context.Background(), Options{Top: 10}, dbConfig, afero.NewMemMapFs(), conn.Intercept)
+		// Check error
+		assert.ErrorContains(t, err, `failed to enable hypopg: ERROR: extension "hypopg" is not available`)
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"os"
 
+	"github.com/docker/go-units"
 	"github.com/go-errors/errors"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
@@ -19,8 +21,9 @@ import (
 var IndexSizesQuery string
 
 type Result struct {
-	Name string
-	Size string
+	Name string `json:"name"`
+	// Size in bytes
+	Size int64 `json:"size"`
 }
 
 func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
@@ -37,10 +40,13 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 	if err != nil {
 		return err
 	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result)
+	}
 
 	table := "|Name|size|\n|-|-|\n"
 	for _, r := range result {
-		table += fmt.Sprintf("|`%s`|`%s`|\n", r.Name, r.Size)
+		table += fmt.Sprintf("|`%s`|`%s`|\n", r.Name, units.BytesSize(float64(r.Size)))
 	}
 	return list.RenderTable(table)
 }
@@ -1,6 +1,6 @@
 SELECT
   n.nspname || '.' || c.relname AS name,
-  pg_size_pretty(sum(c.relpages::bigint*8192)::bigint) AS size
+  sum(c.relpages::bigint*8192)::bigint AS size
 FROM pg_class c
 LEFT JOIN pg_namespace n ON (n.oid = c.relnamespace)
 WHERE NOT n.nspname LIKE ANY($1)
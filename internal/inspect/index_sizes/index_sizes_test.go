@@ -30,7 +30,7 @@ func TestIndexSizes(t *testing.T) {
 		conn.Query(IndexSizesQuery, reset.LikeEscapeSchema(utils.InternalSchemas)).
 			Reply("SELECT 1", Result{
 				Name: "test_table_idx",
-				Size: "100GB",
+				Size: 100 << 30,
 			})
 		// Run test
 		err := Run(context.Background(), dbConfig, fsys, conn.Intercept)
@@ -4,6 +4,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"os"
 
 	"github.com/go-errors/errors"
 	"github.com/jackc/pgconn"
@@ -19,9 +20,10 @@ import (
 var IndexUsageQuery string
 
 type Result struct {
-	Name                        string
-	Percent_of_times_index_used string
-	Rows_in_table               int64
+	Name string `json:"name"`
+	// Zero if the table has no index scans
+	Percent_of_times_index_used float64 `json:"percent_of_times_index_used"`
+	Rows_in_table               int64   `json:"rows_in_table"`
 }
 
 func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
@@ -38,10 +40,17 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 	if err != nil {
 		return err
 	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result)
+	}
 	// TODO: implement a markdown table marshaller
 	table := "|Table name|Percentage of times index used|Rows in table|\n|-|-|-|\n"
 	for _, r := range result {
-		table += fmt.Sprintf("|`%s`|`%s`|`%d`|\n", r.Name, r.Percent_of_times_index_used, r.Rows_in_table)
+		percent := "Insufficient data"
+		if r.Percent_of_times_index_used > 0 {
+			percent = fmt.Sprintf("%.1f%%", r.Percent_of_times_index_used)
+		}
+		table += fmt.Sprintf("|`%s`|`%s`|`%d`|\n", r.Name, percent, r.Rows_in_table)
 	}
 	return list.RenderTable(table)
 }
@@ -1,10 +1,10 @@
 SELECT
   schemaname || '.' || relname AS name,
   CASE
-    WHEN idx_scan IS NULL THEN 'Insufficient data'
-    WHEN idx_scan = 0 THEN 'Insufficient data'
-    ELSE ROUND(100.0 * idx_scan / (seq_scan + idx_scan), 1) || '%'
-  END percent_of_times_index_used,
+    WHEN idx_scan IS NULL THEN 0
+    WHEN idx_scan = 0 THEN 0
+    ELSE ROUND(100.0 * idx_scan / (seq_scan + idx_scan), 1)
+  END::float8 AS percent_of_times_index_used,
   n_live_tup rows_in_table
 FROM pg_stat_user_tables
 WHERE NOT schemaname LIKE ANY($1)
@@ -30,7 +30,7 @@ func TestIndexUsage(t *testing.T) {
 		conn.Query(IndexUsageQuery, reset.LikeEscapeSchema(utils.InternalSchemas)).
 			Reply("SELECT 1", Result{
 				Name:                        "test_table_idx",
-				Percent_of_times_index_used: "0.9",
+				Percent_of_times_index_used: 0.9,
 				Rows_in_table:               300,
 			})
 		// Run test
@@ -4,6 +4,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"os"
 	"regexp"
 
 	"github.com/go-errors/errors"
@@ -19,12 +20,13 @@ import (
 var LocksQuery string
 
 type Result struct {
-	Pid           int
-	Relname       string
-	Transactionid string
-	Granted       bool
-	Query         string
-	Age           string
+	Pid           int    `json:"pid"`
+	Relname       string `json:"relname"`
+	Transactionid string `json:"transactionid"`
+	Granted       bool   `json:"granted"`
+	Query         string `json:"query"`
+	// Age in seconds
+	Age float64 `json:"age"`
 }
 
 func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
@@ -41,6 +43,9 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 	if err != nil {
 		return err
 	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result)
+	}
 
 	table := "|pid|relname|transaction id|granted|query|age|\n|-|-|-|-|-|-|\n"
 	for _, r := range result {
@@ -51,7 +56,7 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 		// escape pipes in query
 		re = regexp.MustCompile(`\|`)
 		query = re.ReplaceAllString(query, `\|`)
-		table += fmt.Sprintf("|`%d`|`%s`|`%s`|`%t`|%s|`%s`|\n", r.Pid, r.Relname, r.Transactionid, r.Granted, query, r.Age)
+		table += fmt.Sprintf("|`%d`|`%s`|`%s`|`%t`|%s|`%s`|\n", r.Pid, r.Relname, r.Transactionid, r.Granted, query, utils.FormatDuration(r.Age))
 	}
 	return list.RenderTable(table)
 }
@@ -4,7 +4,7 @@ SELECT
   COALESCE(pg_locks.transactionid, 'null') AS transactionid,
   pg_locks.granted,
   pg_stat_activity.query,
-  age(now(), pg_stat_activity.query_start)::text AS age
+  extract(epoch FROM age(now(), pg_stat_activity.query_start))::float8 AS age
 FROM pg_stat_activity, pg_locks LEFT OUTER JOIN pg_class ON (pg_locks.relation = pg_class.oid)
 WHERE pg_stat_activity.query <> '<insufficient privilege>'
 AND pg_locks.pid = pg_stat_activity.pid
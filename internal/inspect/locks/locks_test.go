@@ -32,7 +32,7 @@ func TestLocksCommand(t *testing.T) {
 				Transactionid: "9301",
 				Granted:       true,
 				Query:         "select 1",
-				Age:           "300ms",
+				Age:           0.3,
 			})
 		// Run test
 		err := Run(context.Background(), dbConfig, fsys, conn.Intercept)
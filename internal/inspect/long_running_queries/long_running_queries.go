@@ -4,6 +4,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"os"
 
 	"github.com/go-errors/errors"
 	"github.com/jackc/pgconn"
@@ -18,9 +19,10 @@ import (
 var LongRunningQueriesQuery string
 
 type Result struct {
-	Pid      int
-	Duration string
-	Query    string
+	Pid int `json:"pid"`
+	// Duration in seconds
+	Duration float64 `json:"duration"`
+	Query    string  `json:"query"`
 }
 
 func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
@@ -37,10 +39,13 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 	if err != nil {
 		return err
 	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result)
+	}
 
 	table := "|pid|Duration|Query|\n|-|-|-|\n"
 	for _, r := range result {
-		table += fmt.Sprintf("|`%d`|`%s`|`%s`|\n", r.Pid, r.Duration, r.Query)
+		table += fmt.Sprintf("|`%d`|`%s`|`%s`|\n", r.Pid, utils.FormatDuration(r.Duration), r.Query)
 	}
 	return list.RenderTable(table)
 }
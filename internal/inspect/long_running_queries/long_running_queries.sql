@@ -1,6 +1,6 @@
 SELECT
   pid,
-  age(now(), pg_stat_activity.query_start)::text AS duration,
+  extract(epoch FROM age(now(), pg_stat_activity.query_start))::float8 AS duration,
   query AS query
 FROM
   pg_stat_activity
@@ -28,7 +28,7 @@ func TestLongQueriesCommand(t *testing.T) {
 		conn.Query(LongRunningQueriesQuery).
 			Reply("SELECT 1", Result{
 				Pid:      1,
-				Duration: "300ms",
+				Duration: 0.3,
 				Query:    "select 1",
 			})
 		// Run test
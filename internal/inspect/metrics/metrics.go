@@ -0,0 +1,215 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/inspect"
+	"github.com/supabase/cli/internal/utils"
+)
+
+const namespace = "supabase_inspect"
+
+// Maps a column of an inspect query to a gauge, with other columns as labels.
+type gauge struct {
+	query  string
+	column string
+	labels []string
+	desc   *prometheus.Desc
+	// Counts rows per label set instead of reading the column
+	count bool
+}
+
+func newGauge(query, name, help, column string, labels ...string) gauge {
+	return gauge{
+		query:  query,
+		column: column,
+		labels: labels,
+		desc:   prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, labels, nil),
+	}
+}
+
+func newCounter(query, name, help string, labels ...string) gauge {
+	g := newGauge(query, name, help, "", labels...)
+	g.count = true
+	return g
+}
+
+var gauges = []gauge{
+	newGauge("cache", "cache_hit_ratio", "Ratio of blocks read from the buffer cache.", "ratio", "name"),
+	newGauge("bloat", "bloat_ratio", "Estimated ratio of bloat in tables and indexes.", "bloat", "type", "schemaname", "object_name"),
+	newGauge("bloat", "bloat_waste_bytes", "Estimated wasted space in tables and indexes.", "waste", "type", "schemaname", "object_name"),
+	newGauge("table_sizes", "table_size_bytes", "Size of each table excluding indexes.", "size", "schema", "name"),
+	newGauge("total_table_sizes", "table_total_size_bytes", "Size of each table including indexes.", "size", "schema", "name"),
+	newGauge("index_sizes", "index_size_bytes", "Size of each index.", "size", "name"),
+	newGauge("vacuum_stats", "table_rows", "Estimated number of rows in each table.", "rowcount", "schema", "table"),
+	newGauge("vacuum_stats", "table_dead_rows", "Number of dead rows in each table.", "dead_rowcount", "schema", "table"),
+	newGauge("vacuum_stats", "table_autovacuum_threshold_rows", "Number of dead rows that triggers autovacuum.", "autovacuum_threshold", "schema", "table"),
+	newCounter("locks", "exclusive_locks", "Number of exclusive locks held or awaited.", "granted"),
+	newGauge("replication_slots", "replication_slot_lag_bytes", "Replication lag of each slot.", "replication_lag", "slot_name"),
+	newGauge("replication_slots", "replication_slot_active", "Whether each replication slot is active.", "active", "slot_name"),
+}
+
+var (
+	upDesc       = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "up"), "Whether the last scrape of the database succeeded.", nil, nil)
+	durationDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "scrape_duration_seconds"), "Duration of the last scrape of the database.", nil, nil)
+)
+
+// Runs inspect queries on scrape, reusing results within the cache ttl.
+type Collector struct {
+	connect func(context.Context) (*pgx.Conn, error)
+	ttl     time.Duration
+	timeout time.Duration
+
+	mu       sync.Mutex
+	conn     *pgx.Conn
+	rows     map[string][]map[string]any
+	err      error
+	duration time.Duration
+	last     time.Time
+}
+
+func NewCollector(connect func(context.Context) (*pgx.Conn, error), ttl, timeout time.Duration) *Collector {
+	return &Collector{connect: connect, ttl: ttl, timeout: timeout}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- upDesc
+	ch <- durationDesc
+	for _, g := range gauges {
+		ch <- g.desc
+	}
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.last.IsZero() || time.Since(c.last) >= c.ttl {
+		start := time.Now()
+		c.rows, c.err = c.scrape()
+		c.duration = time.Since(start)
+		c.last = time.Now()
+	}
+	ch <- prometheus.MustNewConstMetric(durationDesc, prometheus.GaugeValue, c.duration.Seconds())
+	if c.err != nil {
+		fmt.Fprintln(os.Stderr, c.err)
+		ch <- prometheus.MustNewConstMetric(upDesc, prometheus.GaugeValue, 0)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(upDesc, prometheus.GaugeValue, 1)
+	for _, g := range gauges {
+		g.collect(c.rows[g.query], ch)
+	}
+}
+
+func (c *Collector) scrape() (map[string][]map[string]any, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	if c.conn == nil || c.conn.IsClosed() {
+		conn, err := c.connect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.conn = conn
+	}
+	var names []string
+	for _, g := range gauges {
+		if !utils.SliceContains(names, g.query) {
+			names = append(names, g.query)
+		}
+	}
+	rows, err := inspect.QueryRows(ctx, names, c.conn)
+	if err != nil {
+		// Reconnects on the next scrape in case the connection is broken
+		c.Close()
+	}
+	return rows, err
+}
+
+func (c *Collector) Close() {
+	if c.conn != nil {
+		c.conn.Close(context.Background())
+		c.conn = nil
+	}
+}
+
+func (g gauge) collect(rows []map[string]any, ch chan<- prometheus.Metric) {
+	if g.count {
+		counts := map[string]float64{}
+		values := map[string][]string{}
+		for _, row := range rows {
+			labels := labelValues(row, g.labels)
+			key := fmt.Sprint(labels)
+			counts[key]++
+			values[key] = labels
+		}
+		for key, n := range counts {
+			ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, n, values[key]...)
+		}
+		return
+	}
+	for _, row := range rows {
+		var value float64
+		switch v := row[g.column].(type) {
+		case float64:
+			value = v
+		case bool:
+			if v {
+				value = 1
+			}
+		default:
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, value, labelValues(row, g.labels)...)
+	}
+}
+
+func labelValues(row map[string]any, labels []string) []string {
+	result := make([]string, len(labels))
+	for i, l := range labels {
+		if v, ok := row[l]; ok && v != nil {
+			result[i] = fmt.Sprint(v)
+		}
+	}
+	return result
+}
+
+func Run(ctx context.Context, listen string, ttl, timeout time.Duration, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
+	collector := NewCollector(func(ctx context.Context) (*pgx.Conn, error) {
+		return utils.ConnectByConfig(ctx, config, options...)
+	}, ttl, timeout)
+	defer collector.Close()
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(collector); err != nil {
+		return errors.Errorf("failed to register collector: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: timeout}
+	lis, err := net.Listen("tcp", listen)
+	if err != nil {
+		return errors.Errorf("failed to listen on %s: %w", listen, err)
+	}
+	go func() {
+		<-ctx.Done()
+		if err := server.Shutdown(context.Background()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}()
+	fmt.Fprintln(os.Stderr, "Serving metrics on "+utils.Aqua(fmt.Sprintf("http://%s/metrics", lis.Addr())))
+	if err := server.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return errors.Errorf("failed to serve metrics: %w", err)
+	}
+	return nil
+}
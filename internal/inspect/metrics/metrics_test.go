@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	t.Run("exports cached rows as gauges", func(t *testing.T) {
+		collector := NewCollector(func(ctx context.Context) (*pgx.Conn, error) {
+			return nil, errors.New("should not connect within cache ttl")
+		}, time.Minute, time.Second)
+		collector.last = time.Now()
+		collector.rows = map[string][]map[string]any{
+			"cache": {
+				{"name": "table hit rate", "ratio": 0.95},
+			},
+			"locks": {
+				{"pid": 1.0, "relname": "users", "granted": true},
+				{"pid": 2.0, "relname": "users", "granted": false},
+				{"pid": 3.0, "relname": "posts", "granted": true},
+			},
+			"replication_slots": {
+				{"slot_name": "supabase_realtime", "active": true, "replication_lag": 1024.0},
+			},
+		}
+		// Run test
+		err := testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP supabase_inspect_cache_hit_ratio Ratio of blocks read from the buffer cache.
+# TYPE supabase_inspect_cache_hit_ratio gauge
+supabase_inspect_cache_hit_ratio{name="table hit rate"} 0.95
+# HELP supabase_inspect_exclusive_locks Number of exclusive locks held or awaited.
+# TYPE supabase_inspect_exclusive_locks gauge
+supabase_inspect_exclusive_locks{granted="false"} 1
+supabase_inspect_exclusive_locks{granted="true"} 2
+# HELP supabase_inspect_replication_slot_active Whether each replication slot is active.
+# TYPE supabase_inspect_replication_slot_active gauge
+supabase_inspect_replication_slot_active{slot_name="supabase_realtime"} 1
+# HELP supabase_inspect_replication_slot_lag_bytes Replication lag of each slot.
+# TYPE supabase_inspect_replication_slot_lag_bytes gauge
+supabase_inspect_replication_slot_lag_bytes{slot_name="supabase_realtime"} 1024
+# HELP supabase_inspect_up Whether the last scrape of the database succeeded.
+# TYPE supabase_inspect_up gauge
+supabase_inspect_up 1
+`), "supabase_inspect_up", "supabase_inspect_cache_hit_ratio", "supabase_inspect_exclusive_locks",
+			"supabase_inspect_replication_slot_active", "supabase_inspect_replication_slot_lag_bytes")
+		// Check error
+		assert.NoError(t, err)
+	})
+
+	t.Run("reports down on connection error", func(t *testing.T) {
+		var attempts int
+		collector := NewCollector(func(ctx context.Context) (*pgx.Conn, error) {
+			attempts++
+			return nil, errors.New("connection refused")
+		}, 0, time.Second)
+		// Run test
+		err := testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP supabase_inspect_up Whether the last scrape of the database succeeded.
+# TYPE supabase_inspect_up gauge
+supabase_inspect_up 0
+`), "supabase_inspect_up")
+		// Check error
+		assert.NoError(t, err)
+		// Retries on every scrape without a cache ttl
+		assert.Equal(t, 1, attempts)
+		assert.Equal(t, 1, testutil.CollectAndCount(collector, "supabase_inspect_up"))
+		assert.Equal(t, 2, attempts)
+	})
+}
@@ -27,10 +27,10 @@ func TestOutliersCommand(t *testing.T) {
 		defer conn.Close(t)
 		conn.Query(OutliersQuery).
 			Reply("SELECT 1", Result{
-				Total_exec_time: "0.9",
-				Prop_exec_time:  "0.9",
-				Ncalls:          "0.9",
-				Sync_io_time:    "0.9",
+				Total_exec_time: 0.9,
+				Prop_exec_time:  0.9,
+				Ncalls:          9,
+				Sync_io_time:    0.9,
 				Query:           "SELECT 1",
 			})
 		// Run test
@@ -4,7 +4,9 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"os"
 
+	"github.com/docker/go-units"
 	"github.com/go-errors/errors"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
@@ -18,11 +20,12 @@ import (
 var ReplicationSlotsQuery string
 
 type Result struct {
-	Slot_name                  string
-	Active                     bool
-	State                      string
-	Replication_client_address string
-	Replication_lag_gb         string
+	Slot_name                  string `json:"slot_name"`
+	Active                     bool   `json:"active"`
+	State                      string `json:"state"`
+	Replication_client_address string `json:"replication_client_address"`
+	// Lag in bytes
+	Replication_lag int64 `json:"replication_lag"`
 }
 
 func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
@@ -39,10 +42,13 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 	if err != nil {
 		return err
 	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result)
+	}
 	// TODO: implement a markdown table marshaller
-	table := "|Name|Active|State|Replication Client Address|Replication Lag GB|\n|-|-|-|-|-|\n"
+	table := "|Name|Active|State|Replication Client Address|Replication Lag|\n|-|-|-|-|-|\n"
 	for _, r := range result {
-		table += fmt.Sprintf("|`%s`|`%t`|`%s`|`%s`|`%s`|\n", r.Slot_name, r.Active, r.State, r.Replication_client_address, r.Replication_lag_gb)
+		table += fmt.Sprintf("|`%s`|`%t`|`%s`|`%s`|`%s`|\n", r.Slot_name, r.Active, r.State, r.Replication_client_address, units.BytesSize(float64(r.Replication_lag)))
 	}
 	return list.RenderTable(table)
 }
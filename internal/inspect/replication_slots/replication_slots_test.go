@@ -31,7 +31,7 @@ func TestReplicationCommand(t *testing.T) {
 				Active:                     true,
 				State:                      "active",
 				Replication_client_address: "127.0.0.1",
-				Replication_lag_gb:         "0.9",
+				Replication_lag:            1 << 30,
 			})
 		// Run test
 		err := Run(context.Background(), dbConfig, fsys, conn.Intercept)
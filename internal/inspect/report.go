@@ -1,11 +1,14 @@
 package inspect
 
 import (
+	"bytes"
 	"context"
 	"embed"
+	"encoding/json"
 	"fmt"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -18,7 +21,10 @@ import (
 	"github.com/supabase/cli/internal/utils"
 )
 
-//go:embed **/*.sql
+// Only queries at the top of each command directory are run by inspect report. Queries
+// nested further, such as explain/queries, belong to interactive commands and are excluded.
+//
+//go:embed */*.sql
 var queries embed.FS
 
 func Report(ctx context.Context, out string, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
@@ -32,6 +38,7 @@ func Report(ctx context.Context, out string, config pgconn.Config, fsys afero.Fs
 	}
 	defer conn.Close(context.Background())
 	fmt.Fprintln(os.Stderr, "Running queries...")
+	var names, sqls []string
 	if err := fs.WalkDir(queries, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return errors.Errorf("failed to walk queries: %w", err)
@@ -44,11 +51,34 @@ func Report(ctx context.Context, out string, config pgconn.Config, fsys afero.Fs
 			return errors.Errorf("failed to read query: %w", err)
 		}
 		name := strings.Split(d.Name(), ".")[0]
+		names = append(names, name)
+		sqls = append(sqls, string(query))
 		outPath := filepath.Join(out, fmt.Sprintf("%s_%s.csv", name, date))
 		return copyToCSV(ctx, string(query), outPath, conn.PgConn(), fsys)
 	}); err != nil {
 		return err
 	}
+	custom, err := LoadCustomQueries(fsys)
+	if err != nil {
+		return err
+	}
+	for _, q := range custom {
+		query, err := q.Render(nil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping custom query %s: %v\n", q.Name, err)
+			continue
+		}
+		names = append(names, q.Name)
+		sqls = append(sqls, query)
+		outPath := filepath.Join(out, fmt.Sprintf("%s_%s.csv", q.Name, date))
+		if err := copyToCSV(ctx, query, outPath, conn.PgConn(), fsys); err != nil {
+			return err
+		}
+	}
+	outPath := filepath.Join(out, fmt.Sprintf("report_%s.json", date))
+	if err := saveJSON(ctx, names, sqls, outPath, conn, fsys); err != nil {
+		return err
+	}
 	if !filepath.IsAbs(out) {
 		out, _ = filepath.Abs(out)
 	}
@@ -71,9 +101,71 @@ func copyToCSV(ctx context.Context, query, outPath string, conn *pgconn.PgConn,
 	return nil
 }
 
+// Combines the results of all queries into a single json document keyed by query name.
+func saveJSON(ctx context.Context, names, queries []string, outPath string, conn *pgx.Conn, fsys afero.Fs) error {
+	var report string
+	if err := conn.QueryRow(ctx, wrapJSON(names, queries)).Scan(&report); err != nil {
+		return errors.Errorf("failed to query report: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(report), "", "  "); err != nil {
+		return errors.Errorf("failed to format report: %w", err)
+	}
+	if err := afero.WriteFile(fsys, outPath, buf.Bytes(), 0644); err != nil {
+		return errors.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// Runs the named queries in a single round trip and returns their rows keyed by query name.
+func QueryRows(ctx context.Context, names []string, conn *pgx.Conn) (map[string][]map[string]any, error) {
+	sqls := make([]string, len(names))
+	for i, name := range names {
+		query, err := queries.ReadFile(path.Join(name, name+".sql"))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, errors.Errorf("unknown inspect query: %s", name)
+		} else if err != nil {
+			return nil, errors.Errorf("failed to read query: %w", err)
+		}
+		sqls[i] = string(query)
+	}
+	var report string
+	if err := conn.QueryRow(ctx, wrapJSON(names, sqls)).Scan(&report); err != nil {
+		return nil, errors.Errorf("failed to query rows: %w", err)
+	}
+	var result map[string][]map[string]any
+	if err := json.Unmarshal([]byte(report), &result); err != nil {
+		return nil, errors.Errorf("failed to parse rows: %w", err)
+	}
+	return result, nil
+}
+
 var ignoreSchemas = fmt.Sprintf("'{%s}'::text[]", strings.Join(reset.LikeEscapeSchema(utils.InternalSchemas), ","))
 
+func expandQuery(query string) string {
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	return strings.ReplaceAll(query, "$1", ignoreSchemas)
+}
+
 func wrapQuery(query string) string {
-	fullQuery := strings.ReplaceAll(query, "$1", ignoreSchemas)
-	return fmt.Sprintf("COPY (%s) TO STDOUT WITH CSV HEADER", fullQuery)
+	return fmt.Sprintf("COPY (%s) TO STDOUT WITH CSV HEADER", expandQuery(query))
+}
+
+// PostgreSQL functions accept at most 100 arguments, ie. 50 key value pairs
+const maxObjectPairs = 50
+
+func wrapJSON(names, queries []string) string {
+	var objects []string
+	for start := 0; start < len(names); start += maxObjectPairs {
+		end := min(start+maxObjectPairs, len(names))
+		args := make([]string, end-start)
+		for i, name := range names[start:end] {
+			args[i] = fmt.Sprintf("'%s', (SELECT coalesce(json_agg(t), '[]') FROM (%s) t)", name, expandQuery(queries[start+i]))
+		}
+		objects = append(objects, fmt.Sprintf("jsonb_build_object(%s)", strings.Join(args, ", ")))
+	}
+	if len(objects) == 0 {
+		return "SELECT '{}'"
+	}
+	return fmt.Sprintf("SELECT (%s)::text", strings.Join(objects, " || "))
 }
@@ -3,11 +3,14 @@ package inspect
 import (
 	"context"
 	"fmt"
+	"io/fs"
+	"strings"
 	"testing"
 
 	"github.com/jackc/pgconn"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/supabase/cli/internal/inspect/bloat"
 	"github.com/supabase/cli/internal/inspect/blocking"
 	"github.com/supabase/cli/internal/inspect/cache"
@@ -85,7 +88,9 @@ func TestReportCommand(t *testing.T) {
 			Query(wrapQuery(unused_indexes.UnusedIndexesQuery)).
 			Reply("COPY 0").
 			Query(wrapQuery(vacuum_stats.VacuumStatsQuery)).
-			Reply("COPY 0")
+			Reply("COPY 0").
+			Query(wrapJSON(listQueries(t))).
+			Reply("SELECT 1", []interface{}{`{"bloat":[]}`})
 		// Run test
 		err := Report(context.Background(), ".", dbConfig, fsys, conn.Intercept)
 		// Check error
@@ -93,7 +98,27 @@ func TestReportCommand(t *testing.T) {
 		matches, err := afero.Glob(fsys, "*.csv")
 		assert.NoError(t, err)
 		assert.Len(t, matches, 20)
+		matches, err = afero.Glob(fsys, "report_*.json")
+		assert.NoError(t, err)
+		require.Len(t, matches, 1)
+		contents, err := afero.ReadFile(fsys, matches[0])
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"bloat":[]}`, string(contents))
+	})
+}
+
+func listQueries(t *testing.T) (names, sqls []string) {
+	err := fs.WalkDir(queries, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		query, err := queries.ReadFile(path)
+		names = append(names, strings.Split(d.Name(), ".")[0])
+		sqls = append(sqls, string(query))
+		return err
 	})
+	require.NoError(t, err)
+	return names, sqls
 }
 
 func TestWrapQuery(t *testing.T) {
@@ -104,6 +129,13 @@ func TestWrapQuery(t *testing.T) {
 		)
 	})
 
+	t.Run("strips trailing semicolon", func(t *testing.T) {
+		assert.Equal(t,
+			"COPY (SELECT 1) TO STDOUT WITH CSV HEADER",
+			wrapQuery("SELECT 1;\n"),
+		)
+	})
+
 	t.Run("replaces placeholder value", func(t *testing.T) {
 		assert.Equal(t,
 			fmt.Sprintf("COPY (SELECT 'a' LIKE ANY(%s)) TO STDOUT WITH CSV HEADER", ignoreSchemas),
@@ -111,3 +143,28 @@ func TestWrapQuery(t *testing.T) {
 		)
 	})
 }
+
+func TestWrapJSON(t *testing.T) {
+	t.Run("combines queries into object", func(t *testing.T) {
+		assert.Equal(t,
+			`SELECT (jsonb_build_object('a', (SELECT coalesce(json_agg(t), '[]') FROM (SELECT 1) t), 'b', (SELECT coalesce(json_agg(t), '[]') FROM (SELECT 2) t)))::text`,
+			wrapJSON([]string{"a", "b"}, []string{"SELECT 1", "SELECT 2;"}),
+		)
+	})
+
+	t.Run("merges objects over argument limit", func(t *testing.T) {
+		names := make([]string, maxObjectPairs+1)
+		sqls := make([]string, len(names))
+		for i := range names {
+			names[i] = fmt.Sprintf("q%d", i)
+			sqls[i] = fmt.Sprintf("SELECT %d", i)
+		}
+		// Run test
+		sql := wrapJSON(names, sqls)
+		// Check output
+		objects := strings.Split(sql, " || ")
+		require.Len(t, objects, 2)
+		assert.Equal(t, maxObjectPairs, strings.Count(objects[0], "json_agg"))
+		assert.Equal(t, `jsonb_build_object('q50', (SELECT coalesce(json_agg(t), '[]') FROM (SELECT 50) t)))::text`, objects[1])
+	})
+}
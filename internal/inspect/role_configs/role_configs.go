@@ -4,6 +4,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"os"
 
 	"github.com/go-errors/errors"
 	"github.com/jackc/pgconn"
@@ -18,8 +19,8 @@ import (
 var RoleConfigsQuery string
 
 type Result struct {
-	Role_name     string
-	Custom_config string
+	Role_name     string `json:"role_name"`
+	Custom_config string `json:"custom_config"`
 }
 
 func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
@@ -36,6 +37,9 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 	if err != nil {
 		return err
 	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result)
+	}
 
 	table := "|Role name|Custom config|\n|-|-|\n"
 	for _, r := range result {
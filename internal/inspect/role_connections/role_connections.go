@@ -4,6 +4,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"os"
 
 	"github.com/go-errors/errors"
 	"github.com/jackc/pgconn"
@@ -18,9 +19,9 @@ import (
 var RoleConnectionsQuery string
 
 type Result struct {
-	Rolname            string
-	Active_connections int
-	Connection_limit   int
+	Rolname            string `json:"rolname"`
+	Active_connections int    `json:"active_connections"`
+	Connection_limit   int    `json:"connection_limit"`
 }
 
 func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
@@ -37,6 +38,9 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 	if err != nil {
 		return err
 	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result)
+	}
 
 	table := "|Role Name|Active connction|\n|-|-|\n"
 	sum := 0
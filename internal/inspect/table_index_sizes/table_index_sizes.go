@@ -4,7 +4,9 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"os"
 
+	"github.com/docker/go-units"
 	"github.com/go-errors/errors"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
@@ -19,8 +21,9 @@ import (
 var TableIndexSizesQuery string
 
 type Result struct {
-	Table      string
-	Index_size string
+	Table string `json:"table"`
+	// Size in bytes
+	Index_size int64 `json:"index_size"`
 }
 
 func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
@@ -37,10 +40,13 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 	if err != nil {
 		return err
 	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result)
+	}
 
 	table := "|Table|Index size|\n|-|-|\n"
 	for _, r := range result {
-		table += fmt.Sprintf("|`%s`|`%s`|\n", r.Table, r.Index_size)
+		table += fmt.Sprintf("|`%s`|`%s`|\n", r.Table, units.BytesSize(float64(r.Index_size)))
 	}
 	return list.RenderTable(table)
 }
@@ -30,7 +30,7 @@ func TestTableIndexSizesCommand(t *testing.T) {
 		conn.Query(TableIndexSizesQuery, reset.LikeEscapeSchema(utils.InternalSchemas)).
 			Reply("SELECT 1", Result{
 				Table:      "public.test_table",
-				Index_size: "3GB",
+				Index_size: 3 << 30,
 			})
 		// Run test
 		err := Run(context.Background(), dbConfig, fsys, conn.Intercept)
@@ -4,6 +4,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"os"
 
 	"github.com/go-errors/errors"
 	"github.com/jackc/pgconn"
@@ -19,9 +20,9 @@ import (
 var TableRecordCountsQuery string
 
 type Result struct {
-	Schema          string
-	Name            string
-	Estimated_count int64
+	Schema          string `json:"schema"`
+	Name            string `json:"name"`
+	Estimated_count int64  `json:"estimated_count"`
 }
 
 func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
@@ -38,6 +39,9 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 	if err != nil {
 		return err
 	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result)
+	}
 
 	table := "Schema|Table|Estimated count|\n|-|-|-|\n"
 	for _, r := range result {
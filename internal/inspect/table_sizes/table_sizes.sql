@@ -1,7 +1,7 @@
 SELECT
   n.nspname AS schema,
   c.relname AS name,
-  pg_size_pretty(pg_table_size(c.oid)) AS size
+  pg_table_size(c.oid) AS size
 FROM pg_class c
 LEFT JOIN pg_namespace n ON (n.oid = c.relnamespace)
 WHERE NOT n.nspname LIKE ANY($1)
@@ -0,0 +1,321 @@
+package top
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+	"github.com/supabase/cli/internal/utils"
+)
+
+const (
+	sortDuration = iota
+	sortPid
+	sortState
+	sortWait
+)
+
+var sortNames = []string{"duration", "pid", "state", "wait"}
+
+type (
+	snapshotMsg struct {
+		snapshot Snapshot
+		err      error
+	}
+	tickMsg   time.Time
+	signalMsg struct {
+		status string
+		err    error
+	}
+)
+
+var (
+	headerStyle   = lipgloss.NewStyle().Bold(true)
+	selectedStyle = lipgloss.NewStyle().Reverse(true)
+	dimStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+// A pending cancel or terminate action awaiting confirmation.
+type pendingSignal struct {
+	pid       int32
+	terminate bool
+}
+
+type topModel struct {
+	ctx      context.Context
+	sampler  *Sampler
+	interval time.Duration
+	snapshot Snapshot
+	err      error
+	status   string
+	sortBy   int
+	filter   string
+	// True while the filter is being edited
+	filtering bool
+	cursor    int
+	confirm   *pendingSignal
+	width     int
+	height    int
+}
+
+func newTopModel(ctx context.Context, sampler *Sampler, interval time.Duration) topModel {
+	return topModel{
+		ctx:      ctx,
+		sampler:  sampler,
+		interval: interval,
+	}
+}
+
+func (m topModel) Init() tea.Cmd {
+	return m.refresh
+}
+
+func (m topModel) refresh() tea.Msg {
+	snapshot, err := m.sampler.Sample(m.ctx)
+	return snapshotMsg{snapshot: snapshot, err: err}
+}
+
+func (m topModel) tick() tea.Cmd {
+	return tea.Tick(m.interval, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+func (m topModel) signal(p pendingSignal) tea.Cmd {
+	return func() tea.Msg {
+		verb := "Cancelled query of"
+		if p.terminate {
+			verb = "Terminated"
+		}
+		if err := m.sampler.Signal(m.ctx, p.pid, p.terminate); err != nil {
+			return signalMsg{err: err}
+		}
+		return signalMsg{status: fmt.Sprintf("%s backend %d", verb, p.pid)}
+	}
+}
+
+func (m topModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case snapshotMsg:
+		m.err = msg.err
+		if msg.err == nil {
+			m.snapshot = msg.snapshot
+			m.clampCursor()
+		}
+		// Schedules the next refresh only after the current one completes
+		return m, m.tick()
+	case tickMsg:
+		return m, m.refresh
+	case signalMsg:
+		m.status = msg.status
+		if msg.err != nil {
+			m.status = errorStyle.Render(msg.err.Error())
+		}
+	}
+	return m, nil
+}
+
+func (m topModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyCtrlC {
+		return m, tea.Quit
+	}
+	if m.confirm != nil {
+		p := *m.confirm
+		m.confirm = nil
+		if key := msg.String(); key == "y" || key == "Y" {
+			return m, m.signal(p)
+		}
+		m.status = "Aborted."
+		return m, nil
+	}
+	if m.filtering {
+		switch msg.Type {
+		case tea.KeyEnter, tea.KeyEsc:
+			m.filtering = false
+		case tea.KeyBackspace:
+			if len(m.filter) > 0 {
+				runes := []rune(m.filter)
+				m.filter = string(runes[:len(runes)-1])
+			}
+		case tea.KeyRunes, tea.KeySpace:
+			m.filter += string(msg.Runes)
+		}
+		m.clampCursor()
+		return m, nil
+	}
+	switch msg.String() {
+	case "q", "esc":
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		m.cursor++
+		m.clampCursor()
+	case "s":
+		m.sortBy = (m.sortBy + 1) % len(sortNames)
+	case "/":
+		m.filtering = true
+	case "c", "x":
+		sessions := m.visible()
+		if len(sessions) == 0 {
+			return m, nil
+		}
+		m.confirm = &pendingSignal{pid: sessions[m.cursor].Pid, terminate: msg.String() == "x"}
+	}
+	return m, nil
+}
+
+func (m *topModel) clampCursor() {
+	if n := len(m.visible()); m.cursor >= n {
+		m.cursor = max(n-1, 0)
+	}
+}
+
+// Returns sessions matching the filter in the selected sort order.
+func (m topModel) visible() []Session {
+	filter := strings.ToLower(m.filter)
+	var result []Session
+	for _, s := range m.snapshot.Sessions {
+		text := strings.ToLower(strings.Join([]string{s.Usename, s.Application_name, s.State, s.Wait_event, s.Query}, " "))
+		if strings.Contains(text, filter) {
+			result = append(result, s)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		switch m.sortBy {
+		case sortPid:
+			return a.Pid < b.Pid
+		case sortState:
+			return a.State < b.State
+		case sortWait:
+			return a.Wait_event_type+a.Wait_event > b.Wait_event_type+b.Wait_event
+		default:
+			return a.Duration > b.Duration
+		}
+	})
+	return result
+}
+
+func (m topModel) View() string {
+	return m.render()
+}
+
+func (m topModel) render() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "Sessions: %d  TPS: %.1f  Cache hit: %.2f%%  Sort: %s",
+		len(m.snapshot.Sessions), m.snapshot.Tps, m.snapshot.CacheHit*100, sortNames[m.sortBy])
+	if len(m.filter) > 0 || m.filtering {
+		fmt.Fprintf(&out, "  Filter: %s", m.filter)
+		if m.filtering {
+			out.WriteString("_")
+		}
+	}
+	out.WriteString("\n\n")
+	out.WriteString(headerStyle.Render(fmt.Sprintf("%-8s %-16s %-20s %-24s %10s  %s", "PID", "USER", "STATE", "WAIT", "DURATION", "QUERY")) + "\n")
+	for i, s := range m.visible() {
+		wait := s.Wait_event_type
+		if len(s.Wait_event) > 0 {
+			wait += ":" + s.Wait_event
+		}
+		line := fmt.Sprintf("%-8d %-16s %-20s %-24s %10s  %s", s.Pid, clip(s.Usename, 16), clip(s.State, 20), clip(wait, 24), utils.FormatDuration(s.Duration), oneLine(s.Query))
+		if m.width > 0 {
+			line = truncate.String(line, uint(m.width))
+		}
+		if i == m.cursor {
+			line = selectedStyle.Render(line)
+		}
+		out.WriteString(line + "\n")
+	}
+	if tree := blockingTree(m.snapshot.Sessions); len(tree) > 0 {
+		out.WriteString("\n" + headerStyle.Render("Blocking tree") + "\n")
+		for _, line := range tree {
+			if m.width > 0 {
+				line = truncate.String(line, uint(m.width))
+			}
+			out.WriteString(line + "\n")
+		}
+	}
+	out.WriteString("\n")
+	if m.err != nil {
+		out.WriteString(errorStyle.Render(m.err.Error()) + "\n")
+	}
+	if m.confirm != nil {
+		verb := "Cancel the query of"
+		if m.confirm.terminate {
+			verb = "Terminate"
+		}
+		out.WriteString(utils.Yellow(fmt.Sprintf("%s backend %d? [y/N]", verb, m.confirm.pid)))
+	} else if len(m.status) > 0 {
+		out.WriteString(m.status)
+	} else {
+		out.WriteString(dimStyle.Render("↑/↓ select • s sort • / filter • c cancel query • x terminate backend • q quit"))
+	}
+	return out.String()
+}
+
+// Renders blocking backends as roots with the sessions they block indented below.
+func blockingTree(sessions []Session) []string {
+	byPid := make(map[int32]Session, len(sessions))
+	children := map[int32][]int32{}
+	for _, s := range sessions {
+		byPid[s.Pid] = s
+		for _, blocker := range s.Blocked_by {
+			children[blocker] = append(children[blocker], s.Pid)
+		}
+	}
+	var roots []int32
+	for pid := range children {
+		if s, ok := byPid[pid]; !ok || len(s.Blocked_by) == 0 {
+			roots = append(roots, pid)
+		}
+	}
+	sort.Slice(roots, func(i, j int) bool { return roots[i] < roots[j] })
+	var lines []string
+	visited := map[int32]bool{}
+	var walk func(pid int32, depth int)
+	walk = func(pid int32, depth int) {
+		if visited[pid] {
+			return
+		}
+		visited[pid] = true
+		prefix := ""
+		if depth > 0 {
+			prefix = strings.Repeat("   ", depth-1) + "└─ "
+		}
+		line := fmt.Sprintf("%s%d", prefix, pid)
+		if s, ok := byPid[pid]; ok {
+			line += fmt.Sprintf(" (%s) %s", utils.FormatDuration(s.Duration), oneLine(s.Query))
+		}
+		lines = append(lines, line)
+		kids := children[pid]
+		sort.Slice(kids, func(i, j int) bool { return kids[i] < kids[j] })
+		for _, child := range kids {
+			walk(child, depth+1)
+		}
+	}
+	for _, pid := range roots {
+		walk(pid, 0)
+	}
+	return lines
+}
+
+func oneLine(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+func clip(s string, n int) string {
+	return truncate.StringWithTail(s, uint(n), "…")
+}
@@ -0,0 +1,12 @@
+SELECT
+  pid,
+  coalesce(usename, '') AS usename,
+  application_name,
+  coalesce(state, '') AS state,
+  coalesce(wait_event_type, '') AS wait_event_type,
+  coalesce(wait_event, '') AS wait_event,
+  coalesce(extract(epoch FROM now() - coalesce(query_start, backend_start)), 0)::float8 AS duration,
+  query,
+  pg_blocking_pids(pid) AS blocked_by
+FROM pg_stat_activity
+WHERE backend_type = 'client backend' AND pid <> pg_backend_pid()
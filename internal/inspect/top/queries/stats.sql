@@ -0,0 +1,5 @@
+SELECT
+  xact_commit + xact_rollback AS xacts,
+  coalesce(blks_hit::float8 / nullif(blks_hit + blks_read, 0), 0) AS cache_hit
+FROM pg_stat_database
+WHERE datname = current_database()
@@ -0,0 +1,135 @@
+package top
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-errors/errors"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/utils"
+	"github.com/supabase/cli/pkg/pgxv5"
+	"golang.org/x/term"
+)
+
+var (
+	//go:embed queries/sessions.sql
+	SessionsQuery string
+	//go:embed queries/stats.sql
+	StatsQuery string
+)
+
+const (
+	CANCEL_BACKEND    = "SELECT pg_cancel_backend($1)"
+	TERMINATE_BACKEND = "SELECT pg_terminate_backend($1)"
+)
+
+type Session struct {
+	Pid              int32  `json:"pid"`
+	Usename          string `json:"usename"`
+	Application_name string `json:"application_name"`
+	State            string `json:"state"`
+	Wait_event_type  string `json:"wait_event_type"`
+	Wait_event       string `json:"wait_event"`
+	// Seconds since the current query started
+	Duration   float64 `json:"duration"`
+	Query      string  `json:"query"`
+	Blocked_by []int32 `json:"blocked_by"`
+}
+
+type Snapshot struct {
+	Time     time.Time `json:"time"`
+	Sessions []Session `json:"sessions"`
+	// Zero on the first sample since it is computed from the previous one
+	Tps      float64 `json:"tps"`
+	CacheHit float64 `json:"cache_hit"`
+}
+
+// Serialises access to a single connection shared by refreshes and key bindings.
+type Sampler struct {
+	mu    sync.Mutex
+	conn  *pgx.Conn
+	xacts int64
+	last  time.Time
+}
+
+func NewSampler(conn *pgx.Conn) *Sampler {
+	return &Sampler{conn: conn}
+}
+
+func (s *Sampler) Sample(ctx context.Context) (Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := Snapshot{Time: time.Now()}
+	rows, err := s.conn.Query(ctx, SessionsQuery)
+	if err != nil {
+		return result, errors.Errorf("failed to query sessions: %w", err)
+	}
+	if result.Sessions, err = pgxv5.CollectRows[Session](rows); err != nil {
+		return result, err
+	}
+	var xacts int64
+	if err := s.conn.QueryRow(ctx, StatsQuery).Scan(&xacts, &result.CacheHit); err != nil {
+		return result, errors.Errorf("failed to query stats: %w", err)
+	}
+	if elapsed := result.Time.Sub(s.last).Seconds(); !s.last.IsZero() && elapsed > 0 {
+		result.Tps = float64(xacts-s.xacts) / elapsed
+	}
+	s.xacts, s.last = xacts, result.Time
+	return result, nil
+}
+
+// Cancels the current query of a backend, or terminates the backend entirely.
+func (s *Sampler) Signal(ctx context.Context, pid int32, terminate bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sql, verb := CANCEL_BACKEND, "cancel"
+	if terminate {
+		sql, verb = TERMINATE_BACKEND, "terminate"
+	}
+	var ok bool
+	if err := s.conn.QueryRow(ctx, sql, pid).Scan(&ok); err != nil {
+		return errors.Errorf("failed to %s backend: %w", verb, err)
+	} else if !ok {
+		return errors.Errorf("failed to %s backend: pid %d not found", verb, pid)
+	}
+	return nil
+}
+
+func Run(ctx context.Context, interval time.Duration, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
+	conn, err := utils.ConnectByConfig(ctx, config, options...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+	sampler := NewSampler(conn)
+	// Prints a single snapshot when output is piped or machine-readable
+	if !term.IsTerminal(int(os.Stdin.Fd())) || utils.OutputFormat.Value != utils.OutputPretty {
+		snapshot, err := sampler.Sample(ctx)
+		if err != nil {
+			return err
+		}
+		switch utils.OutputFormat.Value {
+		case utils.OutputPretty:
+		case utils.OutputCsv:
+			return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, snapshot.Sessions)
+		default:
+			return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, snapshot)
+		}
+		m := newTopModel(ctx, sampler, interval)
+		m.snapshot = snapshot
+		fmt.Println(m.render())
+		return nil
+	}
+	p := utils.NewProgram(newTopModel(ctx, sampler, interval), tea.WithAltScreen(), tea.WithContext(ctx))
+	if err := p.Start(); err != nil && !errors.Is(err, tea.ErrProgramKilled) {
+		return errors.Errorf("failed to run dashboard: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package top
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/pkg/pgtest"
+)
+
+var sessions = []Session{
+	{Pid: 10, Usename: "postgres", State: "active", Duration: 30, Query: "UPDATE users SET name = $1"},
+	{Pid: 20, Usename: "authenticator", State: "active", Wait_event_type: "Lock", Wait_event: "transactionid", Duration: 12, Query: "UPDATE users\n  SET email = $1", Blocked_by: []int32{10}},
+	{Pid: 30, Usename: "authenticator", State: "idle", Duration: 90, Query: "SELECT 1", Blocked_by: []int32{20}},
+}
+
+func TestSampler(t *testing.T) {
+	t.Run("computes tps from previous sample", func(t *testing.T) {
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query(SessionsQuery).
+			Reply("SELECT 1", sessions[1]).
+			Query(StatsQuery).
+			Reply("SELECT 1", []interface{}{int64(100), 0.99}).
+			Query(SessionsQuery).
+			Reply("SELECT 0").
+			Query(StatsQuery).
+			Reply("SELECT 1", []interface{}{int64(300), 0.98})
+		sampler := NewSampler(conn.MockClient(t))
+		// Run test
+		first, err := sampler.Sample(context.Background())
+		require.NoError(t, err)
+		sampler.last = first.Time.Add(-time.Second)
+		second, err := sampler.Sample(context.Background())
+		require.NoError(t, err)
+		// Check result
+		assert.Equal(t, []Session{sessions[1]}, first.Sessions)
+		assert.Zero(t, first.Tps)
+		assert.Equal(t, 0.99, first.CacheHit)
+		assert.Empty(t, second.Sessions)
+		assert.Greater(t, second.Tps, float64(0))
+		assert.Equal(t, 0.98, second.CacheHit)
+	})
+
+	t.Run("throws error on missing backend", func(t *testing.T) {
+		// Setup mock postgres
+		conn := pgtest.NewConn()
+		defer conn.Close(t)
+		conn.Query(TERMINATE_BACKEND, int32(42)).
+			Reply("SELECT 1", []interface{}{false})
+		// Run test
+		err := NewSampler(conn.MockClient(t)).Signal(context.Background(), 42, true)
+		// Check error
+		assert.ErrorContains(t, err, "failed to terminate backend: pid 42 not found")
+	})
+}
+
+func TestBlockingTree(t *testing.T) {
+	assert.Equal(t, []string{
+		"10 (30s) UPDATE users SET name = $1",
+		"└─ 20 (12s) UPDATE users SET email = $1",
+		"   └─ 30 (1m30s) SELECT 1",
+	}, blockingTree(sessions))
+	assert.Empty(t, blockingTree(sessions[:1]))
+}
+
+func TestTopModel(t *testing.T) {
+	newModel := func() topModel {
+		m := newTopModel(context.Background(), nil, time.Second)
+		m.snapshot = Snapshot{Sessions: sessions}
+		return m
+	}
+
+	press := func(m topModel, keys ...string) topModel {
+		for _, k := range keys {
+			var msg tea.KeyMsg
+			switch k {
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			case "down":
+				msg = tea.KeyMsg{Type: tea.KeyDown}
+			default:
+				msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			}
+			next, _ := m.Update(msg)
+			m = next.(topModel)
+		}
+		return m
+	}
+
+	t.Run("sorts by duration by default", func(t *testing.T) {
+		var pids []int32
+		for _, s := range newModel().visible() {
+			pids = append(pids, s.Pid)
+		}
+		assert.Equal(t, []int32{30, 10, 20}, pids)
+	})
+
+	t.Run("cycles sort order", func(t *testing.T) {
+		m := press(newModel(), "s")
+		var pids []int32
+		for _, s := range m.visible() {
+			pids = append(pids, s.Pid)
+		}
+		assert.Equal(t, []int32{10, 20, 30}, pids)
+	})
+
+	t.Run("filters sessions", func(t *testing.T) {
+		m := press(newModel(), "/", "e", "m", "a", "i", "l", "enter")
+		assert.False(t, m.filtering)
+		assert.Equal(t, "email", m.filter)
+		visible := m.visible()
+		require.Len(t, visible, 1)
+		assert.Equal(t, int32(20), visible[0].Pid)
+	})
+
+	t.Run("confirms before terminating", func(t *testing.T) {
+		m := press(newModel(), "down", "x")
+		require.NotNil(t, m.confirm)
+		assert.Equal(t, pendingSignal{pid: 10, terminate: true}, *m.confirm)
+		assert.Contains(t, m.View(), "Terminate backend 10? [y/N]")
+		// Any key other than y aborts
+		m = press(m, "n")
+		assert.Nil(t, m.confirm)
+		assert.Equal(t, "Aborted.", m.status)
+	})
+
+	t.Run("schedules refresh after snapshot", func(t *testing.T) {
+		next, cmd := newModel().Update(snapshotMsg{snapshot: Snapshot{Sessions: sessions[:1]}})
+		assert.NotNil(t, cmd)
+		assert.Len(t, next.(topModel).snapshot.Sessions, 1)
+	})
+}
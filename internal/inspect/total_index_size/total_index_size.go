@@ -4,7 +4,9 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"os"
 
+	"github.com/docker/go-units"
 	"github.com/go-errors/errors"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
@@ -19,7 +21,8 @@ import (
 var TotalIndexSizeQuery string
 
 type Result struct {
-	Size string
+	// Size in bytes
+	Size int64 `json:"size"`
 }
 
 func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
@@ -36,10 +39,13 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 	if err != nil {
 		return err
 	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result)
+	}
 
 	table := "|Size|\n|-|\n"
 	for _, r := range result {
-		table += fmt.Sprintf("|`%s`|\n", r.Size)
+		table += fmt.Sprintf("|`%s`|\n", units.BytesSize(float64(r.Size)))
 	}
 	return list.RenderTable(table)
 }
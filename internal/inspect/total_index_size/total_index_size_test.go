@@ -29,7 +29,7 @@ func TestTotalIndexSizeCommand(t *testing.T) {
 		defer conn.Close(t)
 		conn.Query(TotalIndexSizeQuery, reset.LikeEscapeSchema(utils.InternalSchemas)).
 			Reply("SELECT 1", Result{
-				Size: "8GB",
+				Size: 8 << 30,
 			})
 		// Run test
 		err := Run(context.Background(), dbConfig, fsys, conn.Intercept)
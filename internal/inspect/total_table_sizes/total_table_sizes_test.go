@@ -31,7 +31,7 @@ func TestTotalTableSizesCommand(t *testing.T) {
 			Reply("SELECT 1", Result{
 				Schema: "public",
 				Name:   "test_table",
-				Size:   "3GB",
+				Size:   3 << 30,
 			})
 		// Run test
 		err := Run(context.Background(), dbConfig, fsys, conn.Intercept)
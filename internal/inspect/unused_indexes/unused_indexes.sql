@@ -1,7 +1,7 @@
 SELECT
   schemaname || '.' || relname AS table,
   indexrelname AS index,
-  pg_size_pretty(pg_relation_size(i.indexrelid)) AS index_size,
+  pg_relation_size(i.indexrelid) AS index_size,
   idx_scan as index_scans
 FROM pg_stat_user_indexes ui
 JOIN pg_index i ON ui.indexrelid = i.indexrelid
@@ -31,7 +31,7 @@ func TestUnusedIndexesCommand(t *testing.T) {
 			Reply("SELECT 1", Result{
 				Table:       "test_table",
 				Index:       "test_table_idx",
-				Index_size:  "3GB",
+				Index_size:  3 << 30,
 				Index_scans: 2,
 			})
 		// Run test
@@ -4,7 +4,8 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
-	"strings"
+	"os"
+	"strconv"
 
 	"github.com/go-errors/errors"
 	"github.com/jackc/pgconn"
@@ -20,14 +21,15 @@ import (
 var VacuumStatsQuery string
 
 type Result struct {
-	Schema               string
-	Table                string
-	Last_vacuum          string
-	Last_autovacuum      string
-	Rowcount             string
-	Dead_rowcount        string
-	Autovacuum_threshold string
-	Expect_autovacuum    string
+	Schema          string `json:"schema"`
+	Table           string `json:"table"`
+	Last_vacuum     string `json:"last_vacuum"`
+	Last_autovacuum string `json:"last_autovacuum"`
+	// Negative if the table has never been analyzed
+	Rowcount             int64  `json:"rowcount"`
+	Dead_rowcount        int64  `json:"dead_rowcount"`
+	Autovacuum_threshold int64  `json:"autovacuum_threshold"`
+	Expect_autovacuum    string `json:"expect_autovacuum"`
 }
 
 func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...func(*pgx.ConnConfig)) error {
@@ -44,11 +46,17 @@ func Run(ctx context.Context, config pgconn.Config, fsys afero.Fs, options ...fu
 	if err != nil {
 		return err
 	}
+	if utils.OutputFormat.Value != utils.OutputPretty {
+		return utils.EncodeOutput(utils.OutputFormat.Value, os.Stdout, result)
+	}
 
 	table := "|Schema|Table|Last Vacuum|Last Auto Vacuum|Row count|Dead row count|Expect autovacuum?\n|-|-|-|-|-|-|-|\n"
 	for _, r := range result {
-		rowcount := strings.Replace(r.Rowcount, "-1", "No stats", 1)
-		table += fmt.Sprintf("|`%s`|`%s`|%s|%s|`%s`|`%s`|`%s`|\n", r.Schema, r.Table, r.Last_vacuum, r.Last_autovacuum, rowcount, r.Dead_rowcount, r.Expect_autovacuum)
+		rowcount := "No stats"
+		if r.Rowcount >= 0 {
+			rowcount = strconv.FormatInt(r.Rowcount, 10)
+		}
+		table += fmt.Sprintf("|`%s`|`%s`|%s|%s|`%s`|`%d`|`%s`|\n", r.Schema, r.Table, r.Last_vacuum, r.Last_autovacuum, rowcount, r.Dead_rowcount, r.Expect_autovacuum)
 	}
 	return list.RenderTable(table)
 }
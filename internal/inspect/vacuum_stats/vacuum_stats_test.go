@@ -33,9 +33,9 @@ func TestVacuumCommand(t *testing.T) {
 				Table:                "test_table",
 				Last_vacuum:          "2021-01-01 00:00:00",
 				Last_autovacuum:      "2021-01-01 00:00:00",
-				Rowcount:             "1000",
-				Dead_rowcount:        "100",
-				Autovacuum_threshold: "100",
+				Rowcount:             1000,
+				Dead_rowcount:        100,
+				Autovacuum_threshold: 100,
 				Expect_autovacuum:    "yes",
 			})
 		// Run test
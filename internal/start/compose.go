@@ -0,0 +1,240 @@
+package start
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/docker/cli/cli/compose/loader"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/go-errors/errors"
+	"github.com/jackc/pgconn"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/db/start"
+	"github.com/supabase/cli/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+const ExportCompose = "compose"
+
+type composeFile struct {
+	Name     string                    `yaml:"name"`
+	Services map[string]composeService `yaml:"services"`
+	Networks map[string]composeNetwork `yaml:"networks"`
+	Volumes  map[string]composeVolume  `yaml:"volumes,omitempty"`
+}
+
+type composeService struct {
+	ContainerName string                           `yaml:"container_name"`
+	Image         string                           `yaml:"image"`
+	Hostname      string                           `yaml:"hostname,omitempty"`
+	Entrypoint    []string                         `yaml:"entrypoint,omitempty"`
+	Command       []string                         `yaml:"command,omitempty"`
+	WorkingDir    string                           `yaml:"working_dir,omitempty"`
+	Environment   map[string]string                `yaml:"environment,omitempty"`
+	Ports         []string                         `yaml:"ports,omitempty"`
+	Volumes       []string                         `yaml:"volumes,omitempty"`
+	VolumesFrom   []string                         `yaml:"volumes_from,omitempty"`
+	Tmpfs         []string                         `yaml:"tmpfs,omitempty"`
+	Healthcheck   *composeHealthcheck              `yaml:"healthcheck,omitempty"`
+	Restart       string                           `yaml:"restart,omitempty"`
+	DependsOn     map[string]composeDependency     `yaml:"depends_on,omitempty"`
+	Networks      map[string]composeServiceNetwork `yaml:"networks"`
+	ExtraHosts    []string                         `yaml:"extra_hosts,omitempty"`
+	Labels        map[string]string                `yaml:"labels"`
+}
+
+type composeHealthcheck struct {
+	Test        []string `yaml:"test"`
+	Interval    string   `yaml:"interval,omitempty"`
+	Timeout     string   `yaml:"timeout,omitempty"`
+	Retries     int      `yaml:"retries,omitempty"`
+	StartPeriod string   `yaml:"start_period,omitempty"`
+}
+
+type composeDependency struct {
+	Condition string `yaml:"condition"`
+}
+
+type composeServiceNetwork struct {
+	Aliases []string `yaml:"aliases,omitempty"`
+}
+
+type composeNetwork struct {
+	Name string `yaml:"name"`
+}
+
+type composeVolume struct {
+	Name string `yaml:"name"`
+}
+
+// Writes a compose file with the same containers that supabase start would run.
+func Export(ctx context.Context, format string, w io.Writer, fsys afero.Fs, excludedContainers []string) error {
+	if format != ExportCompose {
+		return errors.Errorf("unsupported export format: %s", format)
+	}
+	if err := utils.LoadConfigFS(fsys); err != nil {
+		return err
+	}
+	compose := newComposeFile()
+	config, hostConfig, networkingConfig := start.NewDatabaseConfig()
+	if _, err := compose.add(ctx, config, hostConfig, networkingConfig, utils.DbId); err != nil {
+		return err
+	}
+	dbConfig := pgconn.Config{
+		Host:     utils.DbId,
+		Port:     5432,
+		User:     "postgres",
+		Password: utils.Config.Db.Password,
+		Database: "postgres",
+	}
+	if _, err := startServices(ctx, fsys, excludedContainers, dbConfig, compose.add); err != nil {
+		return err
+	}
+	if err := compose.resolveDependencies(); err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(compose); err != nil {
+		return errors.Errorf("failed to encode compose file: %w", err)
+	}
+	return enc.Close()
+}
+
+func newComposeFile() *composeFile {
+	return &composeFile{
+		Name:     utils.Config.ProjectId,
+		Services: map[string]composeService{},
+		Networks: map[string]composeNetwork{utils.NetId: {Name: utils.NetId}},
+		Volumes:  map[string]composeVolume{},
+	}
+}
+
+// Records a container as a compose service, applying the same defaults as utils.DockerStart.
+func (c *composeFile) add(ctx context.Context, config container.Config, hostConfig container.HostConfig, networkingConfig network.NetworkingConfig, containerName string) (string, error) {
+	service := composeService{
+		ContainerName: containerName,
+		Image:         utils.GetRegistryImageUrl(config.Image),
+		Hostname:      config.Hostname,
+		Entrypoint:    escapeAll(config.Entrypoint),
+		Command:       escapeAll(config.Cmd),
+		WorkingDir:    config.WorkingDir,
+		Environment:   map[string]string{},
+		VolumesFrom:   hostConfig.VolumesFrom,
+		Restart:       string(hostConfig.RestartPolicy.Name),
+		Networks:      map[string]composeServiceNetwork{},
+		// Matches the host gateway that the CLI adds on Linux
+		ExtraHosts: []string{utils.DinDHost + ":host-gateway"},
+		Labels:     map[string]string{utils.CliProjectLabel: utils.Config.ProjectId},
+	}
+	for _, env := range config.Env {
+		key, value, _ := strings.Cut(env, "=")
+		service.Environment[key] = escape(value)
+	}
+	for port, bindings := range hostConfig.PortBindings {
+		for _, b := range bindings {
+			// Lets compose pick the host port of automatic ports that have not been assigned
+			binding := port.Port()
+			if hostPort := b.HostPort; hostPort != "0" && len(hostPort) > 0 {
+				binding = hostPort + ":" + binding
+			} else if len(b.HostIP) > 0 {
+				binding = ":" + binding
+			}
+			if len(b.HostIP) > 0 {
+				binding = b.HostIP + ":" + binding
+			}
+			service.Ports = append(service.Ports, binding)
+		}
+	}
+	sort.Strings(service.Ports)
+	for _, bind := range hostConfig.Binds {
+		spec, err := loader.ParseVolume(bind)
+		if err != nil {
+			return "", errors.Errorf("failed to parse docker volume: %w", err)
+		}
+		if spec.Type == string(mount.TypeVolume) && len(spec.Source) > 0 {
+			c.Volumes[spec.Source] = composeVolume{Name: spec.Source}
+		}
+		service.Volumes = append(service.Volumes, bind)
+	}
+	for path := range hostConfig.Tmpfs {
+		service.Tmpfs = append(service.Tmpfs, path)
+	}
+	sort.Strings(service.Tmpfs)
+	if hc := config.Healthcheck; hc != nil {
+		service.Healthcheck = &composeHealthcheck{
+			Test:    escapeAll(hc.Test),
+			Retries: hc.Retries,
+		}
+		if hc.Interval > 0 {
+			service.Healthcheck.Interval = hc.Interval.String()
+		}
+		if hc.Timeout > 0 {
+			service.Healthcheck.Timeout = hc.Timeout.String()
+		}
+		if hc.StartPeriod > 0 {
+			service.Healthcheck.StartPeriod = hc.StartPeriod.String()
+		}
+	}
+	for id, endpoint := range networkingConfig.EndpointsConfig {
+		if _, ok := c.Networks[id]; !ok {
+			c.Networks[id] = composeNetwork{Name: id}
+		}
+		service.Networks[id] = composeServiceNetwork{Aliases: endpoint.Aliases}
+	}
+	c.Services[serviceName(containerName, networkingConfig)] = service
+	return containerName, nil
+}
+
+// Waits for the database to be healthy before starting other services, and replaces
+// container names in volumes_from with their service names.
+func (c *composeFile) resolveDependencies() error {
+	names := make(map[string]string, len(c.Services))
+	for name, service := range c.Services {
+		names[service.ContainerName] = name
+	}
+	db, ok := names[utils.DbId]
+	if !ok {
+		return errors.New("missing database service")
+	}
+	for name, service := range c.Services {
+		if name != db {
+			service.DependsOn = map[string]composeDependency{db: {Condition: "service_healthy"}}
+		}
+		for i, id := range service.VolumesFrom {
+			if from, ok := names[id]; ok {
+				service.VolumesFrom[i] = from
+			}
+		}
+		c.Services[name] = service
+	}
+	return nil
+}
+
+// Uses the first network alias as service name, such as db or kong.
+func serviceName(containerName string, networkingConfig network.NetworkingConfig) string {
+	if endpoint, ok := networkingConfig.EndpointsConfig[utils.NetId]; ok && len(endpoint.Aliases) > 0 {
+		return endpoint.Aliases[0]
+	}
+	return containerName
+}
+
+// Compose interpolates $VAR in all string values, so literal dollar signs must be doubled.
+func escape(value string) string {
+	return strings.ReplaceAll(value, "$", "$$")
+}
+
+func escapeAll(values []string) []string {
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = escape(v)
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
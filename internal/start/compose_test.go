@@ -0,0 +1,78 @@
+package start
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExportCompose(t *testing.T) {
+	t.Run("exports all services", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, utils.WriteConfig(fsys, false))
+		// Run test
+		var out bytes.Buffer
+		err := Export(context.Background(), ExportCompose, &out, fsys, []string{"studio", "postgres-meta"})
+		// Check error
+		assert.NoError(t, err)
+		var compose composeFile
+		require.NoError(t, yaml.Unmarshal(out.Bytes(), &compose))
+		assert.Equal(t, utils.Config.ProjectId, compose.Name)
+		assert.Contains(t, compose.Networks, utils.NetId)
+		assert.Contains(t, compose.Volumes, utils.DbId)
+		assert.NotContains(t, compose.Services, "studio")
+		assert.NotContains(t, compose.Services, "pg_meta")
+		// Check database service
+		db := compose.Services["db"]
+		assert.Equal(t, utils.DbId, db.ContainerName)
+		assert.Contains(t, db.Ports, "54322:5432")
+		assert.Contains(t, db.Volumes, utils.DbId+":/var/lib/postgresql/data")
+		assert.Equal(t, []string{"CMD", "pg_isready", "-U", "postgres", "-h", "127.0.0.1", "-p", "5432"}, db.Healthcheck.Test)
+		assert.Empty(t, db.DependsOn)
+		// Check kong service
+		kong := compose.Services["kong"]
+		assert.Equal(t, []string{"kong", "api.supabase.internal"}, kong.Networks[utils.NetId].Aliases)
+		assert.Equal(t, "service_healthy", kong.DependsOn["db"].Condition)
+		assert.Contains(t, kong.Entrypoint[2], "_format_version")
+		// Check volumes_from uses service name
+		assert.Equal(t, []string{"storage"}, compose.Services["imgproxy"].VolumesFrom)
+	})
+
+	t.Run("exports unassigned ports without host port", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		require.NoError(t, utils.WriteConfig(fsys, false))
+		config, err := afero.ReadFile(fsys, utils.ConfigPath)
+		require.NoError(t, err)
+		config = bytes.Replace(config, []byte("port = 54322"), []byte("port = 0"), 1)
+		config = bytes.Replace(config, []byte("port = 54321"), []byte("port = 0"), 1)
+		require.NoError(t, afero.WriteFile(fsys, utils.ConfigPath, config, 0644))
+		require.NoError(t, afero.WriteFile(fsys, utils.PortsPath, []byte(`{"api.port": 60001}`), 0644))
+		// Run test
+		var out bytes.Buffer
+		err = Export(context.Background(), ExportCompose, &out, fsys, []string{"studio", "postgres-meta"})
+		// Check error
+		assert.NoError(t, err)
+		var compose composeFile
+		require.NoError(t, yaml.Unmarshal(out.Bytes(), &compose))
+		assert.Equal(t, []string{"5432"}, compose.Services["db"].Ports)
+		assert.Contains(t, compose.Services["kong"].Ports, "60001:8000")
+	})
+
+	t.Run("throws error on unsupported format", func(t *testing.T) {
+		err := Export(context.Background(), "helm", nil, afero.NewMemMapFs(), nil)
+		assert.ErrorContains(t, err, "unsupported export format: helm")
+	})
+}
+
+func TestEscapeCompose(t *testing.T) {
+	assert.Equal(t, `search_path="$$user",public`, escape(`search_path="$user",public`))
+	assert.Nil(t, escapeAll(nil))
+}
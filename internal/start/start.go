@@ -56,6 +56,11 @@ func Run(ctx context.Context, fsys afero.Fs, excludedContainers []string, ignore
 		} else if !errors.Is(err, utils.ErrNotRunning) {
 			return err
 		}
+		if err := utils.Config.ReassignPorts(); err != nil {
+			return err
+		} else if err := utils.SaveAutoPorts(fsys); err != nil {
+			return err
+		}
 		if _, err := utils.LoadAccessTokenFS(fsys); err == nil {
 			if ref, err := flags.LoadProjectRef(fsys); err == nil {
 				local := services.GetServiceImages()
@@ -169,28 +174,51 @@ var (
 
 var serviceTimeout = 30 * time.Second
 
+// Creates and starts a container, such as utils.DockerStart.
+type dockerStartFunc func(ctx context.Context, config container.Config, hostConfig container.HostConfig, networkingConfig network.NetworkingConfig, containerName string) (string, error)
+
 func run(p utils.Program, ctx context.Context, fsys afero.Fs, excludedContainers []string, dbConfig pgconn.Config, options ...func(*pgx.ConnConfig)) error {
-	excluded := make(map[string]bool)
-	for _, name := range excludedContainers {
-		excluded[name] = true
+	// Start Postgres.
+	w := utils.StatusWriter{Program: p}
+	if dbConfig.Host == utils.DbId {
+		if err := start.StartDatabase(ctx, fsys, w, options...); err != nil {
+			return err
+		}
 	}
 
-	jwks, err := utils.Config.Auth.ResolveJWKS(ctx)
+	p.Send(utils.StatusMsg("Starting containers..."))
+	started, err := startServices(ctx, fsys, excludedContainers, dbConfig, utils.DockerStart)
 	if err != nil {
 		return err
 	}
 
-	// Start Postgres.
-	w := utils.StatusWriter{Program: p}
-	if dbConfig.Host == utils.DbId {
-		if err := start.StartDatabase(ctx, fsys, w, options...); err != nil {
+	p.Send(utils.StatusMsg("Waiting for health checks..."))
+	if utils.NoBackupVolume && utils.SliceContains(started, utils.StorageId) {
+		if err := start.WaitForHealthyService(ctx, serviceTimeout, utils.StorageId); err != nil {
+			return err
+		}
+		// Disable prompts when seeding
+		if err := buckets.Run(ctx, "", false, fsys); err != nil {
 			return err
 		}
 	}
+	return start.WaitForHealthyService(ctx, serviceTimeout, started...)
+}
+
+// Starts all services except the database, returning the names of started containers.
+func startServices(ctx context.Context, fsys afero.Fs, excludedContainers []string, dbConfig pgconn.Config, dockerStart dockerStartFunc) ([]string, error) {
+	excluded := make(map[string]bool)
+	for _, name := range excludedContainers {
+		excluded[name] = true
+	}
+
+	jwks, err := utils.Config.Auth.ResolveJWKS(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	var started []string
 	var isStorageEnabled = utils.Config.Storage.Enabled && !isContainerExcluded(utils.Config.Storage.Image, excluded)
-	p.Send(utils.StatusMsg("Starting containers..."))
 
 	// Start Logflare
 	if utils.Config.Analytics.Enabled && !isContainerExcluded(utils.Config.Analytics.Image, excluded) {
@@ -216,7 +244,7 @@ func run(p utils.Program, ctx context.Context, fsys afero.Fs, excludedContainers
 		case config.LogflareBigQuery:
 			workdir, err := os.Getwd()
 			if err != nil {
-				return errors.Errorf("failed to get working directory: %w", err)
+				return nil, errors.Errorf("failed to get working directory: %w", err)
 			}
 			hostJwtPath := filepath.Join(workdir, utils.Config.Analytics.GcpJwtPath)
 			bind = append(bind, hostJwtPath+":/opt/app/rel/logflare/bin/gcloud.json")
@@ -233,7 +261,7 @@ func run(p utils.Program, ctx context.Context, fsys afero.Fs, excludedContainers
 			)
 		}
 
-		if _, err := utils.DockerStart(
+		if _, err := dockerStart(
 			ctx,
 			container.Config{
 				Hostname: "127.0.0.1",
@@ -271,7 +299,7 @@ EOF
 			},
 			utils.LogflareId,
 		); err != nil {
-			return err
+			return nil, err
 		}
 		started = append(started, utils.LogflareId)
 	}
@@ -291,13 +319,13 @@ EOF
 			EdgeRuntimeId: utils.EdgeRuntimeId,
 			DbId:          utils.DbId,
 		}); err != nil {
-			return errors.Errorf("failed to exec template: %w", err)
+			return nil, errors.Errorf("failed to exec template: %w", err)
 		}
 		var binds, env []string
 		// Special case for GitLab pipeline
 		parsed, err := client.ParseHostURL(utils.Docker.DaemonHost())
 		if err != nil {
-			return errors.Errorf("failed to parse docker host: %w", err)
+			return nil, errors.Errorf("failed to parse docker host: %w", err)
 		}
 		// Ref: https://vector.dev/docs/reference/configuration/sources/docker_logs/#docker_host
 		dindHost := url.URL{Scheme: "http", Host: net.JoinHostPort(utils.DinDHost, "2375")}
@@ -312,14 +340,14 @@ EOF
 			env = append(env, "DOCKER_HOST="+dindHost.String())
 		case "unix":
 			if parsed, err = client.ParseHostURL(client.DefaultDockerHost); err != nil {
-				return errors.Errorf("failed to parse default host: %w", err)
+				return nil, errors.Errorf("failed to parse default host: %w", err)
 			}
 			if utils.Docker.DaemonHost() != client.DefaultDockerHost {
 				fmt.Fprintln(os.Stderr, utils.Yellow("WARNING:"), "analytics requires mounting default docker socket:", parsed.Host)
 			}
 			binds = append(binds, fmt.Sprintf("%[1]s:%[1]s:ro", parsed.Host))
 		}
-		if _, err := utils.DockerStart(
+		if _, err := dockerStart(
 			ctx,
 			container.Config{
 				Image: utils.Config.Analytics.VectorImage,
@@ -350,7 +378,7 @@ EOF
 			},
 			utils.VectorId,
 		); err != nil {
-			return err
+			return nil, err
 		}
 		started = append(started, utils.VectorId)
 	}
@@ -370,7 +398,7 @@ EOF
 			ApiHost:       utils.Config.Hostname,
 			ApiPort:       utils.Config.Api.Port,
 		}); err != nil {
-			return errors.Errorf("failed to exec template: %w", err)
+			return nil, errors.Errorf("failed to exec template: %w", err)
 		}
 
 		binds := []string{}
@@ -383,7 +411,7 @@ EOF
 				var err error
 				hostPath, err = filepath.Abs(hostPath)
 				if err != nil {
-					return errors.Errorf("failed to resolve absolute path: %w", err)
+					return nil, errors.Errorf("failed to resolve absolute path: %w", err)
 				}
 			}
 			dockerPath := path.Join(nginxEmailTemplateDir, id+filepath.Ext(hostPath))
@@ -394,7 +422,7 @@ EOF
 		if utils.Config.Api.Tls.Enabled {
 			dockerPort = 8443
 		}
-		if _, err := utils.DockerStart(
+		if _, err := dockerStart(
 			ctx,
 			container.Config{
 				Image: utils.Config.Api.KongImage,
@@ -450,7 +478,7 @@ EOF
 			},
 			utils.KongId,
 		); err != nil {
-			return err
+			return nil, err
 		}
 		started = append(started, utils.KongId)
 	}
@@ -672,7 +700,7 @@ EOF
 			}
 		}
 
-		if _, err := utils.DockerStart(
+		if _, err := dockerStart(
 			ctx,
 			container.Config{
 				Image:        utils.Config.Auth.Image,
@@ -699,7 +727,7 @@ EOF
 			},
 			utils.GotrueId,
 		); err != nil {
-			return err
+			return nil, err
 		}
 		started = append(started, utils.GotrueId)
 	}
@@ -713,7 +741,7 @@ EOF
 		if utils.Config.Inbucket.Pop3Port != 0 {
 			inbucketPortBindings["1100/tcp"] = []nat.PortBinding{{HostPort: strconv.FormatUint(uint64(utils.Config.Inbucket.Pop3Port), 10)}}
 		}
-		if _, err := utils.DockerStart(
+		if _, err := dockerStart(
 			ctx,
 			container.Config{
 				Image: utils.Config.Inbucket.Image,
@@ -737,14 +765,14 @@ EOF
 			},
 			utils.InbucketId,
 		); err != nil {
-			return err
+			return nil, err
 		}
 		started = append(started, utils.InbucketId)
 	}
 
 	// Start Realtime.
 	if utils.Config.Realtime.Enabled && !isContainerExcluded(utils.Config.Realtime.Image, excluded) {
-		if _, err := utils.DockerStart(
+		if _, err := dockerStart(
 			ctx,
 			container.Config{
 				Image: utils.Config.Realtime.Image,
@@ -792,14 +820,14 @@ EOF
 			},
 			utils.RealtimeId,
 		); err != nil {
-			return err
+			return nil, err
 		}
 		started = append(started, utils.RealtimeId)
 	}
 
 	// Start PostgREST.
 	if utils.Config.Api.Enabled && !isContainerExcluded(utils.Config.Api.Image, excluded) {
-		if _, err := utils.DockerStart(
+		if _, err := dockerStart(
 			ctx,
 			container.Config{
 				Image: utils.Config.Api.Image,
@@ -826,7 +854,7 @@ EOF
 			},
 			utils.RestId,
 		); err != nil {
-			return err
+			return nil, err
 		}
 		started = append(started, utils.RestId)
 	}
@@ -834,7 +862,7 @@ EOF
 	// Start Storage.
 	if isStorageEnabled {
 		dockerStoragePath := "/mnt"
-		if _, err := utils.DockerStart(
+		if _, err := dockerStart(
 			ctx,
 			container.Config{
 				Image: utils.Config.Storage.Image,
@@ -884,14 +912,14 @@ EOF
 			},
 			utils.StorageId,
 		); err != nil {
-			return err
+			return nil, err
 		}
 		started = append(started, utils.StorageId)
 	}
 
 	// Start Storage ImgProxy.
 	if isStorageEnabled && utils.Config.Storage.ImageTransformation.Enabled && !isContainerExcluded(utils.Config.Storage.ImageTransformation.Image, excluded) {
-		if _, err := utils.DockerStart(
+		if _, err := dockerStart(
 			ctx,
 			container.Config{
 				Image: utils.Config.Storage.ImageTransformation.Image,
@@ -920,7 +948,7 @@ EOF
 			},
 			utils.ImgProxyId,
 		); err != nil {
-			return err
+			return nil, err
 		}
 		started = append(started, utils.ImgProxyId)
 	}
@@ -928,15 +956,19 @@ EOF
 	// Start all functions.
 	if utils.Config.EdgeRuntime.Enabled && !isContainerExcluded(utils.Config.EdgeRuntime.Image, excluded) {
 		dbUrl := fmt.Sprintf("postgresql://%s:%s@%s:%d/%s", dbConfig.User, dbConfig.Password, dbConfig.Host, dbConfig.Port, dbConfig.Database)
-		if err := serve.ServeFunctions(ctx, "", nil, "", dbUrl, serve.RuntimeOption{}, fsys); err != nil {
-			return err
+		config, hostConfig, networkingConfig, err := serve.NewContainerConfig("", nil, "", dbUrl, serve.RuntimeOption{}, fsys)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := dockerStart(ctx, config, hostConfig, networkingConfig, utils.EdgeRuntimeId); err != nil {
+			return nil, err
 		}
 		started = append(started, utils.EdgeRuntimeId)
 	}
 
 	// Start pg-meta.
 	if utils.Config.Studio.Enabled && !isContainerExcluded(utils.Config.Studio.PgmetaImage, excluded) {
-		if _, err := utils.DockerStart(
+		if _, err := dockerStart(
 			ctx,
 			container.Config{
 				Image: utils.Config.Studio.PgmetaImage,
@@ -967,14 +999,14 @@ EOF
 			},
 			utils.PgmetaId,
 		); err != nil {
-			return err
+			return nil, err
 		}
 		started = append(started, utils.PgmetaId)
 	}
 
 	// Start Studio.
 	if utils.Config.Studio.Enabled && !isContainerExcluded(utils.Config.Studio.Image, excluded) {
-		if _, err := utils.DockerStart(
+		if _, err := dockerStart(
 			ctx,
 			container.Config{
 				Image: utils.Config.Studio.Image,
@@ -1014,7 +1046,7 @@ EOF
 			},
 			utils.StudioId,
 		); err != nil {
-			return err
+			return nil, err
 		}
 		started = append(started, utils.StudioId)
 	}
@@ -1039,9 +1071,9 @@ EOF
 			DefaultMaxClients: utils.Config.Db.Pooler.MaxClientConn,
 			DefaultPoolSize:   utils.Config.Db.Pooler.DefaultPoolSize,
 		}); err != nil {
-			return errors.Errorf("failed to exec template: %w", err)
+			return nil, errors.Errorf("failed to exec template: %w", err)
 		}
-		if _, err := utils.DockerStart(
+		if _, err := dockerStart(
 			ctx,
 			container.Config{
 				Image: utils.Config.Db.Pooler.Image,
@@ -1089,22 +1121,11 @@ EOF
 			},
 			utils.PoolerId,
 		); err != nil {
-			return err
+			return nil, err
 		}
 		started = append(started, utils.PoolerId)
 	}
-
-	p.Send(utils.StatusMsg("Waiting for health checks..."))
-	if utils.NoBackupVolume && utils.SliceContains(started, utils.StorageId) {
-		if err := start.WaitForHealthyService(ctx, serviceTimeout, utils.StorageId); err != nil {
-			return err
-		}
-		// Disable prompts when seeding
-		if err := buckets.Run(ctx, "", false, fsys); err != nil {
-			return err
-		}
-	}
-	return start.WaitForHealthyService(ctx, serviceTimeout, started...)
+	return started, nil
 }
 
 func isContainerExcluded(imageName string, excluded map[string]bool) bool {
@@ -37,16 +37,26 @@ type CustomName struct {
 	StorageS3Region          string `env:"storage.s3_region,default=S3_PROTOCOL_REGION"`
 }
 
+// Ports set to 0 in config are only assigned when local services are started.
+const notAssigned = "not assigned"
+
+func withPort(name string, value string) string {
+	if port, ok := utils.Config.AutoPorts[name]; ok && port == 0 {
+		return notAssigned
+	}
+	return value
+}
+
 func (c *CustomName) toValues(exclude ...string) map[string]string {
 	values := map[string]string{
-		c.DbURL: fmt.Sprintf("postgresql://%s@%s:%d/postgres", url.UserPassword("postgres", utils.Config.Db.Password), utils.Config.Hostname, utils.Config.Db.Port),
+		c.DbURL: withPort("db.port", fmt.Sprintf("postgresql://%s@%s:%d/postgres", url.UserPassword("postgres", utils.Config.Db.Password), utils.Config.Hostname, utils.Config.Db.Port)),
 	}
 	if utils.Config.Api.Enabled && !utils.SliceContains(exclude, utils.RestId) && !utils.SliceContains(exclude, utils.ShortContainerImageName(utils.Config.Api.Image)) {
-		values[c.ApiURL] = utils.Config.Api.ExternalUrl
-		values[c.GraphqlURL] = utils.GetApiUrl("/graphql/v1")
+		values[c.ApiURL] = withPort("api.port", utils.Config.Api.ExternalUrl)
+		values[c.GraphqlURL] = withPort("api.port", utils.GetApiUrl("/graphql/v1"))
 	}
 	if utils.Config.Studio.Enabled && !utils.SliceContains(exclude, utils.StudioId) && !utils.SliceContains(exclude, utils.ShortContainerImageName(utils.Config.Studio.Image)) {
-		values[c.StudioURL] = fmt.Sprintf("http://%s:%d", utils.Config.Hostname, utils.Config.Studio.Port)
+		values[c.StudioURL] = withPort("studio.port", fmt.Sprintf("http://%s:%d", utils.Config.Hostname, utils.Config.Studio.Port))
 	}
 	if utils.Config.Auth.Enabled && !utils.SliceContains(exclude, utils.GotrueId) && !utils.SliceContains(exclude, utils.ShortContainerImageName(utils.Config.Auth.Image)) {
 		values[c.JWTSecret] = utils.Config.Auth.JwtSecret
@@ -54,10 +64,10 @@ func (c *CustomName) toValues(exclude ...string) map[string]string {
 		values[c.ServiceRoleKey] = utils.Config.Auth.ServiceRoleKey
 	}
 	if utils.Config.Inbucket.Enabled && !utils.SliceContains(exclude, utils.InbucketId) && !utils.SliceContains(exclude, utils.ShortContainerImageName(utils.Config.Inbucket.Image)) {
-		values[c.InbucketURL] = fmt.Sprintf("http://%s:%d", utils.Config.Hostname, utils.Config.Inbucket.Port)
+		values[c.InbucketURL] = withPort("inbucket.port", fmt.Sprintf("http://%s:%d", utils.Config.Hostname, utils.Config.Inbucket.Port))
 	}
 	if utils.Config.Storage.Enabled && !utils.SliceContains(exclude, utils.StorageId) && !utils.SliceContains(exclude, utils.ShortContainerImageName(utils.Config.Storage.Image)) {
-		values[c.StorageS3URL] = utils.GetApiUrl("/storage/v1/s3")
+		values[c.StorageS3URL] = withPort("api.port", utils.GetApiUrl("/storage/v1/s3"))
 		values[c.StorageS3AccessKeyId] = utils.Config.Storage.S3Credentials.AccessKeyId
 		values[c.StorageS3SecretAccessKey] = utils.Config.Storage.S3Credentials.SecretAccessKey
 		values[c.StorageS3Region] = utils.Config.Storage.S3Credentials.Region
@@ -12,16 +12,19 @@ import (
 )
 
 const (
-	TemplatePgTAP = "pgtap"
+	TemplatePgTAP  = "pgtap"
+	TemplatePolicy = "policy"
 )
 
 var (
 	//go:embed templates/pgtap.sql
 	pgtapTest []byte
+	//go:embed templates/policy.yaml
+	policyTest []byte
 )
 
 func Run(ctx context.Context, name, template string, fsys afero.Fs) error {
-	path := filepath.Join(utils.DbTestsDir, fmt.Sprintf("%s_test.sql", name))
+	path := getPath(name, template)
 	if _, err := fsys.Stat(path); err == nil {
 		return errors.New(path + " already exists.")
 	}
@@ -32,10 +35,19 @@ func Run(ctx context.Context, name, template string, fsys afero.Fs) error {
 	return nil
 }
 
+func getPath(name, template string) string {
+	if template == TemplatePolicy {
+		return filepath.Join(utils.DbPolicyTestsDir, name+".yaml")
+	}
+	return filepath.Join(utils.DbTestsDir, fmt.Sprintf("%s_test.sql", name))
+}
+
 func getTemplate(name string) []byte {
 	switch name {
 	case TemplatePgTAP:
 		return pgtapTest
+	case TemplatePolicy:
+		return policyTest
 	}
 	return nil
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/supabase/cli/internal/db/test"
 	"github.com/supabase/cli/internal/utils"
 )
 
@@ -44,3 +45,20 @@ func TestCreatePgTAP(t *testing.T) {
 		assert.ErrorContains(t, err, "already exists")
 	})
 }
+
+func TestCreatePolicy(t *testing.T) {
+	t.Run("creates policy file", func(t *testing.T) {
+		// Setup in-memory fs
+		fsys := afero.NewMemMapFs()
+		// Run test
+		err := Run(context.Background(), "todos", TemplatePolicy, fsys)
+		// Check error
+		assert.NoError(t, err)
+		f, err := fsys.Stat(filepath.Join(utils.DbPolicyTestsDir, "todos.yaml"))
+		assert.NoError(t, err)
+		assert.EqualValues(t, len(policyTest), f.Size())
+		// Check template is valid
+		_, err = test.LoadPolicySuite(filepath.Join(utils.DbPolicyTestsDir, "todos.yaml"), fsys)
+		assert.NoError(t, err)
+	})
+}
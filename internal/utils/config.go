@@ -2,6 +2,7 @@ package utils
 
 import (
 	_ "embed"
+	"encoding/json"
 	"fmt"
 	"io/fs"
 	"net"
@@ -12,6 +13,7 @@ import (
 	"github.com/go-errors/errors"
 	"github.com/spf13/afero"
 	"github.com/spf13/viper"
+	"github.com/supabase/cli/pkg/archive"
 	"github.com/supabase/cli/pkg/config"
 )
 
@@ -109,6 +111,31 @@ func LoadConfigFS(fsys afero.Fs) error {
 		return err
 	}
 	UpdateDockerIds()
+	return LoadDumpIdentities()
+}
+
+// Saves automatically assigned ports so they stay the same across commands.
+func SaveAutoPorts(fsys afero.Fs) error {
+	if len(Config.AutoPorts) == 0 {
+		return nil
+	}
+	data, err := json.MarshalIndent(Config.AutoPorts, "", "  ")
+	if err != nil {
+		return errors.Errorf("failed to encode ports: %w", err)
+	}
+	return WriteFile(PortsPath, data, fsys)
+}
+
+// Configures the identities used to transparently decrypt dump and seed files.
+func LoadDumpIdentities() error {
+	if len(Config.Db.Dump.Identity) == 0 {
+		return nil
+	}
+	identities, err := archive.ParseIdentities(Config.Db.Dump.Identity)
+	if err != nil {
+		return err
+	}
+	archive.Identities = identities
 	return nil
 }
 
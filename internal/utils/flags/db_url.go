@@ -54,6 +54,8 @@ func ParseDatabaseConfig(flagSet *pflag.FlagSet, fsys afero.Fs) error {
 			if !errors.Is(err, os.ErrNotExist) {
 				return err
 			}
+		} else if err := utils.LoadDumpIdentities(); err != nil {
+			return err
 		}
 		if flag := flagSet.Lookup("db-url"); flag != nil {
 			config, err := pgconn.ParseConfig(flag.Value.String())
@@ -129,6 +129,7 @@ var (
 	ConfigPath            = filepath.Join(SupabaseDirPath, "config.toml")
 	GitIgnorePath         = filepath.Join(SupabaseDirPath, ".gitignore")
 	TempDir               = filepath.Join(SupabaseDirPath, ".temp")
+	InspectDir            = filepath.Join(SupabaseDirPath, "inspect")
 	ImportMapsDir         = filepath.Join(TempDir, "import_maps")
 	ProjectRefPath        = filepath.Join(TempDir, "project-ref")
 	PoolerUrlPath         = filepath.Join(TempDir, "pooler-url")
@@ -141,14 +142,19 @@ var (
 	PoolerVersionPath     = filepath.Join(TempDir, "pooler-version")
 	RealtimeVersionPath   = filepath.Join(TempDir, "realtime-version")
 	CliVersionPath        = filepath.Join(TempDir, "cli-latest")
+	PortsPath             = filepath.Join(TempDir, "ports.json")
 	CurrBranchPath        = filepath.Join(SupabaseDirPath, ".branches", "_current_branch")
 	SchemasDir            = filepath.Join(SupabaseDirPath, "schemas")
 	MigrationsDir         = filepath.Join(SupabaseDirPath, "migrations")
 	FunctionsDir          = filepath.Join(SupabaseDirPath, "functions")
 	FallbackImportMapPath = filepath.Join(FunctionsDir, "import_map.json")
 	FallbackEnvFilePath   = filepath.Join(FunctionsDir, ".env")
+	FunctionsCoverageDir  = filepath.Join(TempDir, "functions-coverage")
 	DbTestsDir            = filepath.Join(SupabaseDirPath, "tests")
+	DbPolicyTestsDir      = filepath.Join(DbTestsDir, "policies")
 	CustomRolesPath       = filepath.Join(SupabaseDirPath, "roles.sql")
+	LintBaselinePath      = filepath.Join(SupabaseDirPath, ".lint-baseline.json")
+	DbCoverageDir         = filepath.Join(TempDir, "coverage")
 
 	ErrNotLinked   = errors.Errorf("Cannot find project ref. Have you run %s?", Aqua("supabase link"))
 	ErrInvalidRef  = errors.New("Invalid project ref format. Must be like `abcdefghijklmnopqrst`.")
@@ -196,7 +202,7 @@ func IsGitRepo() bool {
 // If the `os.Getwd()` is within a supabase project, this will return
 // the root of the given project as the current working directory.
 // Otherwise, the `os.Getwd()` is kept as is.
-func getProjectRoot(absPath string, fsys afero.Fs) string {
+func GetProjectRoot(absPath string, fsys afero.Fs) string {
 	for cwd := absPath; ; cwd = filepath.Dir(cwd) {
 		path := filepath.Join(cwd, ConfigPath)
 		// Treat all errors as file not exists
@@ -228,7 +234,7 @@ func ChangeWorkDir(fsys afero.Fs) error {
 	}
 	workdir := viper.GetString("WORKDIR")
 	if len(workdir) == 0 {
-		workdir = getProjectRoot(CurrentDirAbs, fsys)
+		workdir = GetProjectRoot(CurrentDirAbs, fsys)
 	}
 	if err := os.Chdir(workdir); err != nil {
 		return errors.Errorf("failed to change workdir: %w", err)
@@ -34,7 +34,7 @@ func TestProjectRoot(t *testing.T) {
 		require.NoError(t, err)
 		// Run test
 		cwd := filepath.Join(root, "home", "user", "project")
-		path := getProjectRoot(cwd, fsys)
+		path := GetProjectRoot(cwd, fsys)
 		// Check error
 		assert.Equal(t, root, path)
 	})
@@ -46,7 +46,7 @@ func TestProjectRoot(t *testing.T) {
 		require.NoError(t, err)
 		// Run test
 		cwd := filepath.Join(root, "supabase", "supabase", "functions")
-		path := getProjectRoot(cwd, fsys)
+		path := GetProjectRoot(cwd, fsys)
 		// Check error
 		assert.Equal(t, filepath.Join(root, "supabase"), path)
 	})
@@ -57,7 +57,7 @@ func TestProjectRoot(t *testing.T) {
 		// Setup in-memory fs
 		fsys := afero.NewMemMapFs()
 		// Run test
-		path := getProjectRoot(cwd, fsys)
+		path := GetProjectRoot(cwd, fsys)
 		// Check error
 		assert.NoError(t, err)
 		assert.Equal(t, cwd, path)
@@ -69,7 +69,7 @@ func TestProjectRoot(t *testing.T) {
 		// Setup in-memory fs
 		fsys := &MockFs{DenyPath: filepath.Join(cwd, "supabase")}
 		// Run test
-		path := getProjectRoot(cwd, fsys)
+		path := GetProjectRoot(cwd, fsys)
 		// Check error
 		assert.NoError(t, err)
 		assert.Equal(t, cwd, path)
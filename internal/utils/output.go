@@ -1,9 +1,13 @@
 package utils
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
+	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"github.com/go-errors/errors"
@@ -12,6 +16,7 @@ import (
 )
 
 const (
+	OutputCsv    = "csv"
 	OutputEnv    = "env"
 	OutputJson   = "json"
 	OutputPretty = "pretty"
@@ -28,6 +33,7 @@ var (
 		OutputJson,
 		OutputToml,
 		OutputYaml,
+		OutputCsv,
 	}
 
 	OutputFormat = EnumFlag{
@@ -73,8 +79,67 @@ func EncodeOutput(format string, w io.Writer, value any) error {
 			return errors.Errorf("failed to output toml: %w", err)
 		}
 
+	case OutputCsv:
+		records, err := toCsvRecords(value)
+		if err != nil {
+			return err
+		}
+		enc := csv.NewWriter(w)
+		if err := enc.WriteAll(records); err != nil {
+			return errors.Errorf("failed to output csv: %w", err)
+		}
+
 	default:
 		return errors.Errorf("Unsupported output encoding %q", format)
 	}
 	return nil
 }
+
+// Converts a slice of structs to csv records, using json field names as the header row.
+func toCsvRecords(value any) ([][]string, error) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.Struct {
+		return nil, errors.Errorf("value is not a slice of structs and can't be encoded as csv")
+	}
+	t := v.Type().Elem()
+	var header []string
+	var fields []int
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		} else if len(name) == 0 {
+			name = f.Name
+		}
+		header = append(header, name)
+		fields = append(fields, i)
+	}
+	records := [][]string{header}
+	for i := 0; i < v.Len(); i++ {
+		row := make([]string, len(fields))
+		for j, k := range fields {
+			row[j] = formatCsvValue(v.Index(i).Field(k))
+		}
+		records = append(records, row)
+	}
+	return records, nil
+}
+
+func formatCsvValue(v reflect.Value) string {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
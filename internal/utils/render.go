@@ -26,3 +26,8 @@ func parse(layout, value string) string {
 	}
 	return t.UTC().Format(layoutHuman)
 }
+
+// Formats a duration in seconds, such as those reported by pg_stat_statements, rounded to milliseconds.
+func FormatDuration(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond).String()
+}
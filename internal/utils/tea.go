@@ -62,6 +62,8 @@ func (p *fakeProgram) Send(msg tea.Msg) {
 		if msg != nil {
 			fmt.Println(*msg)
 		}
+	case fmt.Stringer:
+		fmt.Println(msg)
 	}
 
 	_, cmd := p.model.Update(msg)
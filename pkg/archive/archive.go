@@ -0,0 +1,178 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/go-errors/errors"
+	"github.com/klauspost/compress/zstd"
+)
+
+type Compression string
+
+const (
+	None Compression = "none"
+	Gzip Compression = "gzip"
+	Zstd Compression = "zstd"
+)
+
+var (
+	AllowedCompressions = []string{
+		string(None),
+		string(Gzip),
+		string(Zstd),
+	}
+
+	// Identities used to decrypt age encrypted input when none is passed to NewReader.
+	Identities []age.Identity
+
+	gzipMagic  = []byte{0x1f, 0x8b}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	ageMagic   = []byte("age-encryption.org/")
+	armorMagic = []byte(armor.Header)
+)
+
+// Infers the compression format from file extension, ie. dump.sql.gz
+func InferCompression(path string) Compression {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz", ".gzip":
+		return Gzip
+	case ".zst", ".zstd":
+		return Zstd
+	}
+	return None
+}
+
+func ParseRecipients(keys []string) ([]age.Recipient, error) {
+	var result []age.Recipient
+	for _, k := range keys {
+		r, err := age.ParseX25519Recipient(k)
+		if err != nil {
+			return nil, errors.Errorf("failed to parse age recipient: %w", err)
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+func ParseIdentities(keys string) ([]age.Identity, error) {
+	result, err := age.ParseIdentities(strings.NewReader(keys))
+	if err != nil {
+		return nil, errors.Errorf("failed to parse age identity: %w", err)
+	}
+	return result, nil
+}
+
+type writeCloser struct {
+	io.Writer
+	closers []io.Closer
+}
+
+// Closes the innermost writer first so that all buffered data is flushed.
+func (w *writeCloser) Close() error {
+	for i := len(w.closers) - 1; i >= 0; i-- {
+		if err := w.closers[i].Close(); err != nil {
+			return errors.Errorf("failed to close writer: %w", err)
+		}
+	}
+	return nil
+}
+
+// Wraps w such that written data is compressed, then optionally encrypted to the
+// given recipients. The returned writer must be closed to flush all data. Closing
+// the returned writer does not close w.
+func NewWriter(w io.Writer, compression Compression, recipients ...age.Recipient) (io.WriteCloser, error) {
+	result := writeCloser{Writer: w}
+	if len(recipients) > 0 {
+		enc, err := age.Encrypt(w, recipients...)
+		if err != nil {
+			return nil, errors.Errorf("failed to encrypt output: %w", err)
+		}
+		result.Writer = enc
+		result.closers = append(result.closers, enc)
+	}
+	switch compression {
+	case Gzip:
+		gz := gzip.NewWriter(result.Writer)
+		result.Writer = gz
+		result.closers = append(result.closers, gz)
+	case Zstd:
+		zw, err := zstd.NewWriter(result.Writer)
+		if err != nil {
+			return nil, errors.Errorf("failed to create zstd writer: %w", err)
+		}
+		result.Writer = zw
+		result.closers = append(result.closers, zw)
+	}
+	return &result, nil
+}
+
+type readCloser struct {
+	io.Reader
+	close func()
+}
+
+func (r *readCloser) Close() error {
+	if r.close != nil {
+		r.close()
+	}
+	return nil
+}
+
+// Wraps r such that age encrypted and gzip or zstd compressed input is transparently
+// decoded. Plain input is returned as is. Closing the returned reader does not close r.
+func NewReader(r io.Reader, identities ...age.Identity) (io.ReadCloser, error) {
+	if len(identities) == 0 {
+		identities = Identities
+	}
+	result := readCloser{Reader: r}
+	magic, err := peek(&result.Reader, len(armorMagic))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(magic, armorMagic) {
+		result.Reader = armor.NewReader(result.Reader)
+		magic = ageMagic
+	}
+	if bytes.HasPrefix(magic, ageMagic) {
+		if len(identities) == 0 {
+			return nil, errors.New("Missing age identity to decrypt input: set db.dump.identity in config.")
+		}
+		if result.Reader, err = age.Decrypt(result.Reader, identities...); err != nil {
+			return nil, errors.Errorf("failed to decrypt input: %w", err)
+		}
+		if magic, err = peek(&result.Reader, len(zstdMagic)); err != nil {
+			return nil, err
+		}
+	}
+	if bytes.HasPrefix(magic, gzipMagic) {
+		if result.Reader, err = gzip.NewReader(result.Reader); err != nil {
+			return nil, errors.Errorf("failed to read gzip input: %w", err)
+		}
+	} else if bytes.HasPrefix(magic, zstdMagic) {
+		dec, err := zstd.NewReader(result.Reader, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, errors.Errorf("failed to read zstd input: %w", err)
+		}
+		result.Reader = dec
+		result.close = dec.Close
+	}
+	return &result, nil
+}
+
+// Peeks the first n bytes of r, replacing r with a buffered reader.
+func peek(r *io.Reader, n int) ([]byte, error) {
+	br := bufio.NewReader(*r)
+	*r = br
+	magic, err := br.Peek(n)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, errors.Errorf("failed to read input: %w", err)
+	}
+	return magic, nil
+}
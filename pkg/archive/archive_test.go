@@ -0,0 +1,91 @@
+package archive
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sql = "create table test();\ninsert into test default values;\n"
+
+func roundTrip(t *testing.T, compression Compression, recipients []age.Recipient, identities []age.Identity) string {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, compression, recipients...)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, sql)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	r, err := NewReader(&buf, identities...)
+	require.NoError(t, err)
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestRoundTrip(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	recipients := []age.Recipient{identity.Recipient()}
+	identities := []age.Identity{identity}
+
+	for _, c := range AllowedCompressions {
+		compression := Compression(c)
+
+		t.Run("plain "+c, func(t *testing.T) {
+			assert.Equal(t, sql, roundTrip(t, compression, nil, nil))
+		})
+
+		t.Run("encrypted "+c, func(t *testing.T) {
+			assert.Equal(t, sql, roundTrip(t, compression, recipients, identities))
+		})
+	}
+
+	t.Run("reads armored input", func(t *testing.T) {
+		var buf bytes.Buffer
+		aw := armor.NewWriter(&buf)
+		w, err := NewWriter(aw, Gzip, recipients...)
+		require.NoError(t, err)
+		_, err = io.WriteString(w, sql)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		require.NoError(t, aw.Close())
+		// Run test
+		r, err := NewReader(&buf, identities...)
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		// Check error
+		assert.NoError(t, err)
+		assert.Equal(t, sql, string(data))
+	})
+
+	t.Run("throws error on missing identity", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, None, recipients...)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		// Run test
+		_, err = NewReader(&buf)
+		// Check error
+		assert.ErrorContains(t, err, "Missing age identity")
+	})
+
+	t.Run("reads empty input", func(t *testing.T) {
+		r, err := NewReader(&bytes.Buffer{})
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Empty(t, data)
+	})
+}
+
+func TestInferCompression(t *testing.T) {
+	assert.Equal(t, Gzip, InferCompression("dump.sql.gz"))
+	assert.Equal(t, Zstd, InferCompression("dump.sql.ZST"))
+	assert.Equal(t, None, InferCompression("dump.sql"))
+}
@@ -130,6 +130,8 @@ type (
 		Functions    FunctionConfig `toml:"functions"`
 		Analytics    analytics      `toml:"analytics"`
 		Experimental experimental   `toml:"experimental"`
+		// Host ports assigned automatically for fields set to 0
+		AutoPorts map[string]uint16 `toml:"-"`
 	}
 
 	config struct {
@@ -221,6 +223,7 @@ func (c *baseConfig) Clone() baseConfig {
 	copy.Auth.External = maps.Clone(c.Auth.External)
 	copy.Auth.Email.Template = maps.Clone(c.Auth.Email.Template)
 	copy.Auth.Sms.TestOTP = maps.Clone(c.Auth.Sms.TestOTP)
+	copy.AutoPorts = maps.Clone(c.AutoPorts)
 	return copy
 }
 
@@ -440,16 +443,14 @@ func (c *config) Load(path string, fsys fs.FS) error {
 	if connString, err := fs.ReadFile(fsys, builder.PoolerUrlPath); err == nil && len(connString) > 0 {
 		c.Db.Pooler.ConnectionString = string(connString)
 	}
-	// Update external api url
-	apiUrl := url.URL{Host: net.JoinHostPort(c.Hostname,
-		strconv.FormatUint(uint64(c.Api.Port), 10),
-	)}
-	if c.Api.Tls.Enabled {
-		apiUrl.Scheme = "https"
-	} else {
-		apiUrl.Scheme = "http"
+	// Assign host ports set to 0
+	if saved, err := loadSavedPorts(fsys, builder.PortsPath); err != nil {
+		return err
+	} else if err := c.resolvePorts(saved); err != nil {
+		return err
 	}
-	c.Api.ExternalUrl = apiUrl.String()
+	// Update external api url
+	c.Api.ExternalUrl = c.getApiUrl()
 	// Update image versions
 	if version, err := fs.ReadFile(fsys, builder.PostgresVersionPath); err == nil {
 		if strings.HasPrefix(string(version), "15.") && semver.Compare(string(version[3:]), "1.0.55") >= 0 {
@@ -551,6 +552,18 @@ func (c *config) Load(path string, fsys fs.FS) error {
 	return nil
 }
 
+func (c *baseConfig) getApiUrl() string {
+	apiUrl := url.URL{Host: net.JoinHostPort(c.Hostname,
+		strconv.FormatUint(uint64(c.Api.Port), 10),
+	)}
+	if c.Api.Tls.Enabled {
+		apiUrl.Scheme = "https"
+	} else {
+		apiUrl.Scheme = "http"
+	}
+	return apiUrl.String()
+}
+
 func (c *baseConfig) Validate(fsys fs.FS) error {
 	if c.ProjectId == "" {
 		return errors.New("Missing required field in config: project_id")
@@ -560,7 +573,7 @@ func (c *baseConfig) Validate(fsys fs.FS) error {
 	}
 	// Validate api config
 	if c.Api.Enabled {
-		if c.Api.Port == 0 {
+		if c.Api.Port == 0 && !c.isAutoPort("api.port") {
 			return errors.New("Missing required field in config: api.port")
 		}
 	}
@@ -571,7 +584,7 @@ func (c *baseConfig) Validate(fsys fs.FS) error {
 			return errors.Errorf("Invalid config for db.session_replication_role: %s. Must be one of: %v", *c.Db.Settings.SessionReplicationRole, allowedRoles)
 		}
 	}
-	if c.Db.Port == 0 {
+	if c.Db.Port == 0 && !c.isAutoPort("db.port") {
 		return errors.New("Missing required field in config: db.port")
 	}
 	switch c.Db.MajorVersion {
@@ -603,6 +616,17 @@ func (c *baseConfig) Validate(fsys fs.FS) error {
 	default:
 		return errors.Errorf("Failed reading config: Invalid %s: %v.", "db.major_version", c.Db.MajorVersion)
 	}
+	if len(c.Db.Dump.Identity) > 0 {
+		var err error
+		if c.Db.Dump.Identity, err = maybeLoadEnv(c.Db.Dump.Identity); err != nil {
+			return err
+		}
+	}
+	for _, pattern := range c.Db.Lint.IgnoreObjects {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.Errorf("Invalid config for db.lint.ignore_objects: %w", err)
+		}
+	}
 	// Validate pooler config
 	if c.Db.Pooler.Enabled {
 		allowed := []PoolMode{TransactionMode, SessionMode}
@@ -625,7 +649,7 @@ func (c *baseConfig) Validate(fsys fs.FS) error {
 	}
 	// Validate studio config
 	if c.Studio.Enabled {
-		if c.Studio.Port == 0 {
+		if c.Studio.Port == 0 && !c.isAutoPort("studio.port") {
 			return errors.New("Missing required field in config: studio.port")
 		}
 		if parsed, err := url.Parse(c.Studio.ApiUrl); err != nil {
@@ -637,7 +661,7 @@ func (c *baseConfig) Validate(fsys fs.FS) error {
 	}
 	// Validate smtp config
 	if c.Inbucket.Enabled {
-		if c.Inbucket.Port == 0 {
+		if c.Inbucket.Port == 0 && !c.isAutoPort("inbucket.port") {
 			return errors.New("Missing required field in config: inbucket.port")
 		}
 	}
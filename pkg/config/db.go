@@ -57,9 +57,21 @@ type (
 		RootKey      string   `toml:"-" mapstructure:"root_key"`
 		Pooler       pooler   `toml:"pooler"`
 		Seed         seed     `toml:"seed"`
+		Dump         dump     `toml:"dump"`
+		Lint         lint     `toml:"lint"`
 		Settings     settings `toml:"settings"`
 	}
 
+	dump struct {
+		Recipients []string `toml:"recipients"`
+		Identity   string   `toml:"identity"`
+	}
+
+	lint struct {
+		IgnoreRules   []string `toml:"ignore_rules"`
+		IgnoreObjects []string `toml:"ignore_objects"`
+	}
+
 	seed struct {
 		Enabled      bool     `toml:"enabled"`
 		GlobPatterns []string `toml:"sql_paths"`
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net"
+	"strconv"
+
+	"github.com/go-errors/errors"
+)
+
+// Host ports of local services, keyed by config field. Ports set to 0 are assigned
+// automatically so that multiple projects can run at the same time.
+func (c *baseConfig) hostPorts() map[string]*uint16 {
+	return map[string]*uint16{
+		"api.port":                    &c.Api.Port,
+		"db.port":                     &c.Db.Port,
+		"db.shadow_port":              &c.Db.ShadowPort,
+		"db.pooler.port":              &c.Db.Pooler.Port,
+		"studio.port":                 &c.Studio.Port,
+		"inbucket.port":               &c.Inbucket.Port,
+		"analytics.port":              &c.Analytics.Port,
+		"edge_runtime.inspector_port": &c.EdgeRuntime.InspectorPort,
+	}
+}
+
+// Host ports of short-lived containers, such as the shadow database, which are picked
+// on every run instead of being saved.
+var ephemeralPorts = []string{"db.shadow_port", "edge_runtime.inspector_port"}
+
+func loadSavedPorts(fsys fs.FS, path string) (map[string]uint16, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Errorf("failed to read ports: %w", err)
+	}
+	var saved map[string]uint16
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, errors.Errorf("failed to parse ports: %w", err)
+	}
+	return saved, nil
+}
+
+// Resolves ports set to 0 from previously saved assignments. Ports that have not been
+// saved stay 0 until assigned by ReassignPorts, except ephemeral ports which are picked
+// right away.
+func (c *baseConfig) resolvePorts(saved map[string]uint16) error {
+	c.AutoPorts = map[string]uint16{}
+	var ephemeral []*uint16
+	for name, port := range c.hostPorts() {
+		if *port > 0 {
+			continue
+		}
+		if sliceContains(ephemeralPorts, name) {
+			ephemeral = append(ephemeral, port)
+			continue
+		}
+		*port = saved[name]
+		c.AutoPorts[name] = *port
+	}
+	return assignFreePorts(ephemeral)
+}
+
+// Assigns automatic ports that are not yet assigned or no longer free, such as when taken
+// by another project after they were saved. Must only be called when local services are
+// stopped.
+func (c *baseConfig) ReassignPorts() error {
+	ports := c.hostPorts()
+	var taken []*uint16
+	for name, port := range c.AutoPorts {
+		if port == 0 || !isPortFree(port) {
+			taken = append(taken, ports[name])
+		}
+	}
+	if len(taken) == 0 {
+		return nil
+	}
+	if err := assignFreePorts(taken); err != nil {
+		return err
+	}
+	for name := range c.AutoPorts {
+		c.AutoPorts[name] = *ports[name]
+	}
+	// Update urls derived from api port
+	prev := c.Api.ExternalUrl
+	c.Api.ExternalUrl = c.getApiUrl()
+	if c.Studio.ApiUrl == prev {
+		c.Studio.ApiUrl = c.Api.ExternalUrl
+	}
+	return nil
+}
+
+// Returns true if the port is set to 0 in config and assigned automatically.
+func (c *baseConfig) isAutoPort(name string) bool {
+	_, ok := c.AutoPorts[name]
+	return ok
+}
+
+func assignFreePorts(ports []*uint16) error {
+	// Keeps listeners open until all ports are assigned to avoid duplicates
+	var listeners []net.Listener
+	defer func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}()
+	for _, port := range ports {
+		l, err := net.Listen("tcp", ":0")
+		if err != nil {
+			return errors.Errorf("failed to find a free port: %w", err)
+		}
+		listeners = append(listeners, l)
+		*port = uint16(l.Addr().(*net.TCPAddr).Port)
+	}
+	return nil
+}
+
+func isPortFree(port uint16) bool {
+	l, err := net.Listen("tcp", ":"+strconv.FormatUint(uint64(port), 10))
+	if err != nil {
+		return false
+	}
+	l.Close()
+	return true
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"testing"
+	fs "testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePorts(t *testing.T) {
+	t.Run("leaves unsaved ports unassigned", func(t *testing.T) {
+		config := NewConfig()
+		var buf bytes.Buffer
+		require.NoError(t, config.Eject(&buf))
+		data := bytes.Replace(buf.Bytes(), []byte("port = 54321"), []byte("port = 0"), 1)
+		data = bytes.Replace(data, []byte("port = 54322"), []byte("port = 0"), 1)
+		fsys := fs.MapFS{"config.toml": &fs.MapFile{Data: data}}
+		// Run test
+		assert.NoError(t, config.Load("config.toml", fsys))
+		// Check result
+		assert.Equal(t, map[string]uint16{"api.port": 0, "db.port": 0}, config.AutoPorts)
+		assert.Zero(t, config.Api.Port)
+		assert.Zero(t, config.Db.Port)
+		assert.Equal(t, uint16(54323), config.Studio.Port)
+	})
+
+	t.Run("picks ephemeral ports", func(t *testing.T) {
+		config := NewConfig()
+		var buf bytes.Buffer
+		require.NoError(t, config.Eject(&buf))
+		fsys := fs.MapFS{
+			"config.toml":               &fs.MapFile{Data: bytes.Replace(buf.Bytes(), []byte("shadow_port = 54320"), []byte("shadow_port = 0"), 1)},
+			"supabase/.temp/ports.json": &fs.MapFile{Data: []byte(`{"db.shadow_port": 60001}`)},
+		}
+		// Run test
+		assert.NoError(t, config.Load("config.toml", fsys))
+		// Check result
+		assert.Empty(t, config.AutoPorts)
+		assert.NotZero(t, config.Db.ShadowPort)
+		assert.NotEqual(t, uint16(60001), config.Db.ShadowPort)
+	})
+
+	t.Run("loads saved ports", func(t *testing.T) {
+		config := NewConfig()
+		var buf bytes.Buffer
+		require.NoError(t, config.Eject(&buf))
+		fsys := fs.MapFS{
+			"supabase/config.toml":      &fs.MapFile{Data: bytes.Replace(buf.Bytes(), []byte("port = 54321"), []byte("port = 0"), 1)},
+			"supabase/.temp/ports.json": &fs.MapFile{Data: []byte(`{"api.port": 60001, "db.port": 60002}`)},
+		}
+		// Run test
+		assert.NoError(t, config.Load("", fsys))
+		// Check result
+		assert.Equal(t, map[string]uint16{"api.port": 60001}, config.AutoPorts)
+		assert.Equal(t, uint16(60001), config.Api.Port)
+		assert.Equal(t, uint16(54322), config.Db.Port)
+	})
+
+	t.Run("throws error on malformed ports", func(t *testing.T) {
+		config := NewConfig()
+		var buf bytes.Buffer
+		require.NoError(t, config.Eject(&buf))
+		fsys := fs.MapFS{
+			"supabase/config.toml":      &fs.MapFile{Data: buf.Bytes()},
+			"supabase/.temp/ports.json": &fs.MapFile{Data: []byte("{")},
+		}
+		// Run test
+		err := config.Load("", fsys)
+		// Check error
+		assert.ErrorContains(t, err, "failed to parse ports:")
+	})
+}
+
+func TestReassignPorts(t *testing.T) {
+	t.Run("reassigns taken ports", func(t *testing.T) {
+		l, err := net.Listen("tcp", ":0")
+		require.NoError(t, err)
+		defer l.Close()
+		taken := uint16(l.Addr().(*net.TCPAddr).Port)
+		config := NewConfig()
+		config.Api.Port = taken
+		config.AutoPorts = map[string]uint16{"api.port": taken}
+		config.Api.ExternalUrl = config.getApiUrl()
+		config.Studio.ApiUrl = config.Api.ExternalUrl
+		// Run test
+		assert.NoError(t, config.ReassignPorts())
+		// Check result
+		assert.NotEqual(t, taken, config.Api.Port)
+		assert.Equal(t, config.Api.Port, config.AutoPorts["api.port"])
+		assert.Equal(t, "http://127.0.0.1:"+strconv.Itoa(int(config.Api.Port)), config.Api.ExternalUrl)
+		assert.Equal(t, config.Api.ExternalUrl, config.Studio.ApiUrl)
+	})
+
+	t.Run("assigns unassigned ports", func(t *testing.T) {
+		config := NewConfig()
+		config.AutoPorts = map[string]uint16{"api.port": 0, "db.port": 0}
+		config.Api.ExternalUrl = config.getApiUrl()
+		config.Studio.ApiUrl = config.Api.ExternalUrl
+		// Run test
+		assert.NoError(t, config.ReassignPorts())
+		// Check result
+		assert.NotZero(t, config.Api.Port)
+		assert.NotZero(t, config.Db.Port)
+		assert.NotEqual(t, config.Api.Port, config.Db.Port)
+		assert.Equal(t, map[string]uint16{"api.port": config.Api.Port, "db.port": config.Db.Port}, config.AutoPorts)
+		assert.Equal(t, "http://127.0.0.1:"+strconv.Itoa(int(config.Api.Port)), config.Api.ExternalUrl)
+		assert.Equal(t, config.Api.ExternalUrl, config.Studio.ApiUrl)
+	})
+
+	t.Run("keeps free ports", func(t *testing.T) {
+		config := NewConfig()
+		config.Api.Port = 54321
+		// Run test
+		assert.NoError(t, config.ReassignPorts())
+		// Check result
+		assert.Equal(t, uint16(54321), config.Api.Port)
+	})
+}
@@ -27,6 +27,7 @@ type pathBuilder struct {
 	RealtimeVersionPath   string
 	CliVersionPath        string
 	CurrBranchPath        string
+	PortsPath             string
 	SchemasDir            string
 	MigrationsDir         string
 	FunctionsDir          string
@@ -60,6 +61,7 @@ func NewPathBuilder(configPath string) pathBuilder {
 		RealtimeVersionPath:   filepath.Join(base, ".temp", "realtime-version"),
 		CliVersionPath:        filepath.Join(base, ".temp", "cli-latest"),
 		CurrBranchPath:        filepath.Join(base, ".branches", "_current_branch"),
+		PortsPath:             filepath.Join(base, ".temp", "ports.json"),
 		SchemasDir:            filepath.Join(base, "schemas"),
 		MigrationsDir:         filepath.Join(base, "migrations"),
 		FunctionsDir:          filepath.Join(base, "functions"),
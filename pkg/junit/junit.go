@@ -0,0 +1,99 @@
+package junit
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/go-errors/errors"
+)
+
+// Ref: https://github.com/testmoapp/junitxml
+type TestSuites struct {
+	XMLName  xml.Name    `xml:"testsuites"`
+	Name     string      `xml:"name,attr,omitempty"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     float64     `xml:"time,attr"`
+	Suites   []TestSuite `xml:"testsuite"`
+}
+
+type TestSuite struct {
+	Name     string     `xml:"name,attr"`
+	Tests    int        `xml:"tests,attr"`
+	Failures int        `xml:"failures,attr"`
+	Errors   int        `xml:"errors,attr"`
+	Skipped  int        `xml:"skipped,attr"`
+	Time     float64    `xml:"time,attr"`
+	File     string     `xml:"file,attr,omitempty"`
+	Cases    []TestCase `xml:"testcase"`
+}
+
+type TestCase struct {
+	Name      string   `xml:"name,attr"`
+	ClassName string   `xml:"classname,attr,omitempty"`
+	File      string   `xml:"file,attr,omitempty"`
+	Line      int      `xml:"line,attr,omitempty"`
+	Time      float64  `xml:"time,attr"`
+	Failure   *Message `xml:"failure,omitempty"`
+	Error     *Message `xml:"error,omitempty"`
+	Skipped   *Message `xml:"skipped,omitempty"`
+	SystemOut string   `xml:"system-out,omitempty"`
+}
+
+type Message struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// Adds a test case to the suite, updating the summary counts.
+func (s *TestSuite) Add(tc TestCase) {
+	s.Cases = append(s.Cases, tc)
+	s.Tests++
+	s.Time += tc.Time
+	if tc.Failure != nil {
+		s.Failures++
+	}
+	if tc.Error != nil {
+		s.Errors++
+	}
+	if tc.Skipped != nil {
+		s.Skipped++
+	}
+}
+
+// Adds a test suite to the report, updating the summary counts.
+func (r *TestSuites) Add(s TestSuite) {
+	r.Suites = append(r.Suites, s)
+	r.Tests += s.Tests
+	r.Failures += s.Failures
+	r.Errors += s.Errors
+	r.Skipped += s.Skipped
+	r.Time += s.Time
+}
+
+func (r *TestSuites) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return errors.Errorf("failed to write xml header: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return errors.Errorf("failed to encode junit report: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return errors.Errorf("failed to write newline: %w", err)
+	}
+	return nil
+}
+
+// Parses a report written by another tool, ie. deno test.
+func Parse(r io.Reader) (TestSuites, error) {
+	var result TestSuites
+	if err := xml.NewDecoder(r).Decode(&result); err != nil {
+		return result, errors.Errorf("failed to parse junit report: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package junit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteReport(t *testing.T) {
+	suite := TestSuite{Name: "test"}
+	suite.Add(TestCase{Name: "pass", Time: 0.5})
+	suite.Add(TestCase{Name: "fail", Time: 0.25, Failure: &Message{Message: "not ok"}})
+	suite.Add(TestCase{Name: "skip", Skipped: &Message{}})
+	report := TestSuites{}
+	report.Add(suite)
+	// Run test
+	var out bytes.Buffer
+	err := report.Write(&out)
+	// Check error
+	assert.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="1" errors="0" skipped="1" time="0.75">
+  <testsuite name="test" tests="3" failures="1" errors="0" skipped="1" time="0.75">
+    <testcase name="pass" time="0.5"></testcase>
+    <testcase name="fail" time="0.25">
+      <failure message="not ok"></failure>
+    </testcase>
+    <testcase name="skip" time="0">
+      <skipped></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, out.String())
+}
+
+func TestParseReport(t *testing.T) {
+	report, err := Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="deno test" tests="2" failures="1" errors="0" time="0.012">
+    <testsuite name="./hello/index_test.ts" tests="2" disabled="0" errors="0" failures="1">
+        <testcase name="returns greeting" classname="./hello/index_test.ts" time="0.002" line="3" col="6">
+        </testcase>
+        <testcase name="rejects empty name" classname="./hello/index_test.ts" time="0.01" line="9" col="6">
+            <failure message="Uncaught AssertionError">AssertionError: Values are not equal</failure>
+        </testcase>
+    </testsuite>
+</testsuites>
+`))
+	// Check error
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Tests)
+	assert.Len(t, report.Suites, 1)
+	assert.Equal(t, 9, report.Suites[0].Cases[1].Line)
+	assert.Equal(t, "Uncaught AssertionError", report.Suites[0].Cases[1].Failure.Message)
+}
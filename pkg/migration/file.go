@@ -1,6 +1,7 @@
 package migration
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
@@ -14,6 +15,7 @@ import (
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
 	"github.com/spf13/viper"
+	"github.com/supabase/cli/pkg/archive"
 	"github.com/supabase/cli/pkg/parser"
 )
 
@@ -47,15 +49,22 @@ func parseFile(path string, fsys fs.FS) ([]string, error) {
 		return nil, errors.Errorf("failed to open migration file: %w", err)
 	}
 	defer sql.Close()
-	// Unless explicitly specified, Use file length as max buffer size
-	if !viper.IsSet("SCANNER_BUFFER_SIZE") {
-		if fi, err := sql.Stat(); err == nil {
-			if size := int(fi.Size()); size > parser.MaxScannerCapacity {
-				parser.MaxScannerCapacity = size
-			}
-		}
+	// Compressed or encrypted files are decoded transparently
+	r, err := archive.NewReader(sql)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	// Decoded size is only known after reading the whole input, which may be larger than the file on disk
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Errorf("failed to read migration file: %w", err)
+	}
+	// Unless explicitly specified, use decoded length as max buffer size
+	if !viper.IsSet("SCANNER_BUFFER_SIZE") && len(data) > parser.MaxScannerCapacity {
+		parser.MaxScannerCapacity = len(data)
 	}
-	return parser.SplitAndTrim(sql)
+	return parser.SplitAndTrim(bytes.NewReader(data))
 }
 
 func NewMigrationFromReader(sql io.Reader) (*MigrationFile, error) {
@@ -2,6 +2,8 @@ package migration
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"strings"
 	"testing"
@@ -30,6 +32,47 @@ func TestMigrationFile(t *testing.T) {
 		assert.Equal(t, "20220727064247", migration.Version)
 	})
 
+	t.Run("new from compressed file", func(t *testing.T) {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, err := gz.Write([]byte("create schema public;\ncreate table test();"))
+		assert.NoError(t, err)
+		assert.NoError(t, gz.Close())
+		// Setup in-memory fs
+		path := "seed.sql.gz"
+		fsys := fs.MapFS{
+			path: &fs.MapFile{Data: buf.Bytes()},
+		}
+		// Run test
+		migration, err := NewMigrationFromFile(path, fsys)
+		// Check error
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"create schema public", "create table test()"}, migration.Statements)
+	})
+
+	t.Run("new from compressed file with large copy block", func(t *testing.T) {
+		capacity := parser.MaxScannerCapacity
+		defer func() { parser.MaxScannerCapacity = capacity }()
+		rows := strings.Repeat("1\tvalue\n", parser.MaxScannerCapacity/8+1)
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, err := gz.Write([]byte("COPY public.test (id, name) FROM stdin;\n" + rows + "\\.\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, gz.Close())
+		assert.Less(t, buf.Len(), parser.MaxScannerCapacity)
+		// Setup in-memory fs
+		path := "data.sql.gz"
+		fsys := fs.MapFS{
+			path: &fs.MapFile{Data: buf.Bytes()},
+		}
+		// Run test
+		migration, err := NewMigrationFromFile(path, fsys)
+		// Check error
+		assert.NoError(t, err)
+		assert.Len(t, migration.Statements, 1)
+		assert.Contains(t, migration.Statements[0], rows)
+	})
+
 	t.Run("new from reader errors on max token", func(t *testing.T) {
 		viper.Reset()
 		sql := "\tBEGIN; " + strings.Repeat("a", parser.MaxScannerCapacity)
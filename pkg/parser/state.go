@@ -2,6 +2,7 @@ package parser
 
 import (
 	"bytes"
+	"regexp"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -15,6 +16,9 @@ const (
 	END_ATOMIC   = "END"
 )
 
+// Matches a COPY statement that reads inline data, optionally preceded by comments.
+var copyFromStdin = regexp.MustCompile(`(?is)^\s*(?:--[^\n]*\n\s*)*COPY\s.+\sFROM\s+STDIN\b[^;]*;$`)
+
 type State interface {
 	// Return nil to emit token
 	Next(r rune, data []byte) State
@@ -39,6 +43,10 @@ func (s *ReadyState) Next(r rune, data []byte) State {
 	case '\\':
 		return &EscapeState{}
 	case ';':
+		// Inline data rows belong to the same token as the COPY statement
+		if copyFromStdin.Match(data) {
+			return &CopyState{}
+		}
 		// Emit token
 		return nil
 	case '(':
@@ -182,3 +190,29 @@ func (s *AtomicState) Next(r rune, data []byte) State {
 	}
 	return s
 }
+
+// Opened COPY FROM stdin data, terminated by \. on its own line
+type CopyState struct{}
+
+func (s *CopyState) Next(r rune, data []byte) State {
+	if r == '\n' && (bytes.HasSuffix(data, []byte("\n\\.\n")) || bytes.HasSuffix(data, []byte("\n\\.\r\n"))) {
+		// Emit token
+		return nil
+	}
+	return s
+}
+
+// Splits a COPY FROM stdin token into the statement and its inline data rows.
+// The end-of-data marker \. is not included in the returned data.
+func SplitCopy(token string) (stat string, data string, found bool) {
+	// Leading comments may also contain the ; separator
+	for i, r := range token {
+		if r == ';' && copyFromStdin.MatchString(token[:i+1]) {
+			stat, data = token[:i], token[i+1:]
+			data = strings.TrimLeft(data, "\r\n")
+			data = strings.TrimSuffix(strings.TrimRight(data, "\r\n"), "\\.")
+			return strings.TrimSpace(stat), data, true
+		}
+	}
+	return token, "", false
+}
@@ -168,3 +168,36 @@ END
 		checkSplit(t, sql)
 	})
 }
+
+func TestCopyFromStdin(t *testing.T) {
+	t.Run("inline data", func(t *testing.T) {
+		sql := []string{"COPY public.test (id, name) FROM stdin;\n1\tsemi;colon\n2\t'quote\n\\.\n", "SELECT 1;"}
+		checkSplit(t, sql)
+	})
+
+	t.Run("empty data", func(t *testing.T) {
+		sql := []string{"\n--\n-- Data for Name: test; Type: TABLE DATA\n--\n\ncopy test from STDIN;\n\\.\n", "END"}
+		checkSplit(t, sql)
+	})
+
+	t.Run("ignores copy from file", func(t *testing.T) {
+		sql := []string{"COPY test FROM '/tmp/data.csv';", "\n\\.;"}
+		checkSplit(t, sql)
+	})
+
+	t.Run("splits statement from data", func(t *testing.T) {
+		token := "-- Data for Name: test; Type: TABLE DATA\nCOPY public.test (id) FROM stdin;\n1\n2\n\\.\n"
+		stat, data, found := SplitCopy(token)
+		assert.True(t, found)
+		assert.Equal(t, "-- Data for Name: test; Type: TABLE DATA\nCOPY public.test (id) FROM stdin", stat)
+		assert.Equal(t, "1\n2\n", data)
+	})
+
+	t.Run("ignores other statements", func(t *testing.T) {
+		token := "SELECT 1; COPY test FROM stdin;"
+		stat, data, found := SplitCopy(token)
+		assert.False(t, found)
+		assert.Equal(t, token, stat)
+		assert.Empty(t, data)
+	})
+}
@@ -242,6 +242,19 @@ func (r *MockConn) ReplyError(code, message string) *MockConn {
 	return r
 }
 
+// Simulates a COPY FROM stdin reply, expecting all rows in a single data message.
+func (r *MockConn) ReplyCopy(tag, data string) *MockConn {
+	q := r.lastQuery()
+	q.reply.Steps = append(
+		q.reply.Steps,
+		pgmock.SendMessage(&pgproto3.CopyInResponse{}),
+		pgmock.ExpectMessage(&pgproto3.CopyData{Data: []byte(data)}),
+		pgmock.ExpectMessage(&pgproto3.CopyDone{}),
+		pgmock.SendMessage(&pgproto3.CommandComplete{CommandTag: []byte(tag)}),
+	)
+	return r
+}
+
 func (r *MockConn) Close(t *testing.T) {
 	if r.client != nil {
 		if err := r.client.Close(context.Background()); err != nil {